@@ -0,0 +1,35 @@
+// fingerlint 离线检查指纹规则，可在提交指纹或 CI 中使用
+//
+//	go run ./cmd/fingerlint -finger webfinger.yaml -dir dir.yaml -samples webfinger_samples.yaml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slack-wails/core/webscan"
+)
+
+func main() {
+	home, _ := os.UserHomeDir()
+	fingerprintFile := flag.String("finger", filepath.Join(home, "slack", "config", "webfinger.yaml"), "指纹规则文件")
+	activeFile := flag.String("dir", filepath.Join(home, "slack", "config", "dir.yaml"), "主动探测规则文件，为空时跳过")
+	samplesFile := flag.String("samples", "", "指纹回归样本文件，为空时跳过")
+	strict := flag.Bool("strict", false, "存在 warning 时同样返回非 0 退出码")
+	flag.Parse()
+
+	report, err := webscan.LintFingerprints(*fingerprintFile, *activeFile, *samplesFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	for _, issue := range report.Issues {
+		fmt.Println(issue)
+	}
+	errors, warnings := report.Count(webscan.LintError), report.Count(webscan.LintWarning)
+	fmt.Printf("products: %d, rules: %d, samples: %d, errors: %d, warnings: %d\n", report.Products, report.Rules, report.Samples, errors, warnings)
+	if errors > 0 || (*strict && warnings > 0) {
+		os.Exit(1)
+	}
+}
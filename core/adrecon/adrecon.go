@@ -0,0 +1,467 @@
+package adrecon
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"slack-wails/lib/gologger"
+	"slack-wails/lib/structs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// userAccountControl 标志位
+const (
+	uacAccountDisable             = 0x2
+	uacPasswdNotReqd              = 0x20
+	uacServerTrustAccount         = 0x2000
+	uacDontExpirePassword         = 0x10000
+	uacTrustedForDelegation       = 0x80000
+	uacNotDelegated               = 0x100000
+	uacDontReqPreauth             = 0x400000
+	uacTrustedToAuthForDelegation = 0x1000000
+)
+
+const pageSize = 500
+
+var (
+	userAttributes = []string{
+		"sAMAccountName", "displayName", "distinguishedName", "objectSid", "primaryGroupID", "description",
+		"userAccountControl", "adminCount", "servicePrincipalName", "msDS-AllowedToDelegateTo", "sIDHistory",
+		"pwdLastSet", "lastLogonTimestamp",
+	}
+	groupAttributes = []string{
+		"sAMAccountName", "distinguishedName", "objectSid", "description", "adminCount", "member",
+	}
+	computerAttributes = []string{
+		"sAMAccountName", "dNSHostName", "distinguishedName", "objectSid", "primaryGroupID", "operatingSystem",
+		"operatingSystemVersion", "userAccountControl", "servicePrincipalName", "msDS-AllowedToDelegateTo",
+		"msDS-AllowedToActOnBehalfOfOtherIdentity", "lastLogonTimestamp",
+	}
+	// gPLink 形如 [LDAP://cn={GUID},cn=policies,cn=system,DC=corp,DC=local;0]
+	gpLinkPattern = regexp.MustCompile(`(?i)\[LDAP://([^;\]]+);(\d+)\]`)
+
+	functionalLevels = map[string]string{
+		"0": "2000", "1": "2003 Interim", "2": "2003", "3": "2008", "4": "2008 R2", "5": "2012", "6": "2012 R2", "7": "2016",
+	}
+	trustDirections = []string{"Disabled", "Inbound", "Outbound", "Bidirectional"}
+)
+
+// Collect 使用域账号通过 LDAP 枚举域内用户、组、计算机、GPO、信任关系以及密码策略
+// username 为纯用户名时自动补全为 user@domain
+func Collect(ctx context.Context, host, username, password string) (*structs.ADReconResult, error) {
+	conn, err := dial(host)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rootDSE, err := searchOne(conn, "", ldap.ScopeBaseObject, "(objectClass=*)", []string{"defaultNamingContext", "dnsHostName", "domainFunctionality"})
+	if err != nil {
+		return nil, fmt.Errorf("read rootDSE: %v", err)
+	}
+	baseDN := rootDSE.GetAttributeValue("defaultNamingContext")
+	if baseDN == "" {
+		return nil, fmt.Errorf("%s is not an active directory domain controller", host)
+	}
+	domain := DomainFromDN(baseDN)
+	if err = conn.Bind(bindName(username, domain), password); err != nil {
+		return nil, err
+	}
+	gologger.Info(ctx, fmt.Sprintf("[adrecon] %s bind success, base dn: %s", host, baseDN))
+
+	r := &recon{conn: conn, baseDN: baseDN}
+	result := &structs.ADReconResult{}
+	result.Domain, err = r.domain(rootDSE)
+	if err != nil {
+		return nil, err
+	}
+	// 以下步骤失败时仍返回已收集的数据
+	steps := []struct {
+		name string
+		run  func(*structs.ADReconResult) error
+	}{
+		{"users", r.users},
+		{"groups", r.groups},
+		{"computers", r.computers},
+		{"gpos", r.gpos},
+		{"trusts", r.trusts},
+	}
+	for _, step := range steps {
+		if err := step.run(result); err != nil {
+			gologger.Warning(ctx, fmt.Sprintf("[adrecon] collect %s failed: %v", step.name, err))
+		}
+	}
+	resolve(result)
+	gologger.Success(ctx, fmt.Sprintf("[adrecon] %s users: %d groups: %d computers: %d gpos: %d trusts: %d", domain,
+		len(result.Users), len(result.Groups), len(result.Computers), len(result.GPOs), len(result.Trusts)))
+	return result, nil
+}
+
+// 636、3269 端口使用 LDAPS，域控证书通常为自签名
+func dial(host string) (*ldap.Conn, error) {
+	_, port, _ := net.SplitHostPort(host)
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if port == "636" || port == "3269" {
+		return ldap.DialURL("ldaps://"+host, ldap.DialWithDialer(dialer), ldap.DialWithTLSConfig(&tls.Config{InsecureSkipVerify: true}))
+	}
+	return ldap.DialURL("ldap://"+host, ldap.DialWithDialer(dialer))
+}
+
+func bindName(username, domain string) string {
+	if strings.ContainsAny(username, `@\=`) || domain == "" {
+		return username
+	}
+	return username + "@" + domain
+}
+
+// DomainFromDN DC=corp,DC=local -> corp.local
+func DomainFromDN(dn string) string {
+	var labels []string
+	for _, rdn := range strings.Split(dn, ",") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(rdn), "="); ok && strings.EqualFold(k, "DC") {
+			labels = append(labels, v)
+		}
+	}
+	return strings.ToLower(strings.Join(labels, "."))
+}
+
+type recon struct {
+	conn   *ldap.Conn
+	baseDN string
+}
+
+func searchOne(conn *ldap.Conn, baseDN string, scope int, filter string, attributes []string) (*ldap.Entry, error) {
+	sr, err := conn.Search(ldap.NewSearchRequest(baseDN, scope, ldap.NeverDerefAliases, 0, 0, false, filter, attributes, nil))
+	if err != nil {
+		return nil, err
+	}
+	if len(sr.Entries) == 0 {
+		return nil, fmt.Errorf("%s not found", filter)
+	}
+	return sr.Entries[0], nil
+}
+
+func (r *recon) search(filter string, attributes []string) ([]*ldap.Entry, error) {
+	sr, err := r.conn.SearchWithPaging(ldap.NewSearchRequest(r.baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, filter, attributes, nil), pageSize)
+	if sr == nil {
+		return nil, err
+	}
+	// 部分分页失败时保留已返回的条目
+	return sr.Entries, err
+}
+
+func (r *recon) domain(rootDSE *ldap.Entry) (structs.ADDomain, error) {
+	entry, err := searchOne(r.conn, r.baseDN, ldap.ScopeBaseObject, "(objectClass=*)", []string{
+		"objectSid", "minPwdLength", "pwdHistoryLength", "maxPwdAge", "minPwdAge", "lockoutThreshold",
+		"lockoutDuration", "pwdProperties", "ms-DS-MachineAccountQuota", "gPLink",
+	})
+	if err != nil {
+		return structs.ADDomain{}, err
+	}
+	pwdProperties := attrInt(entry, "pwdProperties")
+	level := rootDSE.GetAttributeValue("domainFunctionality")
+	if name, ok := functionalLevels[level]; ok {
+		level = name
+	}
+	return structs.ADDomain{
+		Name:                DomainFromDN(r.baseDN),
+		DistinguishedName:   r.baseDN,
+		SID:                 ParseSID(entry.GetRawAttributeValue("objectSid")),
+		DomainController:    rootDSE.GetAttributeValue("dnsHostName"),
+		FunctionalLevel:     level,
+		MachineAccountQuota: int(attrInt(entry, "ms-DS-MachineAccountQuota")),
+		PasswordPolicy: structs.ADPasswordPolicy{
+			MinLength:            int(attrInt(entry, "minPwdLength")),
+			HistoryLength:        int(attrInt(entry, "pwdHistoryLength")),
+			MaxAge:               formatInterval(attrInt(entry, "maxPwdAge")),
+			MinAge:               formatInterval(attrInt(entry, "minPwdAge")),
+			LockoutThreshold:     int(attrInt(entry, "lockoutThreshold")),
+			LockoutDuration:      formatInterval(attrInt(entry, "lockoutDuration")),
+			Complexity:           pwdProperties&0x1 != 0,
+			ReversibleEncryption: pwdProperties&0x10 != 0,
+		},
+		GPOLinks: parseGPLink(entry.GetAttributeValue("gPLink")),
+	}, nil
+}
+
+func (r *recon) users(result *structs.ADReconResult) error {
+	entries, err := r.search("(&(objectCategory=person)(objectClass=user))", userAttributes)
+	for _, entry := range entries {
+		uac := attrInt(entry, "userAccountControl")
+		sid := ParseSID(entry.GetRawAttributeValue("objectSid"))
+		var sidHistory []string
+		for _, raw := range entry.GetRawAttributeValues("sIDHistory") {
+			sidHistory = append(sidHistory, ParseSID(raw))
+		}
+		result.Users = append(result.Users, structs.ADUser{
+			Name:                    entry.GetAttributeValue("sAMAccountName"),
+			DisplayName:             entry.GetAttributeValue("displayName"),
+			DistinguishedName:       entry.DN,
+			SID:                     sid,
+			PrimaryGroupSID:         primaryGroupSID(sid, entry.GetAttributeValue("primaryGroupID")),
+			Description:             entry.GetAttributeValue("description"),
+			Enabled:                 uac&uacAccountDisable == 0,
+			PasswordNeverExpires:    uac&uacDontExpirePassword != 0,
+			PasswordNotRequired:     uac&uacPasswdNotReqd != 0,
+			DontRequirePreauth:      uac&uacDontReqPreauth != 0,
+			AdminCount:              attrInt(entry, "adminCount") == 1,
+			Sensitive:               uac&uacNotDelegated != 0,
+			UnconstrainedDelegation: uac&uacTrustedForDelegation != 0,
+			TrustedToAuth:           uac&uacTrustedToAuthForDelegation != 0,
+			AllowedToDelegate:       entry.GetAttributeValues("msDS-AllowedToDelegateTo"),
+			SPNs:                    entry.GetAttributeValues("servicePrincipalName"),
+			SIDHistory:              sidHistory,
+			PasswordLastSet:         formatFileTime(attrInt(entry, "pwdLastSet")),
+			LastLogon:               formatFileTime(attrInt(entry, "lastLogonTimestamp")),
+		})
+	}
+	return err
+}
+
+func (r *recon) groups(result *structs.ADReconResult) error {
+	entries, err := r.search("(objectClass=group)", groupAttributes)
+	for _, entry := range entries {
+		group := structs.ADGroup{
+			Name:              entry.GetAttributeValue("sAMAccountName"),
+			DistinguishedName: entry.DN,
+			SID:               ParseSID(entry.GetRawAttributeValue("objectSid")),
+			Description:       entry.GetAttributeValue("description"),
+			AdminCount:        attrInt(entry, "adminCount") == 1,
+		}
+		// 成员名称与类型在 resolve 中补全
+		for _, dn := range r.members(entry) {
+			group.Members = append(group.Members, structs.ADMember{Name: dn})
+		}
+		result.Groups = append(result.Groups, group)
+	}
+	return err
+}
+
+// members 读取组成员，成员超过 MaxValRange 时需按 member;range=x-* 分段读取
+func (r *recon) members(entry *ldap.Entry) []string {
+	var members []string
+	for _, attr := range entry.Attributes {
+		name := strings.ToLower(attr.Name)
+		if name == "member" {
+			return attr.Values
+		}
+		if !strings.HasPrefix(name, "member;range=") {
+			continue
+		}
+		members = append(members, attr.Values...)
+		for end := name[strings.LastIndex(name, "-")+1:]; end != "*"; {
+			start, err := strconv.Atoi(end)
+			if err != nil {
+				break
+			}
+			next, err := searchOne(r.conn, entry.DN, ldap.ScopeBaseObject, "(objectClass=*)", []string{fmt.Sprintf("member;range=%d-*", start+1)})
+			if err != nil || len(next.Attributes) == 0 {
+				break
+			}
+			members = append(members, next.Attributes[0].Values...)
+			rangeName := next.Attributes[0].Name
+			end = rangeName[strings.LastIndex(rangeName, "-")+1:]
+		}
+	}
+	return members
+}
+
+func (r *recon) computers(result *structs.ADReconResult) error {
+	entries, err := r.search("(objectClass=computer)", computerAttributes)
+	for _, entry := range entries {
+		uac := attrInt(entry, "userAccountControl")
+		sid := ParseSID(entry.GetRawAttributeValue("objectSid"))
+		result.Computers = append(result.Computers, structs.ADComputer{
+			Name:                    strings.TrimSuffix(entry.GetAttributeValue("sAMAccountName"), "$"),
+			DNSHostName:             entry.GetAttributeValue("dNSHostName"),
+			DistinguishedName:       entry.DN,
+			SID:                     sid,
+			PrimaryGroupSID:         primaryGroupSID(sid, entry.GetAttributeValue("primaryGroupID")),
+			OperatingSystem:         entry.GetAttributeValue("operatingSystem"),
+			OperatingSystemVersion:  entry.GetAttributeValue("operatingSystemVersion"),
+			Enabled:                 uac&uacAccountDisable == 0,
+			DomainController:        uac&uacServerTrustAccount != 0,
+			UnconstrainedDelegation: uac&uacTrustedForDelegation != 0,
+			TrustedToAuth:           uac&uacTrustedToAuthForDelegation != 0,
+			AllowedToDelegate:       entry.GetAttributeValues("msDS-AllowedToDelegateTo"),
+			AllowedToAct:            ParseSecurityDescriptorSIDs(entry.GetRawAttributeValue("msDS-AllowedToActOnBehalfOfOtherIdentity")),
+			SPNs:                    entry.GetAttributeValues("servicePrincipalName"),
+			LastLogon:               formatFileTime(attrInt(entry, "lastLogonTimestamp")),
+		})
+	}
+	return err
+}
+
+func (r *recon) gpos(result *structs.ADReconResult) error {
+	entries, err := r.search("(objectClass=groupPolicyContainer)", []string{"displayName", "cn", "objectGUID", "gPCFileSysPath"})
+	for _, entry := range entries {
+		result.GPOs = append(result.GPOs, structs.ADGPO{
+			Name:              entry.GetAttributeValue("displayName"),
+			GUID:              entry.GetAttributeValue("cn"),
+			ObjectGUID:        ParseGUID(entry.GetRawAttributeValue("objectGUID")),
+			DistinguishedName: entry.DN,
+			Path:              entry.GetAttributeValue("gPCFileSysPath"),
+		})
+	}
+	return err
+}
+
+func (r *recon) trusts(result *structs.ADReconResult) error {
+	entries, err := r.search("(objectClass=trustedDomain)", []string{"trustPartner", "trustDirection", "trustType", "trustAttributes", "securityIdentifier"})
+	for _, entry := range entries {
+		direction := trustDirections[0]
+		if d := attrInt(entry, "trustDirection"); d >= 0 && d < int64(len(trustDirections)) {
+			direction = trustDirections[d]
+		}
+		attributes := attrInt(entry, "trustAttributes")
+		result.Trusts = append(result.Trusts, structs.ADTrust{
+			Partner:      entry.GetAttributeValue("trustPartner"),
+			SID:          ParseSID(entry.GetRawAttributeValue("securityIdentifier")),
+			Direction:    direction,
+			Type:         trustType(attrInt(entry, "trustType"), attributes),
+			Transitive:   attributes&0x1 == 0,
+			SIDFiltering: attributes&0x4 != 0 || attributes&0x8 != 0,
+		})
+	}
+	return err
+}
+
+// trustType 与 BloodHound 的信任类型保持一致
+func trustType(typ, attributes int64) string {
+	switch {
+	case attributes&0x20 != 0:
+		return "ParentChild"
+	case attributes&0x8 != 0:
+		return "Forest"
+	case typ == 1 || typ == 2:
+		return "External"
+	default:
+		return "Unknown"
+	}
+}
+
+// resolve 补全组成员信息、计算嵌套组关系以及域 GPO 链接的名称
+func resolve(result *structs.ADReconResult) {
+	members := make(map[string]structs.ADMember)
+	for _, user := range result.Users {
+		members[strings.ToLower(user.DistinguishedName)] = structs.ADMember{Name: user.Name, SID: user.SID, Type: "User"}
+	}
+	for _, computer := range result.Computers {
+		members[strings.ToLower(computer.DistinguishedName)] = structs.ADMember{Name: computer.Name + "$", SID: computer.SID, Type: "Computer"}
+	}
+	groupsBySID := make(map[string]string)
+	for _, group := range result.Groups {
+		members[strings.ToLower(group.DistinguishedName)] = structs.ADMember{Name: group.Name, SID: group.SID, Type: "Group"}
+		groupsBySID[group.SID] = strings.ToLower(group.DistinguishedName)
+	}
+	// 成员 DN -> 直接所属组 DN
+	parents := make(map[string][]string)
+	for i, group := range result.Groups {
+		for j, member := range group.Members {
+			dn := strings.ToLower(member.Name)
+			parents[dn] = append(parents[dn], strings.ToLower(group.DistinguishedName))
+			if m, ok := members[dn]; ok {
+				result.Groups[i].Members[j] = m
+				continue
+			}
+			// 外部安全主体的 CN 即为 SID
+			rdn, _, _ := strings.Cut(member.Name, ",")
+			_, cn, _ := strings.Cut(rdn, "=")
+			m := structs.ADMember{Name: member.Name, Type: "Base"}
+			if strings.HasPrefix(cn, "S-1-") {
+				m.SID = cn
+			}
+			result.Groups[i].Members[j] = m
+		}
+	}
+	for i, user := range result.Users {
+		start := []string{strings.ToLower(user.DistinguishedName)}
+		if dn, ok := groupsBySID[user.PrimaryGroupSID]; ok {
+			start = append(start, dn)
+		}
+		for _, dn := range nestedGroups(start, parents) {
+			result.Users[i].MemberOf = append(result.Users[i].MemberOf, members[dn].Name)
+		}
+	}
+	gpoNames := make(map[string]string)
+	for _, gpo := range result.GPOs {
+		gpoNames[strings.ToLower(gpo.DistinguishedName)] = gpo.Name
+	}
+	for i, link := range result.Domain.GPOLinks {
+		result.Domain.GPOLinks[i].Name = gpoNames[strings.ToLower(link.DistinguishedName)]
+	}
+}
+
+// nestedGroups 广度优先遍历所属组，start 中的组本身也计入结果
+func nestedGroups(start []string, parents map[string][]string) []string {
+	var groups []string
+	seen := map[string]bool{start[0]: true}
+	queue := append([]string{}, parents[start[0]]...)
+	queue = append(queue, start[1:]...)
+	for len(queue) > 0 {
+		dn := queue[0]
+		queue = queue[1:]
+		if seen[dn] {
+			continue
+		}
+		seen[dn] = true
+		groups = append(groups, dn)
+		queue = append(queue, parents[dn]...)
+	}
+	return groups
+}
+
+// parseGPLink 选项 1 表示链接已禁用，2 表示强制
+func parseGPLink(gpLink string) []structs.ADGPOLink {
+	var links []structs.ADGPOLink
+	for _, match := range gpLinkPattern.FindAllStringSubmatch(gpLink, -1) {
+		options, _ := strconv.Atoi(match[2])
+		if options&0x1 == 0 {
+			links = append(links, structs.ADGPOLink{DistinguishedName: match[1], Enforced: options&0x2 != 0})
+		}
+	}
+	return links
+}
+
+func primaryGroupSID(sid, rid string) string {
+	i := strings.LastIndex(sid, "-")
+	if i == -1 || rid == "" {
+		return ""
+	}
+	return sid[:i+1] + rid
+}
+
+func attrInt(entry *ldap.Entry, name string) int64 {
+	v, _ := strconv.ParseInt(entry.GetAttributeValue(name), 10, 64)
+	return v
+}
+
+// formatFileTime 将 FILETIME（1601 年起的 100 纳秒数）转换为本地时间
+func formatFileTime(v int64) string {
+	if v <= 0 || v == math.MaxInt64 {
+		return ""
+	}
+	return time.Unix((v-116444736000000000)/1e7, 0).Format("2006-01-02 15:04:05")
+}
+
+// formatInterval 转换域策略中以负的 100 纳秒表示的时长
+func formatInterval(v int64) string {
+	if v == 0 || v == math.MinInt64 {
+		return "never"
+	}
+	if v < 0 {
+		v = -v
+	}
+	d := time.Duration(v * 100)
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d days", d/(24*time.Hour))
+	}
+	return d.String()
+}
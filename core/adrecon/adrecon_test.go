@@ -0,0 +1,150 @@
+package adrecon
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"slack-wails/lib/structs"
+	"strings"
+	"testing"
+)
+
+// S-1-5-21-1004336348-1177238915-682003330-512
+var domainAdminsSID = []byte{
+	0x01, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0x15, 0x00, 0x00, 0x00, 0xdc, 0xf4, 0xdc, 0x3b,
+	0x83, 0x3d, 0x2b, 0x46, 0x82, 0x8b, 0xa6, 0x28, 0x00, 0x02, 0x00, 0x00,
+}
+
+func TestParseSID(t *testing.T) {
+	if sid := ParseSID(domainAdminsSID); sid != "S-1-5-21-1004336348-1177238915-682003330-512" {
+		t.Fatalf("ParseSID = %s", sid)
+	}
+	guid := []byte{0x78, 0x56, 0x34, 0x12, 0x34, 0x12, 0x78, 0x56, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78}
+	if g := ParseGUID(guid); g != "12345678-1234-5678-9ABC-DEF012345678" {
+		t.Fatalf("ParseGUID = %s", g)
+	}
+	// 安全描述符头部 20 字节，DACL 紧随其后，包含一条 ACCESS_ALLOWED_ACE
+	sd := make([]byte, 20)
+	binary.LittleEndian.PutUint32(sd[16:], 20)
+	ace := append([]byte{0x00, 0x00, 0x00, 0x00, 0xff, 0x01, 0x0f, 0x00}, domainAdminsSID...)
+	binary.LittleEndian.PutUint16(ace[2:], uint16(len(ace)))
+	acl := []byte{0x02, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}
+	binary.LittleEndian.PutUint16(acl[2:], uint16(8+len(ace)))
+	sd = append(append(sd, acl...), ace...)
+	if sids := ParseSecurityDescriptorSIDs(sd); !reflect.DeepEqual(sids, []string{"S-1-5-21-1004336348-1177238915-682003330-512"}) {
+		t.Fatalf("ParseSecurityDescriptorSIDs = %v", sids)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	if d := DomainFromDN("DC=Corp,DC=local"); d != "corp.local" {
+		t.Fatalf("DomainFromDN = %s", d)
+	}
+	result := &structs.ADReconResult{
+		Users: []structs.ADUser{{Name: "alice", DistinguishedName: "CN=alice,DC=corp,DC=local", SID: "S-1-5-21-1-1001", PrimaryGroupSID: "S-1-5-21-1-513"}},
+		Groups: []structs.ADGroup{
+			{Name: "Domain Users", DistinguishedName: "CN=Domain Users,DC=corp,DC=local", SID: "S-1-5-21-1-513"},
+			{Name: "IT", DistinguishedName: "CN=IT,DC=corp,DC=local", SID: "S-1-5-21-1-1100", Members: []structs.ADMember{{Name: "CN=alice,DC=corp,DC=local"}}},
+			{Name: "Administrators", DistinguishedName: "CN=Administrators,DC=corp,DC=local", SID: "S-1-5-32-544", Members: []structs.ADMember{
+				{Name: "CN=IT,DC=corp,DC=local"},
+				{Name: "CN=S-1-5-21-9-500,CN=ForeignSecurityPrincipals,DC=corp,DC=local"},
+			}},
+		},
+	}
+	resolve(result)
+	if got := result.Users[0].MemberOf; !reflect.DeepEqual(got, []string{"IT", "Domain Users", "Administrators"}) {
+		t.Fatalf("MemberOf = %v", got)
+	}
+	want := []structs.ADMember{
+		{Name: "IT", SID: "S-1-5-21-1-1100", Type: "Group"},
+		{Name: "CN=S-1-5-21-9-500,CN=ForeignSecurityPrincipals,DC=corp,DC=local", SID: "S-1-5-21-9-500", Type: "Base"},
+	}
+	if got := result.Groups[2].Members; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Members = %v", got)
+	}
+}
+
+func TestExportBloodHound(t *testing.T) {
+	result := &structs.ADReconResult{
+		Domain: structs.ADDomain{Name: "corp.local", DistinguishedName: "DC=corp,DC=local", SID: "S-1-5-21-1"},
+		Users: []structs.ADUser{{
+			Name: "alice", DistinguishedName: "CN=alice,DC=corp,DC=local", SID: "S-1-5-21-1-1001", PrimaryGroupSID: "S-1-5-21-1-513",
+			Enabled: true, SPNs: []string{"http/web01.corp.local"}, AllowedToDelegate: []string{"cifs/FS01.corp.local"},
+		}},
+		Groups: []structs.ADGroup{{
+			Name: "Administrators", DistinguishedName: "CN=Administrators,DC=corp,DC=local", SID: "S-1-5-32-544",
+			Members: []structs.ADMember{{Name: "alice", SID: "S-1-5-21-1-1001", Type: "User"}, {Name: "unknown"}},
+		}},
+		Computers: []structs.ADComputer{{
+			Name: "FS01", DNSHostName: "fs01.corp.local", DistinguishedName: "CN=FS01,DC=corp,DC=local", SID: "S-1-5-21-1-1105",
+			PrimaryGroupSID: "S-1-5-21-1-515", Enabled: true, OperatingSystem: "Windows Server 2019", AllowedToAct: []string{"S-1-5-21-1-1001"},
+		}},
+	}
+	filename := filepath.Join(t.TempDir(), "bloodhound.zip")
+	if err := ExportBloodHound(result, filename); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	files := make(map[string]map[string]interface{})
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var content map[string]interface{}
+		if err := json.NewDecoder(rc).Decode(&content); err != nil {
+			t.Fatalf("%s: %v", f.Name, err)
+		}
+		rc.Close()
+		typ := strings.TrimSuffix(f.Name[strings.Index(f.Name, "_")+1:], ".json")
+		files[typ] = content
+	}
+	for typ, count := range map[string]int{"domains": 1, "users": 1, "groups": 1, "computers": 1, "gpos": 0} {
+		meta, ok := files[typ]["meta"].(map[string]interface{})
+		if !ok || meta["type"] != typ || meta["count"] != float64(count) || meta["version"] != float64(bloodhoundVersion) {
+			t.Fatalf("%s meta = %v", typ, files[typ]["meta"])
+		}
+		if data, ok := files[typ]["data"].([]interface{}); !ok || len(data) != count {
+			t.Fatalf("%s data = %v", typ, files[typ]["data"])
+		}
+	}
+
+	user := files["users"]["data"].([]interface{})[0].(map[string]interface{})
+	props := user["Properties"].(map[string]interface{})
+	if user["ObjectIdentifier"] != "S-1-5-21-1-1001" || user["PrimaryGroupSID"] != "S-1-5-21-1-513" ||
+		props["name"] != "ALICE@CORP.LOCAL" || props["domain"] != "CORP.LOCAL" || props["hasspn"] != true {
+		t.Fatalf("user = %v", user)
+	}
+	if delegate := user["AllowedToDelegate"].([]interface{}); len(delegate) != 1 ||
+		delegate[0].(map[string]interface{})["ObjectIdentifier"] != "S-1-5-21-1-1105" {
+		t.Fatalf("user AllowedToDelegate = %v", delegate)
+	}
+
+	// BUILTIN 组以域名为前缀，未解析到 SID 的成员不导出
+	group := files["groups"]["data"].([]interface{})[0].(map[string]interface{})
+	members := group["Members"].([]interface{})
+	if group["ObjectIdentifier"] != "CORP.LOCAL-S-1-5-32-544" || len(members) != 1 ||
+		!reflect.DeepEqual(members[0], map[string]interface{}{"ObjectIdentifier": "S-1-5-21-1-1001", "ObjectType": "User"}) {
+		t.Fatalf("group = %v", group)
+	}
+
+	computer := files["computers"]["data"].([]interface{})[0].(map[string]interface{})
+	props = computer["Properties"].(map[string]interface{})
+	if computer["ObjectIdentifier"] != "S-1-5-21-1-1105" || props["name"] != "FS01.CORP.LOCAL" || props["samaccountname"] != "FS01$" ||
+		props["operatingsystem"] != "Windows Server 2019" || computer["IsDC"] != false {
+		t.Fatalf("computer = %v", computer)
+	}
+	if act := computer["AllowedToAct"].([]interface{}); len(act) != 1 ||
+		!reflect.DeepEqual(act[0], map[string]interface{}{"ObjectIdentifier": "S-1-5-21-1-1001", "ObjectType": "User"}) {
+		t.Fatalf("computer AllowedToAct = %v", act)
+	}
+	if sessions, ok := computer["Sessions"].(map[string]interface{}); !ok || sessions["Collected"] != false {
+		t.Fatalf("computer Sessions = %v", computer["Sessions"])
+	}
+}
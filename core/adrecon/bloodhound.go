@@ -0,0 +1,330 @@
+package adrecon
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slack-wails/lib/structs"
+	"strings"
+	"time"
+)
+
+// SharpHound 收集方式：Group | Trusts | ObjectProps | SPNTargets
+const bloodhoundMethods = 1 | 32 | 512 | 8192
+
+// SharpHound v2 输出的数据格式版本，BloodHound 4.3 与 CE 均支持导入
+const bloodhoundVersion = 5
+
+type bhTyped struct {
+	ObjectIdentifier string
+	ObjectType       string
+}
+
+type bhMeta struct {
+	Methods int    `json:"methods"`
+	Type    string `json:"type"`
+	Count   int    `json:"count"`
+	Version int    `json:"version"`
+}
+
+type bhFile struct {
+	Data []map[string]interface{} `json:"data"`
+	Meta bhMeta                   `json:"meta"`
+}
+
+type bhCollection struct {
+	Results       []interface{}
+	Collected     bool
+	FailureReason interface{}
+}
+
+// ExportBloodHound 将收集结果转换为 BloodHound 格式的 JSON 并打包为 zip
+func ExportBloodHound(result *structs.ADReconResult, filename string) error {
+	e := newExporter(result)
+	files := map[string][]map[string]interface{}{
+		"domains":   {e.domain()},
+		"users":     e.users(),
+		"groups":    e.groups(),
+		"computers": e.computers(),
+		"gpos":      e.gpos(),
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	prefix := time.Now().Format("20060102150405")
+	for _, typ := range []string{"domains", "users", "groups", "computers", "gpos"} {
+		w, err := zw.Create(fmt.Sprintf("%s_%s.json", prefix, typ))
+		if err != nil {
+			return err
+		}
+		data := files[typ]
+		if data == nil {
+			data = []map[string]interface{}{}
+		}
+		err = json.NewEncoder(w).Encode(bhFile{
+			Data: data,
+			Meta: bhMeta{Methods: bloodhoundMethods, Type: typ, Count: len(data), Version: bloodhoundVersion},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+type exporter struct {
+	result    *structs.ADReconResult
+	name      string // 大写域名
+	hosts     map[string]string
+	types     map[string]string
+	gpoGUIDs  map[string]string
+	emptyList []interface{}
+}
+
+func newExporter(result *structs.ADReconResult) *exporter {
+	e := &exporter{
+		result:    result,
+		name:      strings.ToUpper(result.Domain.Name),
+		hosts:     make(map[string]string),
+		types:     make(map[string]string),
+		gpoGUIDs:  make(map[string]string),
+		emptyList: []interface{}{},
+	}
+	// 约束委派的目标为 SPN，按主机名对应到计算机
+	for _, computer := range result.Computers {
+		e.hosts[strings.ToLower(computer.Name)] = computer.SID
+		if computer.DNSHostName != "" {
+			e.hosts[strings.ToLower(computer.DNSHostName)] = computer.SID
+		}
+	}
+	for _, user := range result.Users {
+		e.types[user.SID] = "User"
+	}
+	for _, computer := range result.Computers {
+		e.types[computer.SID] = "Computer"
+	}
+	for _, group := range result.Groups {
+		e.types[group.SID] = "Group"
+	}
+	for _, gpo := range result.GPOs {
+		e.gpoGUIDs[strings.ToLower(gpo.DistinguishedName)] = gpo.ObjectGUID
+	}
+	return e
+}
+
+// objectId BUILTIN 组的 SID 在各个域中相同，BloodHound 以域名作为前缀区分
+func (e *exporter) objectId(sid string) string {
+	if strings.HasPrefix(sid, "S-1-5-32-") {
+		return e.name + "-" + sid
+	}
+	return sid
+}
+
+func (e *exporter) principal(name string) string {
+	return strings.ToUpper(name) + "@" + e.name
+}
+
+func (e *exporter) properties(name, dn string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":              name,
+		"domain":            e.name,
+		"domainsid":         e.result.Domain.SID,
+		"distinguishedname": strings.ToUpper(dn),
+		"highvalue":         false,
+	}
+}
+
+func (e *exporter) base(id string, properties map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"ObjectIdentifier": id,
+		"Properties":       properties,
+		"Aces":             e.emptyList,
+		"IsDeleted":        false,
+		"IsACLProtected":   false,
+		"ContainedBy":      nil,
+	}
+}
+
+func (e *exporter) delegateTargets(spns []string) []bhTyped {
+	targets := []bhTyped{}
+	seen := make(map[string]bool)
+	for _, spn := range spns {
+		_, host, _ := strings.Cut(spn, "/")
+		host, _, _ = strings.Cut(host, ":")
+		host, _, _ = strings.Cut(host, "/")
+		if sid, ok := e.hosts[strings.ToLower(host)]; ok && !seen[sid] {
+			seen[sid] = true
+			targets = append(targets, bhTyped{ObjectIdentifier: sid, ObjectType: "Computer"})
+		}
+	}
+	return targets
+}
+
+func (e *exporter) domain() map[string]interface{} {
+	d := e.result.Domain
+	props := e.properties(e.name, d.DistinguishedName)
+	props["highvalue"] = true
+	props["functionallevel"] = d.FunctionalLevel
+	props["minpwdlength"] = d.PasswordPolicy.MinLength
+	props["pwdhistorylength"] = d.PasswordPolicy.HistoryLength
+	props["lockoutthreshold"] = d.PasswordPolicy.LockoutThreshold
+	props["machineaccountquota"] = d.MachineAccountQuota
+	obj := e.base(d.SID, props)
+
+	links := []map[string]interface{}{}
+	for _, link := range d.GPOLinks {
+		if guid, ok := e.gpoGUIDs[strings.ToLower(link.DistinguishedName)]; ok {
+			links = append(links, map[string]interface{}{"IsEnforced": link.Enforced, "GUID": guid})
+		}
+	}
+	trusts := []map[string]interface{}{}
+	for _, trust := range e.result.Trusts {
+		trusts = append(trusts, map[string]interface{}{
+			"TargetDomainSid":     trust.SID,
+			"TargetDomainName":    strings.ToUpper(trust.Partner),
+			"IsTransitive":        trust.Transitive,
+			"SidFilteringEnabled": trust.SIDFiltering,
+			"TrustDirection":      trust.Direction,
+			"TrustType":           trust.Type,
+		})
+	}
+	obj["Links"] = links
+	obj["Trusts"] = trusts
+	obj["ChildObjects"] = e.emptyList
+	obj["GPOChanges"] = map[string]interface{}{
+		"AffectedComputers":  e.emptyList,
+		"DcomUsers":          e.emptyList,
+		"LocalAdmins":        e.emptyList,
+		"PSRemoteUsers":      e.emptyList,
+		"RemoteDesktopUsers": e.emptyList,
+	}
+	return obj
+}
+
+func (e *exporter) users() []map[string]interface{} {
+	var data []map[string]interface{}
+	for _, user := range e.result.Users {
+		props := e.properties(e.principal(user.Name), user.DistinguishedName)
+		props["samaccountname"] = user.Name
+		props["displayname"] = user.DisplayName
+		props["description"] = user.Description
+		props["enabled"] = user.Enabled
+		props["pwdneverexpires"] = user.PasswordNeverExpires
+		props["passwordnotreqd"] = user.PasswordNotRequired
+		props["dontreqpreauth"] = user.DontRequirePreauth
+		props["admincount"] = user.AdminCount
+		props["sensitive"] = user.Sensitive
+		props["unconstraineddelegation"] = user.UnconstrainedDelegation
+		props["trustedtoauth"] = user.TrustedToAuth
+		props["hasspn"] = len(user.SPNs) > 0
+		props["serviceprincipalnames"] = nonNil(user.SPNs)
+		props["allowedtodelegate"] = nonNil(user.AllowedToDelegate)
+		props["sidhistory"] = nonNil(user.SIDHistory)
+		props["pwdlastset"] = unixTime(user.PasswordLastSet)
+		props["lastlogontimestamp"] = unixTime(user.LastLogon)
+		obj := e.base(user.SID, props)
+		obj["PrimaryGroupSID"] = user.PrimaryGroupSID
+		obj["AllowedToDelegate"] = e.delegateTargets(user.AllowedToDelegate)
+		obj["HasSIDHistory"] = e.typedSIDs(user.SIDHistory, "User")
+		obj["SPNTargets"] = e.emptyList
+		data = append(data, obj)
+	}
+	return data
+}
+
+func (e *exporter) groups() []map[string]interface{} {
+	var data []map[string]interface{}
+	for _, group := range e.result.Groups {
+		props := e.properties(e.principal(group.Name), group.DistinguishedName)
+		props["samaccountname"] = group.Name
+		props["description"] = group.Description
+		props["admincount"] = group.AdminCount
+		members := []bhTyped{}
+		for _, member := range group.Members {
+			if member.SID != "" {
+				members = append(members, bhTyped{ObjectIdentifier: e.objectId(member.SID), ObjectType: member.Type})
+			}
+		}
+		obj := e.base(e.objectId(group.SID), props)
+		obj["Members"] = members
+		data = append(data, obj)
+	}
+	return data
+}
+
+func (e *exporter) computers() []map[string]interface{} {
+	var data []map[string]interface{}
+	for _, computer := range e.result.Computers {
+		name := computer.DNSHostName
+		if name == "" {
+			name = computer.Name + "." + e.result.Domain.Name
+		}
+		props := e.properties(strings.ToUpper(name), computer.DistinguishedName)
+		props["samaccountname"] = computer.Name + "$"
+		props["enabled"] = computer.Enabled
+		props["unconstraineddelegation"] = computer.UnconstrainedDelegation
+		props["trustedtoauth"] = computer.TrustedToAuth
+		props["operatingsystem"] = computer.OperatingSystem
+		props["serviceprincipalnames"] = nonNil(computer.SPNs)
+		props["allowedtodelegate"] = nonNil(computer.AllowedToDelegate)
+		props["lastlogontimestamp"] = unixTime(computer.LastLogon)
+		obj := e.base(computer.SID, props)
+		obj["PrimaryGroupSID"] = computer.PrimaryGroupSID
+		obj["AllowedToDelegate"] = e.delegateTargets(computer.AllowedToDelegate)
+		obj["AllowedToAct"] = e.typedSIDs(computer.AllowedToAct, "Base")
+		obj["HasSIDHistory"] = e.emptyList
+		obj["DumpSMSAPassword"] = e.emptyList
+		obj["IsDC"] = computer.DomainController
+		obj["DomainSID"] = e.result.Domain.SID
+		obj["Status"] = nil
+		obj["LocalGroups"] = e.emptyList
+		obj["UserRights"] = e.emptyList
+		for _, key := range []string{"Sessions", "PrivilegedSessions", "RegistrySessions"} {
+			obj[key] = bhCollection{Results: e.emptyList}
+		}
+		data = append(data, obj)
+	}
+	return data
+}
+
+func (e *exporter) gpos() []map[string]interface{} {
+	var data []map[string]interface{}
+	for _, gpo := range e.result.GPOs {
+		props := e.properties(e.principal(gpo.Name), gpo.DistinguishedName)
+		props["gpcpath"] = strings.ToUpper(gpo.Path)
+		data = append(data, e.base(gpo.ObjectGUID, props))
+	}
+	return data
+}
+
+// typedSIDs 将本地已收集对象的 SID 标注类型，未知时使用 fallback
+func (e *exporter) typedSIDs(sids []string, fallback string) []bhTyped {
+	typed := []bhTyped{}
+	for _, sid := range sids {
+		typ, ok := e.types[sid]
+		if !ok {
+			typ = fallback
+		}
+		typed = append(typed, bhTyped{ObjectIdentifier: e.objectId(sid), ObjectType: typ})
+	}
+	return typed
+}
+
+func unixTime(t string) int64 {
+	parsed, err := time.ParseInLocation("2006-01-02 15:04:05", t, time.Local)
+	if err != nil {
+		return 0
+	}
+	return parsed.Unix()
+}
+
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
@@ -0,0 +1,61 @@
+package adrecon
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// ParseSID 将 objectSid 等二进制 SID 转换为 S-1-5-21-... 形式
+func ParseSID(b []byte) string {
+	if len(b) < 8 || len(b) < 8+int(b[1])*4 {
+		return ""
+	}
+	var authority uint64
+	for _, v := range b[2:8] {
+		authority = authority<<8 | uint64(v)
+	}
+	sid := fmt.Sprintf("S-%d-%d", b[0], authority)
+	for i := 0; i < int(b[1]); i++ {
+		sid += fmt.Sprintf("-%d", binary.LittleEndian.Uint32(b[8+i*4:]))
+	}
+	return sid
+}
+
+// ParseGUID 转换 objectGUID，前三段为小端序
+func ParseGUID(b []byte) string {
+	if len(b) != 16 {
+		return ""
+	}
+	return strings.ToUpper(fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(b[0:4]), binary.LittleEndian.Uint16(b[4:6]), binary.LittleEndian.Uint16(b[6:8]), b[8:10], b[10:16]))
+}
+
+// ParseSecurityDescriptorSIDs 提取自相关格式安全描述符中 DACL 允许访问的 ACE 主体，
+// 用于解析 msDS-AllowedToActOnBehalfOfOtherIdentity
+func ParseSecurityDescriptorSIDs(b []byte) []string {
+	if len(b) < 20 {
+		return nil
+	}
+	offset := int(binary.LittleEndian.Uint32(b[16:20]))
+	if offset == 0 || offset+8 > len(b) {
+		return nil
+	}
+	count := int(binary.LittleEndian.Uint16(b[offset+4 : offset+6]))
+	var sids []string
+	pos := offset + 8
+	for i := 0; i < count && pos+4 <= len(b); i++ {
+		aceType, aceSize := b[pos], int(binary.LittleEndian.Uint16(b[pos+2:pos+4]))
+		if aceSize < 4 || pos+aceSize > len(b) {
+			break
+		}
+		// ACCESS_ALLOWED_ACE: 头部 4 字节 + 掩码 4 字节 + SID
+		if aceType == 0x00 && aceSize > 8 {
+			if sid := ParseSID(b[pos+8 : pos+aceSize]); sid != "" {
+				sids = append(sids, sid)
+			}
+		}
+		pos += aceSize
+	}
+	return sids
+}
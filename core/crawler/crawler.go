@@ -0,0 +1,488 @@
+// 网站爬虫，静态解析或在无头浏览器中渲染页面，收集链接、表单与接口参数供目录扫描、JS 接口分析与漏洞扫描使用
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"path"
+	"regexp"
+	"slack-wails/lib/browser"
+	"slack-wails/lib/structs"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"github.com/go-resty/resty/v2"
+	"github.com/panjf2000/ants/v2"
+	"github.com/qiwentaidi/clients"
+	"golang.org/x/net/publicsuffix"
+)
+
+// 爬取范围
+const (
+	ScopeHost   = "host"   // 只爬取与起始页面相同的主机与端口
+	ScopeDomain = "domain" // 爬取同一主域名下的全部主机
+)
+
+// 请求的发现方式
+const (
+	SourceLink = "link"
+	SourceForm = "form"
+	SourceXHR  = "xhr"
+)
+
+type Options struct {
+	MaxDepth int               // 从起始页面开始跟随链接的层数
+	MaxPages int               // 单个目标最多访问的页面数
+	Scope    string            // ScopeHost 或 ScopeDomain
+	Headless bool              // 使用无头浏览器渲染页面并记录 XHR/fetch 请求
+	Thread   int               // 同时访问的页面数
+	Headers  map[string]string // 自定义请求头
+}
+
+var DefaultOptions = Options{
+	MaxDepth: 3,
+	MaxPages: 300,
+	Scope:    ScopeHost,
+	Thread:   10,
+}
+
+// 静态资源既不访问也不作为请求输出
+var staticExts = map[string]bool{
+	".js": true, ".css": true, ".map": true, ".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".bmp": true,
+	".svg": true, ".ico": true, ".webp": true, ".woff": true, ".woff2": true, ".ttf": true, ".eot": true, ".otf": true,
+	".mp3": true, ".mp4": true, ".avi": true, ".flv": true, ".pdf": true, ".doc": true, ".docx": true, ".xls": true,
+	".xlsx": true, ".ppt": true, ".pptx": true, ".zip": true, ".rar": true, ".7z": true, ".gz": true, ".tar": true,
+	".exe": true, ".apk": true, ".dmg": true, ".iso": true,
+}
+
+// 可能导致会话失效或修改数据的链接只记录不访问
+var unsafeLink = regexp.MustCompile(`(?i)(logout|logoff|signout|sign-out|log-out|delete|remove|destroy)`)
+
+func (o Options) withDefaults() Options {
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = DefaultOptions.MaxDepth
+	}
+	if o.MaxPages <= 0 {
+		o.MaxPages = DefaultOptions.MaxPages
+	}
+	if o.Scope != ScopeDomain {
+		o.Scope = ScopeHost
+	}
+	if o.Thread <= 0 {
+		o.Thread = DefaultOptions.Thread
+	}
+	return o
+}
+
+type crawler struct {
+	ctx        context.Context
+	root       *url.URL
+	rootDomain string
+	options    Options
+	client     *resty.Client
+
+	mutex     sync.Mutex
+	pages     map[string]bool                   // 已访问的页面
+	endpoints map[string]*structs.CrawlEndpoint // 请求方式 + 地址 + 参数名称 -> 请求
+	next      []*url.URL                        // 下一层待访问的页面
+}
+
+// Crawl 从 target 开始按层爬取，返回发现的全部请求
+func Crawl(ctx context.Context, target string, client *resty.Client, options Options) []structs.CrawlEndpoint {
+	root, err := url.Parse(target)
+	if err != nil || root.Host == "" {
+		return nil
+	}
+	root.Fragment = ""
+	if root.Path == "" {
+		root.Path = "/"
+	}
+	c := &crawler{
+		ctx:       ctx,
+		root:      root,
+		options:   options.withDefaults(),
+		client:    client,
+		pages:     make(map[string]bool),
+		endpoints: make(map[string]*structs.CrawlEndpoint),
+	}
+	c.rootDomain, _ = publicsuffix.EffectiveTLDPlusOne(root.Hostname())
+
+	var wg sync.WaitGroup
+	pool, _ := ants.NewPoolWithFunc(c.options.Thread, func(arg interface{}) {
+		defer wg.Done()
+		page := arg.(*visit)
+		c.crawlPage(page.url, page.depth)
+	})
+	defer pool.Release()
+
+	frontier := []*url.URL{root}
+	for depth := 0; depth <= c.options.MaxDepth && len(frontier) > 0; depth++ {
+		for _, u := range frontier {
+			if ctx.Err() != nil || !c.markVisited(u) {
+				continue
+			}
+			wg.Add(1)
+			pool.Invoke(&visit{url: u, depth: depth})
+		}
+		wg.Wait()
+		c.mutex.Lock()
+		frontier, c.next = c.next, nil
+		c.mutex.Unlock()
+	}
+	return c.result()
+}
+
+type visit struct {
+	url   *url.URL
+	depth int
+}
+
+// markVisited 页面未访问且未超过数量上限时记录并返回 true，参数值不同的同一页面只访问一次
+func (c *crawler) markVisited(u *url.URL) bool {
+	key := endpointKey("GET", u)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.pages[key] || len(c.pages) >= c.options.MaxPages {
+		return false
+	}
+	c.pages[key] = true
+	return true
+}
+
+func (c *crawler) crawlPage(u *url.URL, depth int) {
+	var (
+		base *url.URL
+		html string
+		ok   bool
+	)
+	if c.options.Headless {
+		base, html, ok = c.render(u, depth)
+	}
+	if !ok {
+		if base, html, ok = c.fetch(u); !ok {
+			return
+		}
+	}
+	if depth == 0 {
+		c.record(structs.CrawlEndpoint{URL: u.String(), Method: "GET", Source: SourceLink}, u)
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return
+	}
+	if href, ok := doc.Find("base[href]").Attr("href"); ok {
+		if resolved, err := base.Parse(strings.TrimSpace(href)); err == nil {
+			base = resolved
+		}
+	}
+	for _, link := range extractLinks(doc) {
+		c.addLink(base, link, depth)
+	}
+	doc.Find("form").Each(func(_ int, form *goquery.Selection) {
+		c.addForm(base, form, depth)
+	})
+}
+
+// fetch 静态请求页面，非 HTML 响应不再解析
+func (c *crawler) fetch(u *url.URL) (*url.URL, string, bool) {
+	resp, err := clients.DoRequest("GET", u.String(), c.options.Headers, nil, 10, c.client)
+	if err != nil || resp == nil || resp.RawResponse == nil {
+		return nil, "", false
+	}
+	contentType := strings.ToLower(resp.Header().Get("Content-Type"))
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		return nil, "", false
+	}
+	// 跟随跳转后以最终地址解析相对链接，跳出范围时不再解析
+	final := u
+	if resp.RawResponse.Request != nil && resp.RawResponse.Request.URL != nil {
+		final = resp.RawResponse.Request.URL
+	}
+	if !c.inScope(final) {
+		return nil, "", false
+	}
+	return final, string(resp.Body()), true
+}
+
+// render 在无头浏览器中打开页面，记录页面发出的 XHR/fetch 请求并返回渲染后的 DOM
+func (c *crawler) render(u *url.URL, depth int) (*url.URL, string, bool) {
+	var (
+		html     string
+		location string
+		mutex    sync.Mutex
+		requests []*network.Request
+	)
+	pool := browser.Shared()
+	err := pool.Do(c.ctx, func(tab context.Context) error {
+		chromedp.ListenTarget(tab, func(ev interface{}) {
+			e, ok := ev.(*network.EventRequestWillBeSent)
+			if !ok || (e.Type != network.ResourceTypeXHR && e.Type != network.ResourceTypeFetch) {
+				return
+			}
+			mutex.Lock()
+			requests = append(requests, e.Request)
+			mutex.Unlock()
+		})
+		actions := []chromedp.Action{network.Enable()}
+		if len(c.options.Headers) > 0 {
+			headers := make(network.Headers, len(c.options.Headers))
+			for k, v := range c.options.Headers {
+				headers[k] = v
+			}
+			actions = append(actions, network.SetExtraHTTPHeaders(headers))
+		}
+		actions = append(actions,
+			pool.Navigate(u.String()),
+			chromedp.Location(&location),
+			chromedp.OuterHTML("html", &html, chromedp.ByQuery),
+		)
+		return chromedp.Run(tab, actions...)
+	})
+	if err != nil {
+		return nil, "", false
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, req := range requests {
+		c.addRequest(req, depth)
+	}
+	final, err := url.Parse(location)
+	if err != nil || final.Host == "" {
+		final = u
+	}
+	if !c.inScope(final) {
+		return nil, "", false
+	}
+	return final, html, true
+}
+
+// extractLinks 页面中可以跟随的链接
+func extractLinks(doc *goquery.Document) []string {
+	var links []string
+	for _, item := range []struct{ selector, attr string }{
+		{"a[href]", "href"},
+		{"area[href]", "href"},
+		{"iframe[src]", "src"},
+		{"frame[src]", "src"},
+	} {
+		doc.Find(item.selector).Each(func(_ int, s *goquery.Selection) {
+			links = append(links, s.AttrOr(item.attr, ""))
+		})
+	}
+	// <meta http-equiv="refresh" content="0;url=/login">
+	doc.Find("meta[http-equiv]").Each(func(_ int, s *goquery.Selection) {
+		if !strings.EqualFold(s.AttrOr("http-equiv", ""), "refresh") {
+			return
+		}
+		content := s.AttrOr("content", "")
+		if i := strings.Index(strings.ToLower(content), "url="); i >= 0 {
+			links = append(links, strings.Trim(content[i+4:], `'" `))
+		}
+	})
+	return links
+}
+
+func (c *crawler) addLink(base *url.URL, link string, depth int) {
+	link = strings.TrimSpace(link)
+	lower := strings.ToLower(link)
+	if link == "" || strings.HasPrefix(link, "#") || strings.HasPrefix(lower, "javascript:") || strings.HasPrefix(lower, "mailto:") ||
+		strings.HasPrefix(lower, "tel:") || strings.HasPrefix(lower, "data:") {
+		return
+	}
+	u, err := base.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !c.inScope(u) || isStatic(u) {
+		return
+	}
+	u.Fragment = ""
+	c.record(structs.CrawlEndpoint{URL: u.String(), Method: "GET", Source: SourceLink, Depth: depth + 1}, u)
+	if depth < c.options.MaxDepth && !unsafeLink.MatchString(u.Path) {
+		c.mutex.Lock()
+		c.next = append(c.next, u)
+		c.mutex.Unlock()
+	}
+}
+
+// addForm 记录表单提交的请求，表单不会被提交
+func (c *crawler) addForm(base *url.URL, form *goquery.Selection, depth int) {
+	action, err := base.Parse(strings.TrimSpace(form.AttrOr("action", "")))
+	if err != nil || (action.Scheme != "http" && action.Scheme != "https") || !c.inScope(action) {
+		return
+	}
+	action.Fragment = ""
+	values := url.Values{}
+	form.Find("input[name], select[name], textarea[name]").Each(func(_ int, field *goquery.Selection) {
+		name := field.AttrOr("name", "")
+		inputType := strings.ToLower(field.AttrOr("type", "text"))
+		if inputType == "submit" || inputType == "button" || inputType == "reset" || inputType == "image" || inputType == "file" {
+			return
+		}
+		value, ok := field.Attr("value")
+		if goquery.NodeName(field) == "select" {
+			value, ok = field.Find("option").First().Attr("value")
+		}
+		if !ok || value == "" {
+			value = defaultValue(inputType)
+		}
+		values.Add(name, value)
+	})
+	method := strings.ToUpper(strings.TrimSpace(form.AttrOr("method", "GET")))
+	endpoint := structs.CrawlEndpoint{Method: method, Source: SourceForm, Depth: depth + 1}
+	if method == "GET" {
+		query := action.Query()
+		for k, v := range values {
+			query[k] = v
+		}
+		action.RawQuery = query.Encode()
+	} else {
+		endpoint.Body = values.Encode()
+		endpoint.ContentType = strings.ToLower(form.AttrOr("enctype", "application/x-www-form-urlencoded"))
+	}
+	endpoint.URL = action.String()
+	endpoint.Params = sortedKeys(values)
+	c.record(endpoint, action)
+}
+
+// addRequest 记录浏览器中页面发出的接口请求
+func (c *crawler) addRequest(req *network.Request, depth int) {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !c.inScope(u) || isStatic(u) {
+		return
+	}
+	endpoint := structs.CrawlEndpoint{URL: u.String(), Method: strings.ToUpper(req.Method), Source: SourceXHR, Depth: depth}
+	for name, value := range req.Headers {
+		if strings.EqualFold(name, "Content-Type") {
+			endpoint.ContentType, _ = value.(string)
+		}
+	}
+	var body bytes.Buffer
+	for _, entry := range req.PostDataEntries {
+		if data, err := base64.StdEncoding.DecodeString(entry.Bytes); err == nil {
+			body.Write(data)
+		}
+	}
+	endpoint.Body = body.String()
+	endpoint.Params = bodyParams(endpoint.ContentType, endpoint.Body)
+	c.record(endpoint, u)
+}
+
+// bodyParams 表单与 JSON 请求体中的参数名称
+func bodyParams(contentType, body string) []string {
+	if body == "" {
+		return nil
+	}
+	if strings.Contains(contentType, "json") || strings.HasPrefix(strings.TrimSpace(body), "{") {
+		var object map[string]interface{}
+		if json.Unmarshal([]byte(body), &object) == nil {
+			names := make([]string, 0, len(object))
+			for name := range object {
+				names = append(names, name)
+			}
+			return names
+		}
+		return nil
+	}
+	if values, err := url.ParseQuery(body); err == nil {
+		return sortedKeys(values)
+	}
+	return nil
+}
+
+// record 合并同一请求的参数，查询参数名称也作为参数输出
+func (c *crawler) record(endpoint structs.CrawlEndpoint, u *url.URL) {
+	endpoint.Params = append(endpoint.Params, sortedKeys(u.Query())...)
+	key := endpointKey(endpoint.Method, u)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if exist, ok := c.endpoints[key]; ok {
+		exist.Params = mergeParams(exist.Params, endpoint.Params)
+		if exist.Body == "" {
+			exist.Body, exist.ContentType = endpoint.Body, endpoint.ContentType
+		}
+		return
+	}
+	endpoint.Params = mergeParams(nil, endpoint.Params)
+	c.endpoints[key] = &endpoint
+}
+
+func (c *crawler) result() []structs.CrawlEndpoint {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	endpoints := make([]structs.CrawlEndpoint, 0, len(c.endpoints))
+	for _, endpoint := range c.endpoints {
+		endpoints = append(endpoints, *endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].URL != endpoints[j].URL {
+			return endpoints[i].URL < endpoints[j].URL
+		}
+		return endpoints[i].Method < endpoints[j].Method
+	})
+	return endpoints
+}
+
+func (c *crawler) inScope(u *url.URL) bool {
+	if strings.EqualFold(u.Host, c.root.Host) {
+		return true
+	}
+	if c.options.Scope != ScopeDomain || c.rootDomain == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == c.rootDomain || strings.HasSuffix(host, "."+c.rootDomain)
+}
+
+func isStatic(u *url.URL) bool {
+	return staticExts[strings.ToLower(path.Ext(u.Path))]
+}
+
+// endpointKey 参数值不同的同一请求视为重复，避免翻页、日期等参数导致无限爬取
+func endpointKey(method string, u *url.URL) string {
+	return method + " " + u.Scheme + "://" + strings.ToLower(u.Host) + u.EscapedPath() + "?" + strings.Join(sortedKeys(u.Query()), "&")
+}
+
+func defaultValue(inputType string) string {
+	switch inputType {
+	case "email":
+		return "test@example.com"
+	case "number", "range":
+		return "1"
+	case "checkbox", "radio":
+		return "on"
+	case "url":
+		return "http://example.com"
+	case "tel":
+		return "13800138000"
+	case "date":
+		return "2024-01-01"
+	}
+	return "test"
+}
+
+func sortedKeys(values url.Values) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func mergeParams(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var result []string
+	for _, name := range append(a, b...) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,161 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slack-wails/lib/structs"
+	"strings"
+	"testing"
+
+	"github.com/qiwentaidi/clients"
+)
+
+func TestCrawl(t *testing.T) {
+	var logoutVisited bool
+	mux := http.NewServeMux()
+	page := func(path, body string) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != path {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, body)
+		})
+	}
+	page("/", `<html><body>
+		<a href="/admin/user/list?page=1&size=10">users</a>
+		<a href="/static/app.js">js</a>
+		<a href="/logout">logout</a>
+		<a href="http://other.example.com/">other</a>
+		<a href="javascript:void(0)">noop</a>
+		<form action="/search" method="get"><input name="q"><input type="submit" name="go"></form>
+		</body></html>`)
+	page("/admin/user/list", `<html><body>
+		<a href="/admin/user/list?page=2&size=10">next</a>
+		<a href="/admin/user/detail?id=1">detail</a>
+		<form action="/admin/user/save" method="post">
+			<input type="hidden" name="token" value="abc">
+			<input type="email" name="email">
+			<select name="role"><option value="admin">admin</option></select>
+			<textarea name="remark"></textarea>
+		</form>
+		</body></html>`)
+	page("/admin/user/detail", `<html><body><a href="/admin/too/deep">deep</a></body></html>`)
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		logoutVisited = true
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	endpoints := Crawl(context.Background(), server.URL, clients.NewRestyClient(nil, true), Options{MaxDepth: 2})
+	byURL := make(map[string]structs.CrawlEndpoint)
+	for _, endpoint := range endpoints {
+		byURL[strings.TrimPrefix(endpoint.URL, server.URL)] = endpoint
+	}
+
+	if logoutVisited {
+		t.Fatal("logout link should be recorded but not visited")
+	}
+	if _, ok := byURL["/logout"]; !ok {
+		t.Fatal("logout link not recorded")
+	}
+	for _, path := range []string{"/static/app.js", "http://other.example.com/"} {
+		if _, ok := byURL[path]; ok {
+			t.Fatalf("%s should be filtered", path)
+		}
+	}
+	// 参数值不同的同一页面只记录一次
+	list := 0
+	for path := range byURL {
+		if strings.HasPrefix(path, "/admin/user/list") {
+			list++
+		}
+	}
+	if list != 1 {
+		t.Fatalf("expected a single /admin/user/list endpoint, got %d", list)
+	}
+	if endpoint, ok := byURL["/admin/too/deep"]; !ok || endpoint.Depth != 3 {
+		t.Fatalf("deep link should be recorded at depth 3, got %+v", endpoint)
+	}
+
+	search, ok := byURL["/search?q=test"]
+	if !ok || search.Source != SourceForm || !reflect.DeepEqual(search.Params, []string{"q"}) {
+		t.Fatalf("unexpected GET form endpoint: %+v", search)
+	}
+	save, ok := byURL["/admin/user/save"]
+	if !ok || save.Method != "POST" || save.ContentType != "application/x-www-form-urlencoded" {
+		t.Fatalf("unexpected POST form endpoint: %+v", save)
+	}
+	if save.Body != "email=test%40example.com&remark=test&role=admin&token=abc" {
+		t.Fatalf("unexpected form body: %s", save.Body)
+	}
+	if !reflect.DeepEqual(save.Params, []string{"email", "remark", "role", "token"}) {
+		t.Fatalf("unexpected form params: %v", save.Params)
+	}
+
+	if got := Directories(endpoints); !reflect.DeepEqual(got, []string{server.URL + "/admin/", server.URL + "/admin/too/", server.URL + "/admin/user/"}) {
+		t.Fatalf("unexpected directories: %v", got)
+	}
+	if got := FuzzRequests(endpoints); len(got) != 4 || got[2].URL != save.URL {
+		t.Fatalf("expected 3 GET and 1 POST fuzz requests, got %v", got)
+	}
+	raw, err := RawRequest(save)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := strings.TrimPrefix(server.URL, "http://")
+	if want := "POST /admin/user/save HTTP/1.1\r\nHost: " + host + "\r\nContent-Type: application/x-www-form-urlencoded\r\nContent-Length: 57\r\n\r\n" + save.Body; raw != want {
+		t.Fatalf("unexpected raw request: %q", raw)
+	}
+	if got := Params(endpoints); !reflect.DeepEqual(got, []string{"email", "id", "page", "q", "remark", "role", "size", "token"}) {
+		t.Fatalf("unexpected params: %v", got)
+	}
+
+	Remember(server.URL+"/index", endpoints)
+	if got := APIs(server.URL, Endpoints(server.URL)); len(got) == 0 || got[0] != "/admin/too/deep" {
+		t.Fatalf("unexpected apis: %v", got)
+	}
+	if got := APIs(server.URL+"/admin/user/", endpoints); !reflect.DeepEqual(got, []string{"/detail", "/list", "/save"}) {
+		t.Fatalf("unexpected apis under base path: %v", got)
+	}
+}
+
+func TestReset(t *testing.T) {
+	Remember("http://www.example.com/", []structs.CrawlEndpoint{{URL: "http://www.example.com/api/user", Method: "GET"}})
+	if len(Endpoints("http://www.example.com/login")) != 1 {
+		t.Fatal("endpoints not remembered")
+	}
+	Reset()
+	if got := Endpoints("http://www.example.com/login"); got != nil {
+		t.Fatalf("endpoints should be cleared, got %v", got)
+	}
+}
+
+func TestInScope(t *testing.T) {
+	for _, tc := range []struct {
+		scope, target string
+		want          bool
+	}{
+		{ScopeHost, "http://www.example.com/a", true},
+		{ScopeHost, "http://api.example.com/a", false},
+		{ScopeDomain, "http://api.example.com/a", true},
+		{ScopeDomain, "http://example.com/a", true},
+		{ScopeDomain, "http://example.com.evil.com/a", false},
+	} {
+		if got := scopeOf(tc.scope, tc.target); got != tc.want {
+			t.Errorf("scope %s target %s: got %v want %v", tc.scope, tc.target, got, tc.want)
+		}
+	}
+}
+
+func scopeOf(scope, target string) bool {
+	root, _ := url.Parse("http://www.example.com/")
+	u, _ := url.Parse(target)
+	c := &crawler{root: root, rootDomain: "example.com", options: Options{Scope: scope}.withDefaults()}
+	return c.inScope(u)
+}
@@ -0,0 +1,138 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"slack-wails/lib/structs"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 按站点记录最近一次网站扫描的爬虫结果，供目录扫描与 JS 接口分析复用，每次网站扫描开始时清空
+var crawled sync.Map
+
+// Reset 清空上一次网站扫描记录的爬虫结果
+func Reset() {
+	crawled.Clear()
+}
+
+// Remember 记录爬虫结果，同一站点再次爬取时覆盖
+func Remember(target string, endpoints []structs.CrawlEndpoint) {
+	if origin := originOf(target); origin != "" && len(endpoints) > 0 {
+		crawled.Store(origin, endpoints)
+	}
+}
+
+// Endpoints 返回站点已记录的爬虫结果
+func Endpoints(target string) []structs.CrawlEndpoint {
+	if value, ok := crawled.Load(originOf(target)); ok {
+		return value.([]structs.CrawlEndpoint)
+	}
+	return nil
+}
+
+// Directories 请求所在的目录，用作目录扫描的起始地址，例如 http://a.com/admin/user/list -> http://a.com/admin/ 与 http://a.com/admin/user/
+func Directories(endpoints []structs.CrawlEndpoint) []string {
+	set := make(map[string]bool)
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint.URL)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		dir := path.Dir(u.Path)
+		for dir != "/" && dir != "." && dir != "" {
+			set[u.Scheme+"://"+u.Host+dir+"/"] = true
+			dir = path.Dir(dir)
+		}
+	}
+	return sortedSet(set)
+}
+
+// APIs 属于 baseURL 的请求路径，去掉 baseURL 的路径前缀后作为 JS 接口分析的接口列表
+func APIs(baseURL string, endpoints []structs.CrawlEndpoint) []string {
+	base, err := url.Parse(baseURL)
+	if err != nil || base.Host == "" {
+		return nil
+	}
+	origin, prefix := originOf(baseURL), strings.TrimRight(base.Path, "/")
+	set := make(map[string]bool)
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint.URL)
+		if err != nil || originOf(endpoint.URL) != origin || !strings.HasPrefix(u.Path, prefix+"/") {
+			continue
+		}
+		if api := strings.TrimPrefix(u.Path, prefix); api != "/" {
+			set[api] = true
+		}
+	}
+	return sortedSet(set)
+}
+
+// FuzzRequests 带查询参数或请求体的请求，包括 GET 链接、POST 表单与 XHR 接口请求，作为 DAST 模板的参数模糊测试目标
+func FuzzRequests(endpoints []structs.CrawlEndpoint) []structs.CrawlEndpoint {
+	var result []structs.CrawlEndpoint
+	for _, endpoint := range endpoints {
+		if endpoint.Body != "" || strings.Contains(endpoint.URL, "?") {
+			result = append(result, endpoint)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].URL != result[j].URL {
+			return result[i].URL < result[j].URL
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
+
+// RawRequest 请求的原始报文，Host 头为请求链接中的地址
+func RawRequest(endpoint structs.CrawlEndpoint) (string, error) {
+	u, err := url.Parse(endpoint.URL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid url: %s", endpoint.URL)
+	}
+	method := endpoint.Method
+	if method == "" {
+		method = "GET"
+	}
+	var raw strings.Builder
+	fmt.Fprintf(&raw, "%s %s HTTP/1.1\r\nHost: %s\r\n", method, u.RequestURI(), u.Host)
+	if endpoint.Body != "" {
+		if endpoint.ContentType != "" {
+			fmt.Fprintf(&raw, "Content-Type: %s\r\n", endpoint.ContentType)
+		}
+		fmt.Fprintf(&raw, "Content-Length: %d\r\n", len(endpoint.Body))
+	}
+	raw.WriteString("\r\n" + endpoint.Body)
+	return raw.String(), nil
+}
+
+// Params 全部请求中出现过的参数名称
+func Params(endpoints []structs.CrawlEndpoint) []string {
+	set := make(map[string]bool)
+	for _, endpoint := range endpoints {
+		for _, param := range endpoint.Params {
+			set[param] = true
+		}
+	}
+	return sortedSet(set)
+}
+
+func originOf(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+func sortedSet(set map[string]bool) []string {
+	result := make([]string, 0, len(set))
+	for item := range set {
+		result = append(result, item)
+	}
+	sort.Strings(result)
+	return result
+}
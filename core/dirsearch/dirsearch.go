@@ -3,6 +3,9 @@ package dirsearch
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"slack-wails/core/crawler"
+	"slack-wails/core/waf"
 	"slack-wails/lib/gologger"
 	"slack-wails/lib/utils/arrayutil"
 	"slack-wails/lib/utils/httputil"
@@ -54,11 +57,33 @@ type Options struct {
 	CustomHeader           string
 	Recursion              int
 	Backupscan             bool
+	Crawled                bool // 追加网站扫描时爬虫发现的目录作为扫描目标
 }
 
 func NewDirsearchEngine(ctx, ctrlCtx context.Context, o Options) *Dirsearch {
 	headers := clients.Str2HeadersMap(o.CustomHeader)
 
+	if o.Crawled {
+		var directories []string
+		for _, u := range o.URLs {
+			directories = append(directories, crawler.Directories(crawler.Endpoints(u))...)
+		}
+		if len(directories) > 0 {
+			gologger.Info(ctx, fmt.Sprintf("[dirsearch] append %d directories found by crawler", len(directories)))
+			o.URLs = arrayutil.RemoveDuplicates(append(o.URLs, directories...))
+		}
+	}
+
+	// 目标存在网站扫描识别到的 WAF 时降低线程并增加请求间隔
+	for _, u := range o.URLs {
+		if name, ok := waf.Detected(u); ok {
+			o.Workers = min(o.Workers, waf.ThrottledWorkers)
+			o.Interval = max(o.Interval, waf.ThrottledInterval)
+			gologger.Warning(ctx, fmt.Sprintf("[dirsearch] %s is protected by %s, workers %d, interval %ds", u, name, o.Workers, o.Interval))
+			break
+		}
+	}
+
 	return &Dirsearch{
 		ctx:           ctx,
 		options:       o,
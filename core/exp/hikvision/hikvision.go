@@ -108,8 +108,17 @@ func CVE_2021_36260(url, cmd string, client *resty.Client) string {
 	return "[-] 不存在CVE-2021-36260"
 }
 
+// LoginResult 弱口令检测结果，Message 为展示给用户的日志
+type LoginResult struct {
+	Target   string
+	Username string
+	Password string
+	Success  bool
+	Message  string
+}
+
 // 弱口令检测
-func CameraHandlessLogin(appCtx context.Context, url, username string, password []string) string {
+func CameraHandlessLogin(appCtx context.Context, url, username string, password []string) LoginResult {
 	// 设置 Chrome 执行选项
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
 		chromedp.Flag("headless", true),
@@ -146,16 +155,16 @@ func CameraHandlessLogin(appCtx context.Context, url, username string, password
 			chromedp.Text(`label.ng-binding`, &errorMessage, chromedp.NodeVisible),
 		)
 		if err != nil {
-			return fmt.Sprintf("[-] %s  %v\n", url, err)
+			return LoginResult{Target: url, Username: username, Message: fmt.Sprintf("[-] %s  %v\n", url, err)}
 		}
 
 		// 根据 URL 判断登录是否成功
 		if strings.Contains(errorMessage, "用户名或密码不正确") || strings.Contains(errorMessage, "Incorrect user name or password") {
-			gologger.Info(appCtx, fmt.Sprintf("[hivision] %s admin:%s login failed", url, pass))
+			gologger.Info(appCtx, fmt.Sprintf("[hivision] %s %s:%s login failed", url, username, pass))
 		} else {
-			return fmt.Sprintf("[+] %s admin:%s login success!!\n", url, pass)
+			return LoginResult{Target: url, Username: username, Password: pass, Success: true, Message: fmt.Sprintf("[+] %s %s:%s login success!!\n", url, username, pass)}
 		}
 	}
 
-	return fmt.Sprintf("[-] %s all passwords failed to login\n", url)
+	return LoginResult{Target: url, Username: username, Message: fmt.Sprintf("[-] %s all passwords failed to login\n", url)}
 }
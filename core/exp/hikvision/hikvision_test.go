@@ -8,5 +8,5 @@ import (
 
 func TestLogin(t *testing.T) {
 	result := CameraHandlessLogin(context.Background(), "http://xxxxxxxxx/", "admin", []string{"hik12345"})
-	fmt.Printf("result: %v", result)
+	fmt.Printf("result: %+v", result)
 }
@@ -0,0 +1,74 @@
+package jsfind
+
+import (
+	"regexp"
+	"slack-wails/lib/structs"
+	"slack-wails/lib/utils/netutil"
+	"strings"
+)
+
+var (
+	credentialField = regexp.MustCompile(`(?i)^["']?(username|password|.{0,5}账号|.{0,5}密码)["']?\s*[:=]\s*["']?([^"'\s,;]+)`)
+	// 形如 e.password、this.form.username 的变量引用
+	credentialVariable = regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[\w$]+)+$`)
+	credentialIgnore   = []string{"null", "undefined", "true", "false", "string", "password", "username", "required"}
+)
+
+// ExtractCredentials 从敏感信息中按来源文件配对账号与密码，凭据归属于扫描目标
+func ExtractCredentials(target string, sensitive []structs.InfoSource) []structs.Credential {
+	protocol, host, port := netutil.SplitTarget(target)
+	var (
+		sources   []string
+		seen      = make(map[string]bool)
+		usernames = make(map[string][]string)
+		passwords = make(map[string][]string)
+	)
+	for _, is := range sensitive {
+		match := credentialField.FindStringSubmatch(strings.TrimSpace(is.Filed))
+		if match == nil || !isCredentialValue(match[2]) {
+			continue
+		}
+		if !seen[is.Source] {
+			seen[is.Source] = true
+			sources = append(sources, is.Source)
+		}
+		key := strings.ToLower(match[1])
+		if strings.Contains(key, "password") || strings.Contains(key, "密码") {
+			passwords[is.Source] = append(passwords[is.Source], match[2])
+		} else {
+			usernames[is.Source] = append(usernames[is.Source], match[2])
+		}
+	}
+	var creds []structs.Credential
+	for _, source := range sources {
+		users := usernames[source]
+		for i, pass := range passwords[source] {
+			cred := structs.Credential{
+				Host:     host,
+				Port:     port,
+				Protocol: protocol,
+				Password: pass,
+				Source:   "jsfind",
+			}
+			if i < len(users) {
+				cred.Username = users[i]
+			} else if len(users) > 0 {
+				cred.Username = users[0]
+			}
+			creds = append(creds, cred)
+		}
+	}
+	return creds
+}
+
+func isCredentialValue(value string) bool {
+	if len(value) < 3 || credentialVariable.MatchString(value) {
+		return false
+	}
+	for _, ignore := range credentialIgnore {
+		if strings.EqualFold(value, ignore) {
+			return false
+		}
+	}
+	return true
+}
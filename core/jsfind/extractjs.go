@@ -4,10 +4,11 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"slack-wails/lib/browser"
 	"slack-wails/lib/gologger"
 	"slack-wails/lib/utils/arrayutil"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/qiwentaidi/clients"
 
@@ -75,34 +76,33 @@ func extractStaticJs(ctx context.Context, url string) []string {
 // 通过chromedp提取动态JS, 用于对静态的查漏补缺
 func extractDynamicsJs(mainCtx context.Context, url string) []string {
 	var dynamicJsLinks []string
-	ctx, cancel := chromedp.NewContext(context.Background())
-	defer cancel()
-
-	// 设置超时
-	ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
+	var mu sync.Mutex
 	linkSet := make(map[string]bool) // 防止重复
 
-	// 监听所有网络响应
-	chromedp.ListenTarget(ctx, func(ev interface{}) {
-		if ev, ok := ev.(*network.EventResponseReceived); ok {
-			url := ev.Response.URL
-			if strings.Contains(url, ".js") {
-				if !linkSet[url] {
-					dynamicJsLinks = append(dynamicJsLinks, url)
-					linkSet[url] = true
+	// 使用共享浏览器池，等待网络空闲后 JS 动态加载完成
+	pool := browser.Shared()
+	err := pool.Do(context.Background(), func(ctx context.Context) error {
+		// 监听所有网络响应
+		chromedp.ListenTarget(ctx, func(ev interface{}) {
+			if ev, ok := ev.(*network.EventResponseReceived); ok {
+				url := ev.Response.URL
+				mu.Lock()
+				defer mu.Unlock()
+				if strings.Contains(url, ".js") {
+					if !linkSet[url] {
+						dynamicJsLinks = append(dynamicJsLinks, url)
+						linkSet[url] = true
+					}
 				}
 			}
-		}
+		})
+		return chromedp.Run(ctx,
+			network.Enable(),   // 启用网络监听
+			pool.Navigate(url), // 替换为目标 URL
+		)
 	})
-
-	// 启动网络监听
-	err := chromedp.Run(ctx,
-		network.Enable(),              // 启用网络监听
-		chromedp.Navigate(url),        // 替换为目标 URL
-		chromedp.Sleep(5*time.Second), // 等待页面加载 & JS 动态加载完成
-	)
+	mu.Lock()
+	defer mu.Unlock()
 	if err != nil {
 		runtime.EventsEmit(mainCtx, "jsfindlog", fmt.Sprintf("[-] chromedp 运行失败无法动态加载JS链接, 错误原因: (%v)", err))
 		return dynamicJsLinks
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slack-wails/lib/assets"
 	"slack-wails/lib/gologger"
 	"slack-wails/lib/utils"
 	"slack-wails/lib/utils/httputil"
@@ -24,6 +25,11 @@ type SourceMap struct {
 // 输出路径：~/slack/sourceMap
 var outputPath = filepath.Join(utils.HomeDir(), "slack", "sourceMap")
 
+func init() {
+	// 还原出的源码通过本地资源服务的 /sourcemap/ 路径查看
+	assets.Shared().Register("sourcemap", outputPath)
+}
+
 func RestoreWebpack(ctx context.Context, sourceMapURL string) (string, error) {
 	// 正确逻辑：必须以 .js.map 结尾
 	if !strings.HasSuffix(sourceMapURL, ".js.map") {
@@ -7,11 +7,10 @@ import (
 	"fmt"
 	"net"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func ActiveMQScan(ctx, ctrlCtx context.Context, taskId, address string, usernames, passwords []string) {
@@ -24,7 +23,7 @@ func ActiveMQScan(ctx, ctrlCtx context.Context, taskId, address string, username
 			pass = strings.Replace(pass, "{user}", user, -1)
 			flag, err := ActiveMQConn(address, user, pass)
 			if flag && err == nil {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 					TaskId:   taskId,
 					ID:       "activemq weak password",
 					Name:     "activemq weak password",
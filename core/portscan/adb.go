@@ -4,11 +4,10 @@ import (
 	"context"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func AdbScan(ctx, ctrlCtx context.Context, taskId, address string, usernames, passwords []string) {
@@ -95,7 +94,7 @@ func AdbScan(ctx, ctrlCtx context.Context, taskId, address string, usernames, pa
 	}
 
 	if result != "" {
-		runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+		resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 			TaskId:   taskId,
 			ID:       "adb unauthorized",
 			Name:     "adb unauthorized",
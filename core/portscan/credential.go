@@ -0,0 +1,92 @@
+package portscan
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
+	"slack-wails/lib/structs"
+	"slack-wails/lib/utils/credutil"
+	"slack-wails/lib/utils/netutil"
+	"strconv"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 会校验账号密码的爆破模块，未授权类模块（memcached、jdwp、adb、java-rmi）不参与凭据复用
+var reuseProtocols = map[string]bool{
+	"ftp":        true,
+	"ssh":        true,
+	"telnet":     true,
+	"smb":        true,
+	"oracle":     true,
+	"mssql":      true,
+	"mysql":      true,
+	"rdp":        true,
+	"postgresql": true,
+	"mongodb":    true,
+	"ldap":       true,
+	"mqtt":       true,
+	"socks5":     true,
+	"vnc":        true,
+	"redis":      true,
+	"activemq":   true,
+	"rsync":      true,
+	"kafka":      true,
+	"http":       true,
+	"https":      true,
+}
+
+// CredentialReuse 将已获取的凭据逐一重放到其他已发现的服务上，targets 为 scheme://host:port 形式
+func CredentialReuse(ctx, ctrlCtx context.Context, taskId string, targets []string, creds []structs.Credential) {
+	defer runtime.EventsEmit(ctx, "credentialReuseDone")
+	// 复用命中的凭据单独标记来源，与爆破结果区分
+	reuseCtx := resultstore.WithSource(ctx, credutil.SourceReuse)
+	for _, target := range targets {
+		if ctrlCtx.Err() != nil {
+			return
+		}
+		scheme, host, port := netutil.SplitTarget(target)
+		scanFunc, ok := crackScanners[scheme]
+		if !ok || !reuseProtocols[scheme] {
+			continue
+		}
+		address := net.JoinHostPort(host, strconv.Itoa(port))
+		if scheme == "http" || scheme == "https" {
+			address = target
+		}
+		var (
+			tried     = make(map[string]bool)
+			passwords []string
+		)
+		for _, cred := range creds {
+			if ctrlCtx.Err() != nil {
+				return
+			}
+			// 跳过凭据来源服务本身
+			if cred.Host == host && cred.Port == port {
+				continue
+			}
+			// 仅校验密码的协议合并为一次爆破，避免重复执行未授权检测
+			if credutil.PasswordOnly(scheme) {
+				if !tried[cred.Password] {
+					tried[cred.Password] = true
+					passwords = append(passwords, cred.Password)
+				}
+				continue
+			}
+			key := cred.Username + "\x00" + cred.Password
+			if cred.Username == "" || tried[key] {
+				continue
+			}
+			tried[key] = true
+			gologger.Info(ctx, fmt.Sprintf("[reuse] %s %s:%s from %s:%d", target, cred.Username, cred.Password, cred.Host, cred.Port))
+			scanFunc(reuseCtx, ctrlCtx, taskId, address, []string{cred.Username}, []string{cred.Password})
+		}
+		if len(passwords) > 0 {
+			gologger.Info(ctx, fmt.Sprintf("[reuse] %s %d passwords", target, len(passwords)))
+			scanFunc(reuseCtx, ctrlCtx, taskId, address, []string{""}, passwords)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package portscan
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// 基于 ncacn_ip_tcp 与 SMB 命名管道的最小 DCE/RPC 客户端，仅支持无认证的 bind 与 request
+// 参考 C706 第 12 章 Connection-oriented PDU
+
+const (
+	rpcPtypeRequest  = 0x00
+	rpcPtypeResponse = 0x02
+	rpcPtypeFault    = 0x03
+	rpcPtypeBind     = 0x0b
+	rpcPtypeBindAck  = 0x0c
+
+	rpcFlagFirstFrag = 0x01
+	rpcFlagLastFrag  = 0x02
+)
+
+var (
+	// NDR 传输语法 8a885d04-1ceb-11c9-9fe8-08002b104860 v2
+	rpcNDRSyntax = rpcSyntax("8a885d04-1ceb-11c9-9fe8-08002b104860", 2, 0)
+	// Endpoint Mapper e1af8308-5d1f-11c9-91a4-08002b14a0fa v3
+	rpcEPMSyntax = rpcSyntax("e1af8308-5d1f-11c9-91a4-08002b14a0fa", 3, 0)
+)
+
+type rpcConn struct {
+	conn   io.ReadWriteCloser
+	reader io.Reader
+	callID uint32
+}
+
+// rpcUUID 将字符串形式的 UUID 转为 NDR 编码，前三段为小端序
+func rpcUUID(s string) []byte {
+	raw, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil || len(raw) != 16 {
+		return make([]byte, 16)
+	}
+	uuid := make([]byte, 16)
+	binary.LittleEndian.PutUint32(uuid[0:4], binary.BigEndian.Uint32(raw[0:4]))
+	binary.LittleEndian.PutUint16(uuid[4:6], binary.BigEndian.Uint16(raw[4:6]))
+	binary.LittleEndian.PutUint16(uuid[6:8], binary.BigEndian.Uint16(raw[6:8]))
+	copy(uuid[8:], raw[8:])
+	return uuid
+}
+
+// rpcSyntax 返回 p_syntax_id_t，即 UUID + 主次版本号
+func rpcSyntax(uuid string, major, minor uint16) []byte {
+	syntax := make([]byte, 20)
+	copy(syntax, rpcUUID(uuid))
+	binary.LittleEndian.PutUint16(syntax[16:18], major)
+	binary.LittleEndian.PutUint16(syntax[18:20], minor)
+	return syntax
+}
+
+func rpcHeader(ptype byte, fragLength int, callID uint32) []byte {
+	header := []byte{0x05, 0x00, ptype, rpcFlagFirstFrag | rpcFlagLastFrag, 0x10, 0x00, 0x00, 0x00}
+	header = binary.LittleEndian.AppendUint16(header, uint16(fragLength))
+	header = binary.LittleEndian.AppendUint16(header, 0) // auth_length
+	return binary.LittleEndian.AppendUint32(header, callID)
+}
+
+// dialRPC 连接目标 RPC 端口并绑定到指定接口
+func dialRPC(host string, abstractSyntax []byte, timeout time.Duration) (*rpcConn, error) {
+	conn, err := WrapperTcpWithTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	rc := &rpcConn{conn: conn, reader: conn, callID: 1}
+	if err = rc.bind(abstractSyntax); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return rc, nil
+}
+
+func (rc *rpcConn) Close() error {
+	return rc.conn.Close()
+}
+
+func (rc *rpcConn) bind(abstractSyntax []byte) error {
+	body := []byte{0xb8, 0x10, 0xb8, 0x10, 0x00, 0x00, 0x00, 0x00} // max_xmit_frag, max_recv_frag, assoc_group_id
+	body = append(body, 0x01, 0x00, 0x00, 0x00)                    // n_context_elem
+	body = append(body, 0x00, 0x00, 0x01, 0x00)                    // p_cont_id, n_transfer_syn
+	body = append(body, abstractSyntax...)
+	body = append(body, rpcNDRSyntax...)
+	if _, err := rc.conn.Write(append(rpcHeader(rpcPtypeBind, 16+len(body), rc.callID), body...)); err != nil {
+		return err
+	}
+	ptype, _, body, err := rc.readPDU()
+	if err != nil {
+		return err
+	}
+	if ptype != rpcPtypeBindAck {
+		return fmt.Errorf("dcerpc: bind rejected, ptype %d", ptype)
+	}
+	// bind_ack 中 sec_addr 长度可变，需按 4 字节对齐后读取 p_result_list
+	if len(body) < 10 {
+		return errors.New("dcerpc: invalid bind_ack")
+	}
+	secAddrLen := int(binary.LittleEndian.Uint16(body[8:10]))
+	offset := 10 + secAddrLen
+	offset += (4 - (16+offset)%4) % 4
+	if offset+6 > len(body) {
+		return errors.New("dcerpc: invalid bind_ack")
+	}
+	if result := binary.LittleEndian.Uint16(body[offset+4 : offset+6]); result != 0 {
+		return fmt.Errorf("dcerpc: presentation context rejected, result %d", result)
+	}
+	return nil
+}
+
+// Call 发起一次 RPC 调用并返回拼接后的响应 stub 数据
+func (rc *rpcConn) Call(opnum uint16, stub []byte) ([]byte, error) {
+	rc.callID++
+	body := binary.LittleEndian.AppendUint32(nil, uint32(len(stub))) // alloc_hint
+	body = binary.LittleEndian.AppendUint16(body, 0)                 // p_cont_id
+	body = binary.LittleEndian.AppendUint16(body, opnum)
+	body = append(body, stub...)
+	if _, err := rc.conn.Write(append(rpcHeader(rpcPtypeRequest, 16+len(body), rc.callID), body...)); err != nil {
+		return nil, err
+	}
+	var result []byte
+	for {
+		ptype, flags, body, err := rc.readPDU()
+		if err != nil {
+			return nil, err
+		}
+		switch ptype {
+		case rpcPtypeResponse:
+		case rpcPtypeFault:
+			if len(body) >= 12 {
+				return nil, fmt.Errorf("dcerpc: fault status 0x%08x", binary.LittleEndian.Uint32(body[8:12]))
+			}
+			return nil, errors.New("dcerpc: fault")
+		default:
+			return nil, fmt.Errorf("dcerpc: unexpected ptype %d", ptype)
+		}
+		if len(body) < 8 {
+			return nil, errors.New("dcerpc: invalid response")
+		}
+		result = append(result, body[8:]...)
+		if flags&rpcFlagLastFrag != 0 {
+			return result, nil
+		}
+	}
+}
+
+// readPDU 读取一个完整的 PDU，返回类型、标志位与头部之后的内容
+func (rc *rpcConn) readPDU() (byte, byte, []byte, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(rc.reader, header); err != nil {
+		return 0, 0, nil, err
+	}
+	if header[0] != 0x05 {
+		return 0, 0, nil, errors.New("dcerpc: invalid pdu version")
+	}
+	fragLength := int(binary.LittleEndian.Uint16(header[8:10]))
+	authLength := int(binary.LittleEndian.Uint16(header[10:12]))
+	if fragLength < 16 {
+		return 0, 0, nil, errors.New("dcerpc: invalid fragment length")
+	}
+	body := make([]byte, fragLength-16)
+	if _, err := io.ReadFull(rc.reader, body); err != nil {
+		return 0, 0, nil, err
+	}
+	if authLength > 0 && authLength+8 <= len(body) {
+		body = body[:len(body)-authLength-8]
+	}
+	return header[2], header[3], body, nil
+}
+
+// epmLookupAll 通过 ept_lookup 遍历端点映射器中注册的全部条目，返回原始 stub 数据
+func epmLookupAll(host string, timeout time.Duration) ([]byte, error) {
+	rc, err := dialRPC(host, rpcEPMSyntax, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var (
+		all    []byte
+		handle = make([]byte, 20)
+	)
+	// 防止异常服务端返回相同句柄导致死循环
+	for i := 0; i < 20; i++ {
+		stub := binary.LittleEndian.AppendUint32(nil, 0) // inquiry_type: RPC_C_EP_ALL_ELTS
+		stub = binary.LittleEndian.AppendUint32(stub, 0) // object: NULL
+		stub = binary.LittleEndian.AppendUint32(stub, 0) // interface_id: NULL
+		stub = binary.LittleEndian.AppendUint32(stub, 1) // vers_option: RPC_C_VERS_ALL
+		stub = append(stub, handle...)
+		stub = binary.LittleEndian.AppendUint32(stub, 500) // max_ents
+		resp, err := rc.Call(2, stub)
+		if err != nil {
+			if len(all) > 0 {
+				return all, nil
+			}
+			return nil, err
+		}
+		if len(resp) < 28 {
+			break
+		}
+		all = append(all, resp...)
+		copy(handle, resp[0:20])
+		status := binary.LittleEndian.Uint32(resp[len(resp)-4:])
+		if status != 0 || bytes.Equal(handle, make([]byte, 20)) {
+			break
+		}
+	}
+	return all, nil
+}
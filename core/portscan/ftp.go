@@ -4,18 +4,18 @@ import (
 	"context"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
 
 	"github.com/jlaffaye/ftp"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func FtpScan(ctx, ctrlCtx context.Context, taskId, address string, usernames, passwords []string) {
 	flag, directories, err := FtpConn(address, "anonymous", "")
 	if flag && err == nil {
-		runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+		resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 			TaskId:   taskId,
 			ID:       "ftp unauthorized",
 			Name:     "ftp unauthorized",
@@ -37,7 +37,8 @@ func FtpScan(ctx, ctrlCtx context.Context, taskId, address string, usernames, pa
 			pass = strings.Replace(pass, "{user}", user, -1)
 			flag, directories, err := FtpConn(address, user, pass)
 			if flag && err == nil {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
+					TaskId:   taskId,
 					ID:       "ftp weak password",
 					Name:     "ftp weak password",
 					URL:      address,
@@ -0,0 +1,473 @@
+package portscan
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
+	"slack-wails/lib/structs"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-ntlmssp"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// HTTP Basic/Digest/NTLM 认证与 HTML 表单登录暴破
+
+const maxHttpLoginBodySize = 1024 * 200
+
+var (
+	// 用户字典为空时使用的默认用户名
+	defaultHttpUsernames = []string{"admin", "administrator", "root", "test", "user"}
+
+	httpUserFieldReg    = regexp.MustCompile(`(?i)user|login|account|email|name|uid|phone|mobile`)
+	httpCSRFFieldReg    = regexp.MustCompile(`(?i)csrf|xsrf|token|authenticity|__requestverification|__viewstate|__eventvalidation|nonce`)
+	httpCaptchaReg      = regexp.MustCompile(`(?i)captcha|verifycode|verify_code|validatecode|validcode|checkcode|vcode|yzm|kaptcha|g-recaptcha|h-captcha|验证码`)
+	httpPasswordInput   = regexp.MustCompile(`(?i)type\s*=\s*["']?password`)
+	httpDigestParamsReg = regexp.MustCompile(`(\w+)=("([^"]*)"|[^,\s]*)`)
+)
+
+type httpLoginForm struct {
+	Action    string
+	Method    string
+	UserField string
+	PassField string
+	Fields    url.Values
+	Headers   map[string]string
+	HasCSRF   bool // CSRF 令牌每次请求都可能变化，需要在每次尝试前重新获取表单
+}
+
+// httpLoginResponse 用于对比失败基线与每次尝试的响应差异
+type httpLoginResponse struct {
+	Status      int
+	Location    string
+	Length      int
+	HasPassword bool
+	Raw         string
+}
+
+func HttpLoginScan(ctx, ctrlCtx context.Context, taskId, target string, usernames, passwords []string) {
+	if len(usernames) == 0 {
+		usernames = defaultHttpUsernames
+	}
+	client := newHttpLoginClient(nil)
+	resp, body, err := httpLoginFetch(client, target)
+	if err != nil {
+		gologger.Debug(ctx, fmt.Sprintf("[http] %s %v", target, err))
+		return
+	}
+
+	var try func(user, pass string) (bool, string, error)
+	if resp.StatusCode == http.StatusUnauthorized {
+		try = httpAuthTrier(resp.Request.URL.String(), resp.Header.Values("WWW-Authenticate"))
+		if try == nil {
+			gologger.Info(ctx, fmt.Sprintf("[http] %s unsupported authentication: %s", target, strings.Join(resp.Header.Values("WWW-Authenticate"), ", ")))
+			return
+		}
+	} else {
+		if httpCaptchaReg.MatchString(body) && httpPasswordInput.MatchString(body) {
+			gologger.Warning(ctx, fmt.Sprintf("[http] %s login form contains captcha, crack aborted", target))
+			return
+		}
+		form, err := parseHttpLoginForm(resp.Request.URL, body)
+		if err != nil {
+			gologger.Info(ctx, fmt.Sprintf("[http] %s %v", target, err))
+			return
+		}
+		try, err = httpFormTrier(client, resp.Request.URL.String(), form)
+		if err != nil {
+			gologger.Info(ctx, fmt.Sprintf("[http] %s %v", target, err))
+			return
+		}
+	}
+
+	for _, user := range usernames {
+		for _, pass := range passwords {
+			if ctrlCtx.Err() != nil {
+				gologger.Warning(ctx, "[http] User exits crack scanning")
+				return
+			}
+			pass = strings.Replace(pass, "{user}", user, -1)
+			flag, raw, err := try(user, pass)
+			if err != nil {
+				gologger.Warning(ctx, fmt.Sprintf("[http] %s %v, crack aborted", target, err))
+				return
+			}
+			if flag {
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
+					TaskId:   taskId,
+					ID:       "http weak password",
+					Name:     "http weak password",
+					URL:      target,
+					Type:     "HTTP",
+					Severity: "HIGH",
+					Extract:  user + "/" + pass,
+					Response: raw,
+				})
+				return
+			}
+			gologger.Info(ctx, fmt.Sprintf("%s %s:%s is login failed", target, user, pass))
+		}
+	}
+}
+
+func newHttpLoginClient(transport http.RoundTripper) *http.Client {
+	if transport == nil {
+		transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+	jar, _ := cookiejar.New(nil)
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+		Jar:       jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// httpLoginFetch 获取页面，最多跟随 5 次跳转，Cookie 在跳转过程中保留
+func httpLoginFetch(client *http.Client, target string) (*http.Response, string, error) {
+	for i := 0; i < 5; i++ {
+		resp, err := client.Get(target)
+		if err != nil {
+			return nil, "", err
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxHttpLoginBodySize))
+		resp.Body.Close()
+		location, err := resp.Location()
+		if err != nil || resp.StatusCode < 300 || resp.StatusCode >= 400 {
+			return resp, string(body), nil
+		}
+		target = location.String()
+	}
+	return nil, "", errors.New("too many redirects")
+}
+
+// httpAuthTrier 根据 WWW-Authenticate 选择认证方式，优先级 Basic > Digest > NTLM
+func httpAuthTrier(target string, challenges []string) func(user, pass string) (bool, string, error) {
+	schemes := make(map[string]string)
+	for _, challenge := range challenges {
+		scheme, params, _ := strings.Cut(challenge, " ")
+		schemes[strings.ToLower(scheme)] = params
+	}
+	unauthorized := func(resp *http.Response) (bool, string, error) {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return false, "", errors.New("rate limited")
+		}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return false, "", nil
+		}
+		return true, fmt.Sprintf("%s %s", resp.Proto, resp.Status), nil
+	}
+	if _, ok := schemes["basic"]; ok {
+		client := newHttpLoginClient(nil)
+		return func(user, pass string) (bool, string, error) {
+			req, err := http.NewRequest(http.MethodGet, target, nil)
+			if err != nil {
+				return false, "", err
+			}
+			req.SetBasicAuth(user, pass)
+			resp, err := client.Do(req)
+			if err != nil {
+				return false, "", err
+			}
+			return unauthorized(resp)
+		}
+	}
+	if _, ok := schemes["digest"]; ok {
+		client := newHttpLoginClient(nil)
+		return func(user, pass string) (bool, string, error) {
+			// nonce 可能一次性有效，每次尝试都重新获取质询
+			resp, err := client.Get(target)
+			if err != nil {
+				return false, "", err
+			}
+			resp.Body.Close()
+			var challenge string
+			for _, value := range resp.Header.Values("WWW-Authenticate") {
+				if strings.HasPrefix(strings.ToLower(value), "digest ") {
+					challenge = value[7:]
+				}
+			}
+			if challenge == "" {
+				return false, "", errors.New("digest challenge not found")
+			}
+			req, err := http.NewRequest(http.MethodGet, target, nil)
+			if err != nil {
+				return false, "", err
+			}
+			req.Header.Set("Authorization", digestAuthorization(challenge, req.Method, req.URL.RequestURI(), user, pass))
+			resp, err = client.Do(req)
+			if err != nil {
+				return false, "", err
+			}
+			return unauthorized(resp)
+		}
+	}
+	_, ntlm := schemes["ntlm"]
+	_, negotiate := schemes["negotiate"]
+	if ntlm || negotiate {
+		return func(user, pass string) (bool, string, error) {
+			// NTLM 握手绑定在同一连接上，每次尝试使用新的连接
+			client := newHttpLoginClient(ntlmssp.Negotiator{RoundTripper: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}})
+			req, err := http.NewRequest(http.MethodGet, target, nil)
+			if err != nil {
+				return false, "", err
+			}
+			req.SetBasicAuth(user, pass)
+			resp, err := client.Do(req)
+			if err != nil {
+				return false, "", err
+			}
+			return unauthorized(resp)
+		}
+	}
+	return nil
+}
+
+// digestAuthorization 按 RFC 2617 计算 Digest 认证头，支持 MD5/MD5-sess 与 qop=auth
+func digestAuthorization(challenge, method, uri, user, pass string) string {
+	params := make(map[string]string)
+	for _, m := range httpDigestParamsReg.FindAllStringSubmatch(challenge, -1) {
+		value := m[2]
+		if strings.HasPrefix(value, `"`) {
+			value = m[3]
+		}
+		params[strings.ToLower(m[1])] = value
+	}
+	md5hex := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	nonce := make([]byte, 8)
+	rand.Read(nonce)
+	cnonce := hex.EncodeToString(nonce)
+	nc := "00000001"
+	ha1 := md5hex(user + ":" + params["realm"] + ":" + pass)
+	if strings.EqualFold(params["algorithm"], "MD5-sess") {
+		ha1 = md5hex(ha1 + ":" + params["nonce"] + ":" + cnonce)
+	}
+	ha2 := md5hex(method + ":" + uri)
+	header := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s"`, user, params["realm"], params["nonce"], uri)
+	if qop := params["qop"]; qop != "" {
+		header += fmt.Sprintf(`, qop=auth, nc=%s, cnonce="%s", response="%s"`, nc, cnonce, md5hex(ha1+":"+params["nonce"]+":"+nc+":"+cnonce+":auth:"+ha2))
+	} else {
+		header += fmt.Sprintf(`, response="%s"`, md5hex(ha1+":"+params["nonce"]+":"+ha2))
+	}
+	if params["algorithm"] != "" {
+		header += ", algorithm=" + params["algorithm"]
+	}
+	if params["opaque"] != "" {
+		header += fmt.Sprintf(`, opaque="%s"`, params["opaque"])
+	}
+	return header
+}
+
+// parseHttpLoginForm 查找包含密码框的表单，识别用户名、密码字段与隐藏字段
+func parseHttpLoginForm(base *url.URL, body string) (*httpLoginForm, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	var form *httpLoginForm
+	doc.Find("form").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		if s.Find(`input[type="password" i]`).Length() == 0 {
+			return true
+		}
+		form = &httpLoginForm{
+			Method:  strings.ToUpper(s.AttrOr("method", http.MethodGet)),
+			Fields:  url.Values{},
+			Headers: make(map[string]string),
+		}
+		action, err := base.Parse(s.AttrOr("action", ""))
+		if err != nil {
+			action = base
+		}
+		form.Action = action.String()
+		var textFields []string
+		s.Find("input").Each(func(i int, input *goquery.Selection) {
+			name, ok := input.Attr("name")
+			if !ok || name == "" {
+				return
+			}
+			value := input.AttrOr("value", "")
+			switch strings.ToLower(input.AttrOr("type", "text")) {
+			case "password":
+				if form.PassField == "" {
+					form.PassField = name
+				}
+			case "hidden":
+				form.Fields.Set(name, value)
+				if httpCSRFFieldReg.MatchString(name) {
+					form.HasCSRF = true
+				}
+			case "checkbox", "radio":
+				if _, checked := input.Attr("checked"); checked {
+					form.Fields.Set(name, value)
+				}
+			case "submit":
+				form.Fields.Set(name, value)
+			case "text", "email", "tel", "":
+				textFields = append(textFields, name)
+			}
+		})
+		for _, name := range textFields {
+			if httpUserFieldReg.MatchString(name) {
+				form.UserField = name
+				break
+			}
+		}
+		if form.UserField == "" && len(textFields) > 0 {
+			form.UserField = textFields[0]
+		}
+		return false
+	})
+	if form == nil || form.PassField == "" {
+		return nil, errors.New("login form not found")
+	}
+	if form.UserField == "" {
+		return nil, errors.New("username field not found")
+	}
+	if token, ok := doc.Find(`meta[name="csrf-token"]`).Attr("content"); ok {
+		form.Headers["X-CSRF-Token"] = token
+		form.HasCSRF = true
+	}
+	return form, nil
+}
+
+// httpFormTrier 先使用随机账号建立失败基线，后续根据响应差异判断登录是否成功
+func httpFormTrier(client *http.Client, page string, form *httpLoginForm) (func(user, pass string) (bool, string, error), error) {
+	submit := func(user, pass string) (*httpLoginResponse, error) {
+		current := form
+		if form.HasCSRF {
+			resp, body, err := httpLoginFetch(client, page)
+			if err != nil {
+				return nil, err
+			}
+			if current, err = parseHttpLoginForm(resp.Request.URL, body); err != nil {
+				return nil, err
+			}
+		}
+		values := url.Values{}
+		for k, v := range current.Fields {
+			values[k] = v
+		}
+		values.Set(current.UserField, user)
+		values.Set(current.PassField, pass)
+		var req *http.Request
+		var err error
+		if current.Method == http.MethodPost {
+			req, err = http.NewRequest(http.MethodPost, current.Action, strings.NewReader(values.Encode()))
+			if err == nil {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+		} else {
+			u, perr := url.Parse(current.Action)
+			if perr != nil {
+				return nil, perr
+			}
+			u.RawQuery = values.Encode()
+			req, err = http.NewRequest(http.MethodGet, u.String(), nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Referer", page)
+		for k, v := range current.Headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxHttpLoginBodySize))
+		// 去除回显的用户名，避免影响长度比较
+		text := strings.ReplaceAll(string(body), user, "")
+		result := &httpLoginResponse{
+			Status:      resp.StatusCode,
+			Location:    resp.Header.Get("Location"),
+			Length:      len(text),
+			HasPassword: httpPasswordInput.MatchString(text),
+			Raw:         fmt.Sprintf("%s %s\nLocation: %s\n\n%s", resp.Proto, resp.Status, resp.Header.Get("Location"), limitString(string(body), 2048)),
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, errors.New("rate limited")
+		}
+		// 部分系统在多次失败后才出现验证码
+		if result.HasPassword && httpCaptchaReg.MatchString(string(body)) {
+			return nil, errors.New("captcha required after failed login")
+		}
+		return result, nil
+	}
+
+	baseline, err := submit(randomHttpString(10), randomHttpString(12))
+	if err != nil {
+		return nil, err
+	}
+	return func(user, pass string) (bool, string, error) {
+		result, err := submit(user, pass)
+		if err != nil {
+			return false, "", err
+		}
+		return isHttpLoginSuccess(baseline, result), result.Raw, nil
+	}, nil
+}
+
+// isHttpLoginSuccess 与失败基线对比：状态码、跳转地址变化或登录框消失均视为成功
+func isHttpLoginSuccess(baseline, result *httpLoginResponse) bool {
+	switch {
+	case result.Status >= 500, result.Status == http.StatusUnauthorized, result.Status == http.StatusForbidden:
+		return false
+	}
+	if result.Status != baseline.Status {
+		return true
+	}
+	if result.Status >= 300 && result.Status < 400 {
+		return stripQuery(result.Location) != stripQuery(baseline.Location)
+	}
+	if baseline.HasPassword && !result.HasPassword {
+		// 仍需有明显的长度差异，防止错误页面误报
+		diff := result.Length - baseline.Length
+		if diff < 0 {
+			diff = -diff
+		}
+		return diff*10 > baseline.Length
+	}
+	return false
+}
+
+func stripQuery(location string) string {
+	location, _, _ = strings.Cut(location, "?")
+	return strings.ToLower(location)
+}
+
+func limitString(s string, size int) string {
+	if len(s) > size {
+		return s[:size]
+	}
+	return s
+}
+
+func randomHttpString(n int) string {
+	b := make([]byte, n/2+1)
+	rand.Read(b)
+	return hex.EncodeToString(b)[:n]
+}
@@ -0,0 +1,604 @@
+package portscan
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slack-wails/lib/structs"
+	"strings"
+	"time"
+)
+
+// 工控协议识别，仅发送读取设备标识类的只读请求，不写入任何寄存器或变量
+
+type icsProbe struct {
+	protocol string
+	ports    []int
+	services []string // gonmap 可能识别出的服务名称
+	probe    func(host string, timeout time.Duration) (*structs.ICSInfo, error)
+}
+
+var icsProbes = []icsProbe{
+	{"modbus", []int{502}, []string{"modbus", "mbap"}, modbusDeviceIdentification},
+	{"s7", []int{102}, []string{"iso-tsap", "s7", "s7comm"}, s7Identification},
+	{"enip", []int{44818}, []string{"enip", "ethernet-ip", "ethernetip-2", "ethernet-ip-2"}, enipListIdentity},
+	{"dnp3", []int{20000}, []string{"dnp", "dnp3"}, dnp3LinkStatus},
+	{"iec-104", []int{2404}, []string{"iec-104", "iec104"}, iec104TestFrame},
+	{"fins", []int{9600}, []string{"fins", "omron-fins"}, finsControllerData},
+	{"fox", []int{1911, 4911}, []string{"fox", "niagara-fox"}, foxHello},
+}
+
+// BACnet/IP 仅支持 UDP，TCP 端口扫描无法发现，需要单独探测
+const bacnetPort = 47808
+
+// ICSInfoScan 根据端口或服务名称选择工控协议探测
+func ICSInfoScan(scheme, ip string, port int, timeout time.Duration) (*structs.ICSInfo, error) {
+	host := net.JoinHostPort(ip, fmt.Sprint(port))
+	scheme = strings.ToLower(scheme)
+	for _, p := range icsProbes {
+		if containsInt(p.ports, port) || containsString(p.services, scheme) {
+			info, err := p.probe(host, timeout)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", p.protocol, err)
+			}
+			info.Protocol = p.protocol
+			return info, nil
+		}
+	}
+	if port == bacnetPort {
+		return BACnetInfoScan(ip, port, timeout)
+	}
+	return nil, errors.New("ics info: unsupported protocol " + scheme)
+}
+
+// ICSFingerprint 生成用于结果展示的指纹，如 Siemens 6ES7 315-2EH14-0AB0 v3.2.6
+func ICSFingerprint(info *structs.ICSInfo) string {
+	var fields []string
+	for _, f := range []string{info.Vendor, info.Model} {
+		if f != "" {
+			fields = append(fields, f)
+		}
+	}
+	if info.Firmware != "" {
+		fields = append(fields, "v"+strings.TrimPrefix(info.Firmware, "v"))
+	}
+	if len(fields) == 0 {
+		return info.Protocol
+	}
+	return strings.Join(fields, " ")
+}
+
+func icsExchange(conn net.Conn, timeout time.Duration, request []byte) ([]byte, error) {
+	conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write(request); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 2048)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// Modbus/TCP 功能码 0x2B/0x0E 读取设备标识（基本类别：厂商、产品代码、版本）
+func modbusDeviceIdentification(host string, timeout time.Duration) (*structs.ICSInfo, error) {
+	conn, err := WrapperTcpWithTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var lastErr error
+	// 网关通常需要指定单元标识符，依次尝试常见取值
+	for i, unit := range []byte{0x00, 0x01, 0xff} {
+		request := []byte{0x00, byte(i + 1), 0x00, 0x00, 0x00, 0x05, unit, 0x2b, 0x0e, 0x01, 0x00}
+		response, err := icsExchange(conn, timeout, request)
+		if err != nil {
+			return nil, err
+		}
+		info, err := parseModbusDeviceIdentification(response)
+		if err == nil {
+			info.Extra = map[string]string{"unit": fmt.Sprint(unit)}
+			return info, nil
+		}
+		lastErr = err
+	}
+	// 返回异常码说明协议为 Modbus，但不支持读取设备标识
+	if errors.Is(lastErr, errModbusException) {
+		return &structs.ICSInfo{}, nil
+	}
+	return nil, lastErr
+}
+
+var errModbusException = errors.New("modbus exception response")
+
+func parseModbusDeviceIdentification(response []byte) (*structs.ICSInfo, error) {
+	if len(response) < 9 || response[2] != 0x00 || response[3] != 0x00 {
+		return nil, errors.New("invalid mbap header")
+	}
+	if response[7] == 0xab {
+		return nil, errModbusException
+	}
+	if len(response) < 15 || response[7] != 0x2b || response[8] != 0x0e {
+		return nil, errors.New("invalid device identification response")
+	}
+	info := &structs.ICSInfo{}
+	count, pos := int(response[13]), 14
+	for i := 0; i < count && pos+2 <= len(response); i++ {
+		id, length := response[pos], int(response[pos+1])
+		if pos+2+length > len(response) {
+			break
+		}
+		value := strings.TrimSpace(string(response[pos+2 : pos+2+length]))
+		switch id {
+		case 0x00:
+			info.Vendor = value
+		case 0x01:
+			info.Model = value
+		case 0x02:
+			info.Firmware = value
+		}
+		pos += 2 + length
+	}
+	return info, nil
+}
+
+// S7comm 建立 COTP 连接后读取 SZL 0x0011（模块标识）与 0x001C（组件标识）
+var (
+	s7CotpRequests = [][]byte{
+		mustHex("0300001611e00000001400c1020100c2020102c0010a"), // rack 0 slot 2，S7-300/400
+		mustHex("0300001611e00000000500c1020100c2020200c0010a"), // S7-1200/1500
+	}
+	s7SetupCommunication = mustHex("0300001902f08032010000000000080000f0000001000101e0")
+	s7ReadSZL0011        = mustHex("0300002102f080320700000000000800080001120411440100ff09000400110001")
+	s7ReadSZL001C        = mustHex("0300002102f080320700000000000800080001120411440100ff090004001c0001")
+)
+
+func s7Identification(host string, timeout time.Duration) (*structs.ICSInfo, error) {
+	var lastErr error
+	for _, cotp := range s7CotpRequests {
+		info, err := s7Session(host, timeout, cotp)
+		if err == nil {
+			return info, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func s7Session(host string, timeout time.Duration, cotp []byte) (*structs.ICSInfo, error) {
+	conn, err := WrapperTcpWithTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	response, err := icsExchange(conn, timeout, cotp)
+	if err != nil {
+		return nil, err
+	}
+	if len(response) < 6 || response[0] != 0x03 || response[5] != 0xd0 {
+		return nil, errors.New("cotp connection refused")
+	}
+	response, err = icsExchange(conn, timeout, s7SetupCommunication)
+	if err != nil {
+		return nil, err
+	}
+	if len(response) < 8 || response[7] != 0x32 {
+		return nil, errors.New("invalid s7 setup response")
+	}
+	info := &structs.ICSInfo{Vendor: "Siemens", Extra: map[string]string{}}
+	if response, err = icsExchange(conn, timeout, s7ReadSZL0011); err == nil {
+		parseS7ModuleIdentification(response, info)
+	}
+	if response, err = icsExchange(conn, timeout, s7ReadSZL001C); err == nil {
+		parseS7ComponentIdentification(response, info)
+	}
+	return info, nil
+}
+
+func parseS7ModuleIdentification(response []byte, info *structs.ICSInfo) {
+	if len(response) < 8 || response[7] != 0x32 {
+		return
+	}
+	info.Model = cString(response, 43, 20)
+	if hardware := cString(response, 71, 20); hardware != "" {
+		info.Extra["hardware"] = hardware
+	}
+	if len(response) >= 125 {
+		info.Firmware = fmt.Sprintf("%d.%d.%d", response[122], response[123], response[124])
+	}
+}
+
+func parseS7ComponentIdentification(response []byte, info *structs.ICSInfo) {
+	if len(response) < 31 || response[7] != 0x32 {
+		return
+	}
+	offset := 0
+	if response[30] != 0x1c {
+		offset = 4
+	}
+	fields := []struct {
+		key    string
+		offset int
+	}{
+		{"system name", 39}, {"module type", 73}, {"plant identification", 107}, {"copyright", 141}, {"serial", 175},
+	}
+	for _, f := range fields {
+		value := cString(response, f.offset+offset, 32)
+		if value == "" {
+			continue
+		}
+		if f.key == "serial" {
+			info.Serial = value
+		} else {
+			info.Extra[f.key] = value
+		}
+	}
+}
+
+// EtherNet/IP List Identity，封装命令 0x0063
+var (
+	enipListIdentityRequest = mustHex("630000000000000000000000000000000000000000000000")
+	enipVendors             = map[uint16]string{1: "Rockwell Automation/Allen-Bradley", 47: "Omron"}
+	enipDeviceTypes         = map[uint16]string{
+		0x00: "Generic Device", 0x07: "General Purpose Discrete I/O", 0x0c: "Communications Adapter",
+		0x0e: "Programmable Logic Controller", 0x18: "Human-Machine Interface", 0x2b: "Generic Device (keyable)",
+	}
+)
+
+func enipListIdentity(host string, timeout time.Duration) (*structs.ICSInfo, error) {
+	conn, err := WrapperTcpWithTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	response, err := icsExchange(conn, timeout, enipListIdentityRequest)
+	if err != nil {
+		return nil, err
+	}
+	return parseEnipListIdentity(response)
+}
+
+func parseEnipListIdentity(response []byte) (*structs.ICSInfo, error) {
+	if len(response) < 63 || binary.LittleEndian.Uint16(response[0:2]) != 0x0063 {
+		return nil, errors.New("invalid list identity response")
+	}
+	if binary.LittleEndian.Uint16(response[26:28]) != 0x000c {
+		return nil, errors.New("missing identity item")
+	}
+	vendorId := binary.LittleEndian.Uint16(response[48:50])
+	deviceType := binary.LittleEndian.Uint16(response[50:52])
+	info := &structs.ICSInfo{
+		Vendor:   enipVendors[vendorId],
+		Firmware: fmt.Sprintf("%d.%d", response[54], response[55]),
+		Serial:   fmt.Sprintf("0x%08x", binary.LittleEndian.Uint32(response[58:62])),
+		Extra: map[string]string{
+			"vendor id":    fmt.Sprint(vendorId),
+			"device type":  enipDeviceTypes[deviceType],
+			"product code": fmt.Sprint(binary.LittleEndian.Uint16(response[52:54])),
+			"ip":           net.IP(response[36:40]).String(),
+		},
+	}
+	if info.Vendor == "" {
+		info.Vendor = fmt.Sprintf("Vendor %d", vendorId)
+	}
+	if n := int(response[62]); 63+n <= len(response) {
+		info.Model = strings.TrimSpace(string(response[63 : 63+n]))
+	}
+	return info, nil
+}
+
+// DNP3 发送链路层 REQUEST LINK STATUS，从站只响应发往自身地址的报文，因此一次性遍历常用地址
+func dnp3LinkStatus(host string, timeout time.Duration) (*structs.ICSInfo, error) {
+	conn, err := WrapperTcpWithTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var request []byte
+	for dest := 0; dest <= 100; dest++ {
+		request = append(request, dnp3Frame(0xc9, uint16(dest), 0x0000)...)
+	}
+	response, err := icsExchange(conn, timeout, request)
+	if err != nil {
+		return nil, err
+	}
+	return parseDnp3LinkStatus(response)
+}
+
+func dnp3Frame(control byte, dest, src uint16) []byte {
+	frame := []byte{0x05, 0x64, 0x05, control, byte(dest), byte(dest >> 8), byte(src), byte(src >> 8)}
+	crc := dnp3CRC(frame)
+	return append(frame, byte(crc), byte(crc>>8))
+}
+
+func parseDnp3LinkStatus(response []byte) (*structs.ICSInfo, error) {
+	i := bytes.Index(response, []byte{0x05, 0x64})
+	if i == -1 || len(response) < i+10 {
+		return nil, errors.New("invalid dnp3 response")
+	}
+	frame := response[i : i+10]
+	if dnp3CRC(frame[:8]) != binary.LittleEndian.Uint16(frame[8:10]) {
+		return nil, errors.New("dnp3 crc mismatch")
+	}
+	return &structs.ICSInfo{Extra: map[string]string{
+		"source address":      fmt.Sprint(binary.LittleEndian.Uint16(frame[6:8])),
+		"destination address": fmt.Sprint(binary.LittleEndian.Uint16(frame[4:6])),
+		"control":             fmt.Sprintf("0x%02x", frame[3]),
+	}}, nil
+}
+
+// dnp3CRC 多项式 0x3D65（反射形式 0xA6BC），结果取反
+func dnp3CRC(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xa6bc
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return ^crc
+}
+
+// IEC 60870-5-104 发送 TESTFR act 测试帧，不发送 STARTDT 以免影响已有的主站连接
+func iec104TestFrame(host string, timeout time.Duration) (*structs.ICSInfo, error) {
+	conn, err := WrapperTcpWithTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	response, err := icsExchange(conn, timeout, []byte{0x68, 0x04, 0x43, 0x00, 0x00, 0x00})
+	if err != nil {
+		return nil, err
+	}
+	if len(response) < 6 || response[0] != 0x68 || response[2] != 0x83 {
+		return nil, errors.New("invalid testfr con")
+	}
+	return &structs.ICSInfo{Extra: map[string]string{"testfr": "con"}}, nil
+}
+
+// OMRON FINS/TCP 获取节点地址后发送 Controller Data Read（0501）
+func finsControllerData(host string, timeout time.Duration) (*structs.ICSInfo, error) {
+	conn, err := WrapperTcpWithTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	response, err := icsExchange(conn, timeout, mustHex("46494e530000000c000000000000000000000000"))
+	if err != nil {
+		return nil, err
+	}
+	if len(response) < 24 || !bytes.Equal(response[:4], []byte("FINS")) || binary.BigEndian.Uint32(response[12:16]) != 0 {
+		return nil, errors.New("fins node address request rejected")
+	}
+	clientNode, serverNode := response[19], response[23]
+	frame := []byte{0x80, 0x00, 0x02, 0x00, serverNode, 0x00, 0x00, clientNode, 0x00, 0xef, 0x05, 0x01}
+	request := append([]byte("FINS"), make([]byte, 12)...)
+	binary.BigEndian.PutUint32(request[4:8], uint32(8+len(frame)))
+	binary.BigEndian.PutUint32(request[8:12], 2)
+	response, err = icsExchange(conn, timeout, append(request, frame...))
+	if err != nil {
+		return nil, err
+	}
+	return parseFinsControllerData(response)
+}
+
+func parseFinsControllerData(response []byte) (*structs.ICSInfo, error) {
+	// FINS/TCP 头 16 字节 + FINS 头 10 字节 + 命令码 2 字节 + 结束码 2 字节
+	if len(response) < 30 || !bytes.Equal(response[:4], []byte("FINS")) || response[26] != 0x05 || response[27] != 0x01 {
+		return nil, errors.New("invalid controller data read response")
+	}
+	info := &structs.ICSInfo{Vendor: "OMRON", Extra: map[string]string{}}
+	if response[28] != 0x00 || response[29] != 0x00 {
+		info.Extra["end code"] = fmt.Sprintf("%02x%02x", response[28], response[29])
+		return info, nil
+	}
+	info.Model = cString(response, 30, 20)
+	info.Firmware = cString(response, 50, 20)
+	return info, nil
+}
+
+// Niagara Fox 握手，返回的键值包含站点名称、主机名、Niagara 与操作系统版本
+var foxHelloRequest = []byte("fox a 1 -1 fox hello\n{\nfox.version=s:1.0\nid=i:1\n};;\n")
+
+func foxHello(host string, timeout time.Duration) (*structs.ICSInfo, error) {
+	var (
+		conn net.Conn
+		err  error
+	)
+	if _, port, _ := net.SplitHostPort(host); port == "4911" {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", host, &tls.Config{InsecureSkipVerify: true})
+	} else {
+		conn, err = WrapperTcpWithTimeout("tcp", host, timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+	if _, err = conn.Write(foxHelloRequest); err != nil {
+		return nil, err
+	}
+	// 响应以 ;; 结尾，可能分多次返回
+	var response []byte
+	buf := make([]byte, 2048)
+	for !bytes.Contains(response, []byte(";;")) && len(response) < 8192 {
+		n, err := conn.Read(buf)
+		response = append(response, buf[:n]...)
+		if err != nil {
+			if err == io.EOF || len(response) > 0 {
+				break
+			}
+			return nil, err
+		}
+	}
+	return parseFoxHello(response)
+}
+
+func parseFoxHello(response []byte) (*structs.ICSInfo, error) {
+	if !bytes.HasPrefix(response, []byte("fox a")) {
+		return nil, errors.New("invalid fox response")
+	}
+	info := &structs.ICSInfo{Vendor: "Tridium Niagara", Extra: map[string]string{}}
+	for _, line := range strings.Split(string(response), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		// 值的格式为 类型:内容，例如 s:Station
+		if _, v, ok := strings.Cut(value, ":"); ok {
+			value = v
+		}
+		switch key {
+		case "app.name":
+			info.Model = value
+		case "app.version":
+			info.Firmware = value
+		case "brandId":
+			info.Vendor = value
+		case "hostId":
+			info.Serial = value
+		case "fox.version", "hostName", "hostAddress", "station.name", "vm.name", "vm.version", "os.name", "os.version", "timeZone":
+			info.Extra[key] = value
+		}
+	}
+	return info, nil
+}
+
+// BACnetInfoScan 通过 UDP ReadProperty 读取设备对象（通配实例号 4194303）的厂商、型号与固件版本
+func BACnetInfoScan(ip string, port int, timeout time.Duration) (*structs.ICSInfo, error) {
+	conn, err := net.DialTimeout("udp", net.JoinHostPort(ip, fmt.Sprint(port)), timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	properties := []struct {
+		id  byte
+		key string
+	}{
+		{121, "vendor"}, {70, "model"}, {44, "firmware"}, {12, "application software"}, {77, "object name"},
+	}
+	info := &structs.ICSInfo{Protocol: "bacnet", Extra: map[string]string{}}
+	identified := false
+	for i, p := range properties {
+		request := []byte{0x81, 0x0a, 0x00, 0x11, 0x01, 0x04, 0x00, 0x05, byte(i + 1), 0x0c, 0x0c, 0x02, 0x3f, 0xff, 0xff, 0x19, p.id}
+		response, err := icsExchange(conn, timeout, request)
+		if err != nil {
+			// 首个请求无响应说明端口未开放或非 BACnet 设备
+			if i == 0 {
+				return nil, err
+			}
+			continue
+		}
+		value, err := parseBACnetProperty(response)
+		if err != nil {
+			continue
+		}
+		identified = true
+		switch p.key {
+		case "vendor":
+			info.Vendor = value
+		case "model":
+			info.Model = value
+		case "firmware":
+			info.Firmware = value
+		default:
+			info.Extra[p.key] = value
+		}
+	}
+	if !identified {
+		return nil, errors.New("invalid bacnet response")
+	}
+	return info, nil
+}
+
+// parseBACnetProperty 解析 ReadProperty ComplexACK 中的字符串属性值
+func parseBACnetProperty(response []byte) (string, error) {
+	if len(response) < 6 || response[0] != 0x81 || response[4] != 0x01 {
+		return "", errors.New("invalid bvlc header")
+	}
+	// NPDU 控制字段中包含源地址时需要跳过 SNET/SLEN/SADR
+	pos, control := 6, response[5]
+	if control&0x20 != 0 && len(response) > pos+3 {
+		pos += 3 + int(response[pos+2])
+	}
+	if control&0x08 != 0 && len(response) > pos+3 {
+		pos += 3 + int(response[pos+2])
+	}
+	if control&0x20 != 0 {
+		pos++ // hop count
+	}
+	if len(response) < pos+3 || response[pos]&0xf0 != 0x30 || response[pos+2] != 0x0c {
+		return "", errors.New("not a readproperty ack")
+	}
+	open := bytes.IndexByte(response[pos:], 0x3e)
+	if open == -1 || pos+open+2 >= len(response) {
+		return "", errors.New("missing property value")
+	}
+	pos += open + 1
+	tag := response[pos]
+	if tag>>4 != 7 {
+		return "", fmt.Errorf("unsupported value tag 0x%02x", tag)
+	}
+	length := int(tag & 0x07)
+	pos++
+	if length == 5 {
+		length = int(response[pos])
+		pos++
+	}
+	// 首字节为字符集，0 表示 UTF-8
+	if length < 1 || pos+length > len(response) {
+		return "", errors.New("invalid character string")
+	}
+	return strings.TrimSpace(string(response[pos+1 : pos+length])), nil
+}
+
+func cString(b []byte, offset, maxLen int) string {
+	if offset >= len(b) {
+		return ""
+	}
+	end := offset + maxLen
+	if end > len(b) {
+		end = len(b)
+	}
+	s := b[offset:end]
+	if i := bytes.IndexByte(s, 0); i != -1 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(string(s))
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func containsInt(items []int, v int) bool {
+	for _, item := range items {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(items []string, v string) bool {
+	for _, item := range items {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -2,9 +2,8 @@ package portscan
 
 import (
 	"context"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // 只要是nmap 扫描到jdwp协议，默认是 unauthorized (因为也是同样发JDWP-Handshake包检测)
@@ -41,7 +40,7 @@ func JdwpScan(ctx, ctrlCtx context.Context, taskId, address string, usernames, p
 	// 	gologger.Info(ctx, fmt.Sprintf("%s is not jdwp", address))
 	// 	return
 	// }
-	runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+	resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 		TaskId:      taskId,
 		ID:          "jdwp unauthorized",
 		Name:        "jdwp unauthorized",
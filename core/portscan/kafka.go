@@ -5,18 +5,18 @@ import (
 	"context"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
 
 	"github.com/IBM/sarama"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func KafkaScan(ctx, ctrlCtx context.Context, taskId, address string, usernames, passwords []string) {
 	flag, err := KafkaConn(address, "", "")
 	if flag && err == nil {
-		runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+		resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 			TaskId:   taskId,
 			ID:       "kafka unauthorized",
 			Name:     "kafka unauthorized",
@@ -39,7 +39,7 @@ func KafkaScan(ctx, ctrlCtx context.Context, taskId, address string, usernames,
 			pass = strings.Replace(pass, "{user}", user, -1)
 			flag, err := KafkaConn(address, user, pass)
 			if flag && err == nil {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 					TaskId:   taskId,
 					ID:       "kafka weak password",
 					Name:     "kafka weak password",
@@ -2,13 +2,14 @@ package portscan
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 
 	"github.com/go-ldap/ldap/v3"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func LdapScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwords []string) {
@@ -19,9 +20,9 @@ func LdapScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, pass
 				return
 			}
 			pass = strings.Replace(pass, "{user}", user, -1)
-			flag, err := MssqlConn(host, user, pass)
+			flag, err := Ldapconn(host, user, pass)
 			if flag && err == nil {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 					TaskId:   taskId,
 					ID:       "ldap weak password",
 					Name:     "ldap weak password",
@@ -44,7 +45,12 @@ func Ldapconn(host, user, pass string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	defer conn.Close()
 
+	// 空密码会被视为匿名绑定而直接成功
+	if pass == "" {
+		return false, ldap.NewError(ldap.LDAPResultUnwillingToPerform, errors.New("empty password"))
+	}
 	err = conn.Bind(user, pass)
 	if err != nil {
 		return false, err
@@ -4,11 +4,10 @@ import (
 	"context"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func MemcachedScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwords []string) {
@@ -27,7 +26,7 @@ func MemcachedScan(ctx, ctrlCtx context.Context, taskId, host string, usernames,
 				n, err := client.Read(rev)
 				if err == nil {
 					if strings.Contains(string(rev[:n]), "STAT") {
-						runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+						resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 							TaskId:   taskId,
 							ID:       "memcached unauthorized",
 							Name:     "memcached unauthorized",
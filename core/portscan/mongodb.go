@@ -4,11 +4,11 @@ import (
 	"context"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
 
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -17,7 +17,7 @@ import (
 func MongodbScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwords []string) {
 	flag, err := MongodbConn(host, "", "")
 	if flag && err == nil {
-		runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+		resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 			TaskId:   taskId,
 			ID:       "mongodb unauthorized",
 			Name:     "mongodb unauthorized",
@@ -38,7 +38,7 @@ func MongodbScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, p
 			pass = strings.Replace(pass, "{user}", string(user), -1)
 			flag, err := MongodbConn(host, user, pass)
 			if flag && err == nil {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 					TaskId:   taskId,
 					ID:       "mongodb weak password",
 					Name:     "mongodb weak password",
@@ -4,17 +4,17 @@ import (
 	"context"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func MqttScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwords []string) {
 	flag, err := MqttUnauth(host)
 	if flag && err == nil {
-		runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+		resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 			TaskId:   taskId,
 			ID:       "mqtt unauthorized",
 			Name:     "mqtt unauthorized",
@@ -36,7 +36,7 @@ func MqttScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, pass
 			pass = strings.Replace(pass, "{user}", user, -1)
 			flag, err := MqttConn(host, user, pass)
 			if flag && err == nil {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 					TaskId:   taskId,
 					ID:       "mqtt weak password",
 					Name:     "mqtt weak password",
@@ -10,11 +10,10 @@ import (
 	"errors"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 var (
@@ -136,7 +135,7 @@ func MS17010Scan(ctx context.Context, taskId, host string) error {
 		//} else{fmt.Printf("\033[33m%s\tMS17-010\t(%s)\033[0m\n", ip, os)}
 		result := fmt.Sprintf("[+] MS17-010 %s\t(%s)", host, os)
 		gologger.Success(ctx, result)
-		runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+		resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 			TaskId:   taskId,
 			ID:       "MS17-010",
 			Name:     "MS17-010",
@@ -161,7 +160,8 @@ func MS17010Scan(ctx context.Context, taskId, host string) error {
 
 		if reply[34] == 0x51 {
 			result := fmt.Sprintf("[+] MS17-010 %s has DOUBLEPULSAR SMB IMPLANT", host)
-			runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+			resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
+				TaskId:      taskId,
 				ID:          "DOUBLEPULSAR SMB IMPLANT",
 				Name:        "DOUBLEPULSAR SMB IMPLANT",
 				URL:         host,
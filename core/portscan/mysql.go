@@ -5,12 +5,12 @@ import (
 	"database/sql"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func MysqlScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwords []string) {
@@ -23,7 +23,7 @@ func MysqlScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, pas
 			pass = strings.Replace(pass, "{user}", user, -1)
 			flag, err := MysqlConn(host, user, pass)
 			if flag && err == nil {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 					TaskId:   taskId,
 					ID:       "mysql weak password",
 					Name:     "mysql weak password",
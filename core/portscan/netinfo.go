@@ -0,0 +1,181 @@
+package portscan
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"slack-wails/lib/structs"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// NetBIOS 名称查询与 OXID ServerAlive2 解析，用于获取主机名、工作组以及多网卡地址
+// 参考 RFC 1002 4.2.17/4.2.18 与 MS-DCOM 3.1.2.5.1.6
+
+var (
+	// IObjectExporter 99fcfec4-5260-101b-bbcb-00aa0021347a v0.0
+	rpcOXIDSyntax = rpcSyntax("99fcfec4-5260-101b-bbcb-00aa0021347a", 0, 0)
+	// NBSTAT 通配名称 "*" 的一级编码
+	nbstatQuestion = append(append([]byte{0x20}, []byte("CKAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA")...), 0x00, 0x00, 0x21, 0x00, 0x01)
+)
+
+const towerNcacnIPTCP = 0x0007
+
+// NetInfoScan 根据端口选择 NetBIOS 或 OXID 探测，135 端口会同时执行两者
+func NetInfoScan(scheme, ip string, port int, timeout time.Duration) (*structs.NetInfo, error) {
+	var (
+		info *structs.NetInfo
+		err  error
+	)
+	switch {
+	case port == 135 || scheme == "msrpc":
+		info, err = OXIDResolve(net.JoinHostPort(ip, fmt.Sprint(port)), timeout)
+		if err != nil {
+			return nil, err
+		}
+		if nb, err := NetBIOSNameQuery(ip, timeout); err == nil {
+			info.ComputerName = nb.ComputerName
+			info.Workgroup = nb.Workgroup
+			info.MAC = nb.MAC
+			info.DomainController = nb.DomainController
+		}
+	case port == 139 || scheme == "netbios-ssn":
+		info, err = NetBIOSNameQuery(ip, timeout)
+	default:
+		return nil, errors.New("netinfo: unsupported protocol " + scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// NetBIOSNameQuery 向 UDP 137 发送 NBSTAT 查询，解析名称表中的计算机名、工作组与 MAC 地址
+func NetBIOSNameQuery(ip string, timeout time.Duration) (*structs.NetInfo, error) {
+	conn, err := net.DialTimeout("udp", net.JoinHostPort(ip, "137"), timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	// TransactionID + Flags + QDCOUNT=1
+	query := []byte{0x53, 0x4c, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	if _, err = conn.Write(append(query, nbstatQuestion...)); err != nil {
+		return nil, err
+	}
+	reply := make([]byte, 1024)
+	n, err := conn.Read(reply)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNBSTATResponse(reply[:n])
+}
+
+// ParseNBSTATResponse 解析 NODE STATUS RESPONSE
+func ParseNBSTATResponse(data []byte) (*structs.NetInfo, error) {
+	if len(data) < 13 || binary.BigEndian.Uint16(data[6:8]) == 0 {
+		return nil, errors.New("netinfo: empty nbstat response")
+	}
+	offset := 12
+	// RR_NAME 可能是压缩指针
+	if data[offset]&0xc0 == 0xc0 {
+		offset += 2
+	} else {
+		for offset < len(data) && data[offset] != 0 {
+			offset += int(data[offset]) + 1
+		}
+		offset++
+	}
+	// TYPE + CLASS + TTL + RDLENGTH
+	offset += 10
+	if offset >= len(data) {
+		return nil, errors.New("netinfo: invalid nbstat response")
+	}
+	count := int(data[offset])
+	offset++
+	info := &structs.NetInfo{}
+	for i := 0; i < count && offset+18 <= len(data); i++ {
+		name := strings.TrimRight(string(data[offset:offset+15]), " \x00")
+		suffix := data[offset+15]
+		group := binary.BigEndian.Uint16(data[offset+16:offset+18])&0x8000 != 0
+		switch {
+		case suffix == 0x00 && !group && info.ComputerName == "":
+			info.ComputerName = name
+		case suffix == 0x00 && group && info.Workgroup == "":
+			info.Workgroup = name
+		case suffix == 0x1c && group:
+			info.DomainController = true
+		}
+		offset += 18
+	}
+	if offset+6 <= len(data) {
+		mac := net.HardwareAddr(data[offset : offset+6]).String()
+		if mac != "00:00:00:00:00:00" {
+			info.MAC = mac
+		}
+	}
+	if info.ComputerName == "" && info.Workgroup == "" {
+		return nil, errors.New("netinfo: no names in nbstat response")
+	}
+	return info, nil
+}
+
+// OXIDResolve 匿名调用 IObjectExporter::ServerAlive2，返回主机名以及所有网卡地址
+func OXIDResolve(host string, timeout time.Duration) (*structs.NetInfo, error) {
+	rc, err := dialRPC(host, rpcOXIDSyntax, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	stub, err := rc.Call(5, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ParseServerAlive2(stub)
+}
+
+// ParseServerAlive2 解析 ServerAlive2 响应中的 DUALSTRINGARRAY，仅保留 ncacn_ip_tcp 的字符串绑定
+func ParseServerAlive2(stub []byte) (*structs.NetInfo, error) {
+	// COMVERSION + ppdsaOrBindings 引用 + 一致性数组长度 + wNumEntries + wSecurityOffset
+	if len(stub) < 16 || binary.LittleEndian.Uint32(stub[4:8]) == 0 {
+		return nil, errors.New("netinfo: invalid serveralive2 response")
+	}
+	numEntries := int(binary.LittleEndian.Uint16(stub[12:14]))
+	securityOffset := int(binary.LittleEndian.Uint16(stub[14:16]))
+	if securityOffset > numEntries || 16+numEntries*2 > len(stub) {
+		return nil, errors.New("netinfo: invalid dualstringarray")
+	}
+	words := make([]uint16, securityOffset)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint16(stub[16+i*2:])
+	}
+	info := &structs.NetInfo{}
+	for i := 0; i < len(words) && words[i] != 0; {
+		towerID := words[i]
+		start := i + 1
+		end := start
+		for end < len(words) && words[end] != 0 {
+			end++
+		}
+		if towerID == towerNcacnIPTCP {
+			// 形如 hostname 或 10.0.0.1[135]，去掉可选的端口部分
+			addr := string(utf16.Decode(words[start:end]))
+			if idx := strings.Index(addr, "["); idx != -1 {
+				addr = addr[:idx]
+			}
+			if net.ParseIP(addr) == nil && info.Hostname == "" {
+				info.Hostname = addr
+			} else if addr != "" {
+				info.Addresses = append(info.Addresses, addr)
+			}
+		}
+		i = end + 1
+	}
+	if info.Hostname == "" && len(info.Addresses) == 0 {
+		return nil, errors.New("netinfo: no string bindings")
+	}
+	return info, nil
+}
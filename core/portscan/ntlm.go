@@ -0,0 +1,478 @@
+package portscan
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slack-wails/lib/structs"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/qiwentaidi/clients"
+)
+
+// NTLMSSP 信息探测，无需任何凭据，发送 NEGOTIATE 后解析服务端返回的 CHALLENGE
+// 参考 MS-NLMP 2.2.1.2 / 2.2.2.1
+
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// 与 impacket 默认协商标志保持一致，包含 NEGOTIATE_TARGET_INFO 与 NEGOTIATE_VERSION
+var ntlmNegotiateMessage = []byte{
+	'N', 'T', 'L', 'M', 'S', 'S', 'P', 0x00,
+	0x01, 0x00, 0x00, 0x00, // MessageType
+	0x97, 0x82, 0x08, 0xe2, // NegotiateFlags
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // DomainNameFields
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // WorkstationFields
+	0x06, 0x01, 0xb1, 0x1d, 0x00, 0x00, 0x00, 0x0f, // Version
+}
+
+const (
+	msvAvEOL             = 0x0000
+	msvAvNbComputerName  = 0x0001
+	msvAvNbDomainName    = 0x0002
+	msvAvDnsComputerName = 0x0003
+	msvAvDnsDomainName   = 0x0004
+	msvAvDnsTreeName     = 0x0005
+
+	ntlmNegotiateVersion = 0x02000000
+)
+
+// NTLMInfoScan 根据协议或端口选择对应的探测方式，返回 CHALLENGE 中解析出的主机信息
+func NTLMInfoScan(scheme, ip string, port int, timeout time.Duration) (*structs.NTLMInfo, error) {
+	host := net.JoinHostPort(ip, fmt.Sprint(port))
+	var (
+		protocol  string
+		challenge []byte
+		err       error
+	)
+	switch {
+	case scheme == "microsoft-ds" || scheme == "smb" || port == 445:
+		protocol = "smb"
+		challenge, err = smbNTLMChallenge(host, timeout)
+	case scheme == "ms-wbt-server" || scheme == "rdp" || port == 3389:
+		protocol = "rdp"
+		challenge, err = rdpNTLMChallenge(host, timeout)
+	case scheme == "ms-sql-s" || scheme == "mssql" || port == 1433:
+		protocol = "mssql"
+		challenge, err = mssqlNTLMChallenge(host, timeout)
+	case scheme == "smtp" || port == 25 || port == 587:
+		protocol = "smtp"
+		challenge, err = smtpNTLMChallenge(host, timeout)
+	case scheme == "imap" || port == 143:
+		protocol = "imap"
+		challenge, err = imapNTLMChallenge(host, timeout)
+	case scheme == "http" || scheme == "https":
+		protocol = "http"
+		challenge, err = httpNTLMChallenge(scheme, host, port, timeout)
+	default:
+		return nil, errors.New("ntlm info: unsupported protocol " + scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := ParseNTLMChallenge(challenge)
+	if err != nil {
+		return nil, err
+	}
+	info.Protocol = protocol
+	return info, nil
+}
+
+// ParseNTLMChallenge 从任意包含 NTLMSSP CHALLENGE 的数据中解析主机信息
+func ParseNTLMChallenge(data []byte) (*structs.NTLMInfo, error) {
+	start := bytes.Index(data, ntlmSignature)
+	if start == -1 {
+		return nil, errors.New("ntlm info: challenge message not found")
+	}
+	msg := data[start:]
+	if len(msg) < 48 || binary.LittleEndian.Uint32(msg[8:12]) != 2 {
+		return nil, errors.New("ntlm info: invalid challenge message")
+	}
+	info := &structs.NTLMInfo{}
+	flags := binary.LittleEndian.Uint32(msg[20:24])
+	if flags&ntlmNegotiateVersion != 0 && len(msg) >= 56 {
+		info.OSVersion = fmt.Sprintf("Windows %d.%d Build %d", msg[48], msg[49], binary.LittleEndian.Uint16(msg[50:52]))
+	}
+	infoLen := int(binary.LittleEndian.Uint16(msg[40:42]))
+	infoOffset := int(binary.LittleEndian.Uint32(msg[44:48]))
+	if infoOffset+infoLen > len(msg) {
+		return nil, errors.New("ntlm info: target info out of range")
+	}
+	targetInfo := msg[infoOffset : infoOffset+infoLen]
+	for len(targetInfo) >= 4 {
+		avID := binary.LittleEndian.Uint16(targetInfo[0:2])
+		avLen := int(binary.LittleEndian.Uint16(targetInfo[2:4]))
+		if avID == msvAvEOL || 4+avLen > len(targetInfo) {
+			break
+		}
+		value := decodeUTF16LE(targetInfo[4 : 4+avLen])
+		switch avID {
+		case msvAvNbComputerName:
+			info.NetBIOSComputerName = value
+		case msvAvNbDomainName:
+			info.NetBIOSDomainName = value
+		case msvAvDnsComputerName:
+			info.DNSComputerName = value
+		case msvAvDnsDomainName:
+			info.DNSDomainName = value
+		case msvAvDnsTreeName:
+			info.DNSTreeName = value
+		}
+		targetInfo = targetInfo[4+avLen:]
+	}
+	return info, nil
+}
+
+func decodeUTF16LE(b []byte) string {
+	u16s := make([]uint16, len(b)/2)
+	for i := range u16s {
+		u16s[i] = binary.LittleEndian.Uint16(b[i*2:])
+	}
+	return string(utf16.Decode(u16s))
+}
+
+// asn1Length 编码 DER 长度字段
+func asn1Length(n int) []byte {
+	switch {
+	case n < 0x80:
+		return []byte{byte(n)}
+	case n < 0x100:
+		return []byte{0x81, byte(n)}
+	default:
+		return []byte{0x82, byte(n >> 8), byte(n)}
+	}
+}
+
+func asn1Wrap(tag byte, content []byte) []byte {
+	return append(append([]byte{tag}, asn1Length(len(content))...), content...)
+}
+
+// spnegoNegTokenInit 将 NTLMSSP 消息封装为 GSS-API SPNEGO NegTokenInit
+func spnegoNegTokenInit(token []byte) []byte {
+	ntlmOID := []byte{0x06, 0x0a, 0x2b, 0x06, 0x01, 0x04, 0x01, 0x82, 0x37, 0x02, 0x02, 0x0a}
+	spnegoOID := []byte{0x06, 0x06, 0x2b, 0x06, 0x01, 0x05, 0x05, 0x02}
+	mechTypes := asn1Wrap(0xa0, asn1Wrap(0x30, ntlmOID))
+	mechToken := asn1Wrap(0xa2, asn1Wrap(0x04, token))
+	negTokenInit := asn1Wrap(0xa0, asn1Wrap(0x30, append(mechTypes, mechToken...)))
+	return asn1Wrap(0x60, append(spnegoOID, negTokenInit...))
+}
+
+func smb2Header(command uint16, messageID uint64) []byte {
+	header := make([]byte, 64)
+	copy(header[0:4], []byte{0xfe, 'S', 'M', 'B'})
+	binary.LittleEndian.PutUint16(header[4:6], 64)
+	binary.LittleEndian.PutUint16(header[12:14], command)
+	binary.LittleEndian.PutUint16(header[14:16], 1)
+	binary.LittleEndian.PutUint64(header[24:32], messageID)
+	return header
+}
+
+// netbiosSession 添加 NetBIOS Session Service 头部
+func netbiosSession(payload []byte) []byte {
+	length := len(payload)
+	return append([]byte{0x00, byte(length >> 16), byte(length >> 8), byte(length)}, payload...)
+}
+
+func readNetbiosSession(conn net.Conn) ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	length := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+	payload := make([]byte, length)
+	_, err := io.ReadFull(conn, payload)
+	return payload, err
+}
+
+// smb2Negotiate 发送仅包含 SMB 2.0.2 / 2.1 方言的 NEGOTIATE 请求并返回响应
+func smb2Negotiate(conn net.Conn) ([]byte, error) {
+	negotiate := make([]byte, 40)
+	binary.LittleEndian.PutUint16(negotiate[0:2], 36) // StructureSize
+	binary.LittleEndian.PutUint16(negotiate[2:4], 2)  // DialectCount
+	binary.LittleEndian.PutUint16(negotiate[4:6], 1)  // SecurityMode: signing enabled
+	binary.LittleEndian.PutUint16(negotiate[36:38], 0x0202)
+	binary.LittleEndian.PutUint16(negotiate[38:40], 0x0210)
+	if _, err := conn.Write(netbiosSession(append(smb2Header(0, 0), negotiate...))); err != nil {
+		return nil, err
+	}
+	return readNetbiosSession(conn)
+}
+
+func smbNTLMChallenge(host string, timeout time.Duration) ([]byte, error) {
+	conn, err := WrapperTcpWithTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err = smb2Negotiate(conn); err != nil {
+		return nil, err
+	}
+
+	securityBuffer := spnegoNegTokenInit(ntlmNegotiateMessage)
+	sessionSetup := make([]byte, 24)
+	binary.LittleEndian.PutUint16(sessionSetup[0:2], 25) // StructureSize
+	sessionSetup[3] = 1                                  // SecurityMode
+	binary.LittleEndian.PutUint16(sessionSetup[12:14], 64+24)
+	binary.LittleEndian.PutUint16(sessionSetup[14:16], uint16(len(securityBuffer)))
+	sessionSetup = append(sessionSetup, securityBuffer...)
+	if _, err = conn.Write(netbiosSession(append(smb2Header(1, 1), sessionSetup...))); err != nil {
+		return nil, err
+	}
+	return readNetbiosSession(conn)
+}
+
+func rdpNTLMChallenge(host string, timeout time.Duration) ([]byte, error) {
+	conn, err := WrapperTcpWithTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	// TPKT + X.224 Connection Request + RDP_NEG_REQ(PROTOCOL_SSL | PROTOCOL_HYBRID)
+	connectionRequest := []byte{
+		0x03, 0x00, 0x00, 0x13,
+		0x0e, 0xe0, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x08, 0x00, 0x03, 0x00, 0x00, 0x00,
+	}
+	if _, err = conn.Write(connectionRequest); err != nil {
+		return nil, err
+	}
+	reply := make([]byte, 19)
+	if _, err = io.ReadFull(conn, reply); err != nil {
+		return nil, err
+	}
+	// RDP_NEG_RSP 的 selectedProtocol 需要包含 CredSSP
+	if reply[11] != 0x02 || binary.LittleEndian.Uint32(reply[15:19])&0x0a == 0 {
+		return nil, errors.New("ntlm info: rdp does not support credssp")
+	}
+	tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true})
+	if err = tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+	// TSRequest { version: 2, negoTokens: [ { negoToken: NTLMSSP } ] }
+	negoData := asn1Wrap(0xa1, asn1Wrap(0x30, asn1Wrap(0x30, asn1Wrap(0xa0, asn1Wrap(0x04, ntlmNegotiateMessage)))))
+	tsRequest := asn1Wrap(0x30, append([]byte{0xa0, 0x03, 0x02, 0x01, 0x02}, negoData...))
+	if _, err = tlsConn.Write(tsRequest); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 4096)
+	n, err := tlsConn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+func mssqlNTLMChallenge(host string, timeout time.Duration) ([]byte, error) {
+	conn, err := WrapperTcpWithTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	// PRELOGIN: VERSION + ENCRYPTION(ENCRYPT_NOT_SUP)，避免进入 TLS 封装的登录流程
+	prelogin := []byte{
+		0x00, 0x00, 0x0b, 0x00, 0x06,
+		0x01, 0x00, 0x11, 0x00, 0x01,
+		0xff,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x02,
+	}
+	if _, err = conn.Write(tdsPacket(0x12, prelogin)); err != nil {
+		return nil, err
+	}
+	resp, err := readTDSPacket(conn)
+	if err != nil {
+		return nil, err
+	}
+	if encryption, ok := tdsPreloginOption(resp, 0x01); ok && encryption == 0x03 {
+		return nil, errors.New("ntlm info: mssql requires encryption")
+	}
+
+	// LOGIN7 固定部分 94 字节，仅携带 SSPI 数据并打开 fIntSecurity
+	login := make([]byte, 94)
+	binary.LittleEndian.PutUint32(login[0:4], uint32(94+len(ntlmNegotiateMessage)))
+	binary.LittleEndian.PutUint32(login[4:8], 0x71000001) // TDS 7.1
+	binary.LittleEndian.PutUint32(login[8:12], 4096)      // PacketSize
+	login[24] = 0xe0                                      // OptionFlags1
+	login[25] = 0x80                                      // OptionFlags2: fIntSecurity
+	for offset := 36; offset < 72; offset += 4 {
+		binary.LittleEndian.PutUint16(login[offset:], 94)
+	}
+	binary.LittleEndian.PutUint16(login[78:80], 94)
+	binary.LittleEndian.PutUint16(login[80:82], uint16(len(ntlmNegotiateMessage)))
+	binary.LittleEndian.PutUint16(login[82:84], 94)
+	binary.LittleEndian.PutUint16(login[86:88], 94)
+	login = append(login, ntlmNegotiateMessage...)
+	if _, err = conn.Write(tdsPacket(0x10, login)); err != nil {
+		return nil, err
+	}
+	return readTDSPacket(conn)
+}
+
+func tdsPacket(packetType byte, payload []byte) []byte {
+	header := []byte{packetType, 0x01, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00}
+	binary.BigEndian.PutUint16(header[2:4], uint16(len(payload)+8))
+	return append(header, payload...)
+}
+
+func readTDSPacket(conn net.Conn) ([]byte, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	length := int(binary.BigEndian.Uint16(header[2:4]))
+	if length < 8 {
+		return nil, errors.New("ntlm info: invalid tds packet")
+	}
+	payload := make([]byte, length-8)
+	_, err := io.ReadFull(conn, payload)
+	return payload, err
+}
+
+func tdsPreloginOption(payload []byte, token byte) (byte, bool) {
+	for i := 0; i+5 <= len(payload) && payload[i] != 0xff; i += 5 {
+		if payload[i] != token {
+			continue
+		}
+		offset := int(binary.BigEndian.Uint16(payload[i+1 : i+3]))
+		if offset < len(payload) {
+			return payload[offset], true
+		}
+	}
+	return 0, false
+}
+
+func smtpNTLMChallenge(host string, timeout time.Duration) ([]byte, error) {
+	conn, err := WrapperTcpWithTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+	reader := bufio.NewReader(conn)
+
+	if _, err = readSMTPReply(reader); err != nil {
+		return nil, err
+	}
+	if _, err = conn.Write([]byte("EHLO slack\r\n")); err != nil {
+		return nil, err
+	}
+	if _, err = readSMTPReply(reader); err != nil {
+		return nil, err
+	}
+	if _, err = fmt.Fprintf(conn, "AUTH NTLM %s\r\n", base64.StdEncoding.EncodeToString(ntlmNegotiateMessage)); err != nil {
+		return nil, err
+	}
+	line, err := readSMTPReply(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "334 ") {
+		return nil, errors.New("ntlm info: smtp does not support ntlm auth")
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(line[4:]))
+}
+
+// readSMTPReply 读取多行响应并返回最后一行
+func readSMTPReply(reader *bufio.Reader) (string, error) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		if len(line) < 4 || line[3] != '-' {
+			return line, nil
+		}
+	}
+}
+
+func imapNTLMChallenge(host string, timeout time.Duration) ([]byte, error) {
+	conn, err := WrapperTcpWithTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+	reader := bufio.NewReader(conn)
+
+	if _, err = reader.ReadString('\n'); err != nil {
+		return nil, err
+	}
+	if _, err = conn.Write([]byte("a001 AUTHENTICATE NTLM\r\n")); err != nil {
+		return nil, err
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "+") {
+		return nil, errors.New("ntlm info: imap does not support ntlm auth")
+	}
+	if _, err = conn.Write([]byte(base64.StdEncoding.EncodeToString(ntlmNegotiateMessage) + "\r\n")); err != nil {
+		return nil, err
+	}
+	line, err = reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(strings.TrimPrefix(line, "+")))
+}
+
+// httpNTLMChallenge 适用于 WinRM、Exchange、IIS 等开启 NTLM/Negotiate 认证的站点，未声明支持时不发送认证请求
+func httpNTLMChallenge(scheme, host string, port int, timeout time.Duration) ([]byte, error) {
+	method, path := "GET", "/"
+	headers := map[string]string{}
+	// WinRM 仅在 /wsman 上启用认证
+	if port == 5985 || port == 5986 {
+		method, path = "POST", "/wsman"
+		headers["Content-Type"] = "application/soap+xml;charset=UTF-8"
+	}
+	target := fmt.Sprintf("%s://%s%s", scheme, host, path)
+	client := clients.NewRestyClient(nil, false)
+	resp, err := clients.DoRequest(method, target, headers, nil, int(timeout.Seconds()), client)
+	if err != nil {
+		return nil, err
+	}
+	if !AdvertisesNTLM(resp.Header()) {
+		return nil, errors.New("ntlm info: http does not support ntlm auth")
+	}
+	headers["Authorization"] = "NTLM " + base64.StdEncoding.EncodeToString(ntlmNegotiateMessage)
+	resp, err = clients.DoRequest(method, target, headers, nil, int(timeout.Seconds()), client)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range resp.Header().Values("WWW-Authenticate") {
+		for _, prefix := range []string{"NTLM ", "Negotiate "} {
+			if strings.HasPrefix(value, prefix) {
+				if challenge, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[len(prefix):])); err == nil {
+					return challenge, nil
+				}
+			}
+		}
+	}
+	return nil, errors.New("ntlm info: http does not support ntlm auth")
+}
+
+// AdvertisesNTLM 响应头是否声明支持 NTLM 或 Negotiate 认证
+func AdvertisesNTLM(header http.Header) bool {
+	for _, value := range header.Values("WWW-Authenticate") {
+		scheme, _, _ := strings.Cut(strings.TrimSpace(value), " ")
+		if strings.EqualFold(scheme, "NTLM") || strings.EqualFold(scheme, "Negotiate") {
+			return true
+		}
+	}
+	return false
+}
@@ -5,12 +5,12 @@ import (
 	"database/sql"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
 
 	_ "github.com/sijms/go-ora/v2"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 const defaultOracleServerName = "orcl"
@@ -25,7 +25,7 @@ func OracleScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, pa
 			pass = strings.Replace(pass, "{user}", user, -1)
 			flag, err := OracleConn(host, defaultOracleServerName, user, pass)
 			if flag && err == nil {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 					TaskId:   taskId,
 					ID:       "oracle weak password",
 					Name:     "oracle weak password",
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net"
 	"slack-wails/core/webscan"
+	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"sync"
@@ -26,7 +28,7 @@ func TcpScan(ctx, ctrlCtx context.Context, taskId string, addresses <-chan Addre
 	// openPorts := make(map[string]bool) // 记录开放的端口
 	go func() {
 		for pr := range retChan {
-			runtime.EventsEmit(ctx, "webFingerScan", pr)
+			resultstore.Fingerprint(ctx, *pr)
 		}
 		close(single)
 	}()
@@ -94,6 +96,12 @@ type Address struct {
 }
 
 func Connect(ctx context.Context, taskId, ip string, port, timeout int, proxyURL string) *structs.InfoResult {
+	// BACnet/IP 仅监听 UDP，TCP 探测必然失败
+	if port == bacnetPort && proxyURL == "" {
+		if icsInfo, err := BACnetInfoScan(ip, port, time.Second*time.Duration(timeout)); err == nil {
+			return icsResult(ctx, taskId, ip, port, icsInfo)
+		}
+	}
 	scanner := gonmap.New()
 	status, response := scanner.Scan(ip, port, time.Second*time.Duration(timeout), proxyURL)
 
@@ -102,6 +110,7 @@ func Connect(ctx context.Context, taskId, ip string, port, timeout int, proxyURL
 		return nil
 	}
 	var tcpfinger []string
+	var versions map[string]string
 	var raw string // 添加一个默认值
 	// 默认协议设为 unknown
 	scheme := "unknown"
@@ -114,7 +123,7 @@ func Connect(ctx context.Context, taskId, ip string, port, timeout int, proxyURL
 		Banner:   strings.ToLower(raw),
 	}
 	if scheme != "http" && scheme != "https" {
-		tcpfinger = webscan.Scan(ctx, tcpinfo, webscan.FingerprintDB)
+		tcpfinger, versions = webscan.Identify(tcpinfo, webscan.FingerprintDB)
 	}
 	// if scheme == "unknown" {
 	// 	scheme = gonmap.GuessProtocol(port)
@@ -127,19 +136,76 @@ func Connect(ctx context.Context, taskId, ip string, port, timeout int, proxyURL
 		URL:          fmt.Sprintf("%s://%s:%d", scheme, ip, port),
 		Fingerprints: tcpfinger,
 		Detect:       "Default",
+
+		FingerprintDetails: webscan.FingerprintDetails(tcpfinger, versions),
 	}
 
 	// 若是 HTTP/HTTPS，尝试请求获取状态码
+	var ntlmAdvertised bool
 	if scheme == "http" || scheme == "https" {
 		resp, err := clients.SimpleGet(result.URL, clients.NewRestyClient(nil, true))
 		if err == nil {
 			result.StatusCode = resp.StatusCode()
+			ntlmAdvertised = AdvertisesNTLM(resp.Header())
+		}
+	}
+
+	// 工控协议 gonmap 常识别为 unknown 或错误的服务，使用只读请求获取设备信息，设置代理时跳过以免暴露真实地址
+	if proxyURL == "" {
+		if icsInfo, err := ICSInfoScan(scheme, ip, port, time.Second*time.Duration(timeout)); err == nil {
+			result.ICSInfo = icsInfo
+			result.Scheme = icsInfo.Protocol
+			result.URL = fmt.Sprintf("%s://%s:%d", icsInfo.Protocol, ip, port)
+			result.Fingerprints = append(result.Fingerprints, ICSFingerprint(icsInfo))
+			gologger.Info(ctx, fmt.Sprintf("[ics] %s:%d %s %s", ip, port, icsInfo.Protocol, ICSFingerprint(icsInfo)))
+		}
+	}
+
+	// Windows 相关服务通过 NTLMSSP 质询获取主机名、域名与系统版本，网站仅在声明支持 NTLM 认证或为 WinRM 时探测
+	// 质询请求直接建立连接，设置代理时跳过以免暴露真实地址
+	isWeb := scheme == "http" || scheme == "https"
+	if proxyURL == "" && (!isWeb || ntlmAdvertised || port == 5985 || port == 5986) {
+		if ntlmInfo, err := NTLMInfoScan(scheme, ip, port, time.Second*time.Duration(timeout)); err == nil {
+			result.NTLMInfo = ntlmInfo
+			gologger.Info(ctx, fmt.Sprintf("[ntlm] %s:%d %s\\%s %s", ip, port, ntlmInfo.NetBIOSDomainName, ntlmInfo.NetBIOSComputerName, ntlmInfo.OSVersion))
+		}
+	}
+
+	// NetBIOS 名称查询与 OXID 网卡解析，输出格式与 fscan NetInfo 保持一致便于工具页解析
+	// NetBIOS 与 OXID 请求直接建立连接，设置代理时跳过以免暴露真实地址
+	if (port == 135 || port == 139) && proxyURL == "" {
+		if netInfo, err := NetInfoScan(scheme, ip, port, time.Second*time.Duration(timeout)); err == nil {
+			result.NetInfo = netInfo
+			lines := []string{fmt.Sprintf("[*]%s", ip)}
+			for _, name := range []string{netInfo.Workgroup + "\\" + netInfo.ComputerName, netInfo.Hostname} {
+				if name != "" && name != "\\" {
+					lines = append(lines, "   [->]"+strings.TrimPrefix(name, "\\"))
+				}
+			}
+			for _, addr := range netInfo.Addresses {
+				lines = append(lines, "   [->]"+addr)
+			}
+			gologger.Info(ctx, strings.Join(lines, "\n"))
 		}
 	}
 
 	return result
 }
 
+func icsResult(ctx context.Context, taskId, ip string, port int, icsInfo *structs.ICSInfo) *structs.InfoResult {
+	gologger.Info(ctx, fmt.Sprintf("[ics] %s:%d %s %s", ip, port, icsInfo.Protocol, ICSFingerprint(icsInfo)))
+	return &structs.InfoResult{
+		TaskId:       taskId,
+		Host:         ip,
+		Port:         port,
+		Scheme:       icsInfo.Protocol,
+		URL:          fmt.Sprintf("%s://%s:%d", icsInfo.Protocol, ip, port),
+		Fingerprints: []string{ICSFingerprint(icsInfo)},
+		Detect:       "Default",
+		ICSInfo:      icsInfo,
+	}
+}
+
 func WrapperTcpWithTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
 	d := &net.Dialer{Timeout: timeout}
 	return WrapperTCP(network, address, d)
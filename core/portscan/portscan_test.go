@@ -1,6 +1,14 @@
 package portscan
 
 import (
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -38,3 +46,262 @@ func TestWrapperTcpWithTimeout(t *testing.T) {
 	// fmt.Printf("status: %v\n", status)
 	// fmt.Printf("response: %v\n", response)
 }
+
+func TestParseNTLMChallenge(t *testing.T) {
+	avPair := func(id uint16, value string) []byte {
+		var b []byte
+		for _, r := range value {
+			b = append(b, byte(r), 0x00)
+		}
+		return append([]byte{byte(id), byte(id >> 8), byte(len(b)), byte(len(b) >> 8)}, b...)
+	}
+	var targetInfo []byte
+	targetInfo = append(targetInfo, avPair(msvAvNbDomainName, "CORP")...)
+	targetInfo = append(targetInfo, avPair(msvAvNbComputerName, "DC01")...)
+	targetInfo = append(targetInfo, avPair(msvAvDnsDomainName, "corp.local")...)
+	targetInfo = append(targetInfo, avPair(msvAvDnsComputerName, "dc01.corp.local")...)
+	targetInfo = append(targetInfo, 0x00, 0x00, 0x00, 0x00)
+
+	msg := make([]byte, 56)
+	copy(msg, ntlmSignature)
+	msg[8] = 0x02
+	msg[23] = 0x02 // NEGOTIATE_VERSION
+	msg[40], msg[41] = byte(len(targetInfo)), byte(len(targetInfo)>>8)
+	msg[44] = 56
+	copy(msg[48:], []byte{0x0a, 0x00, 0x63, 0x45, 0x00, 0x00, 0x00, 0x0f})
+	msg = append(msg, targetInfo...)
+
+	// 模拟 SPNEGO/TDS 等协议中夹带的 CHALLENGE
+	info, err := ParseNTLMChallenge(append([]byte{0xa1, 0x81, 0xff}, msg...))
+	if err != nil {
+		t.Fatalf("ParseNTLMChallenge() returned an error: %v", err)
+	}
+	if info.NetBIOSDomainName != "CORP" || info.NetBIOSComputerName != "DC01" || info.DNSDomainName != "corp.local" || info.DNSComputerName != "dc01.corp.local" {
+		t.Errorf("ParseNTLMChallenge() = %+v", info)
+	}
+	if info.OSVersion != "Windows 10.0 Build 17763" {
+		t.Errorf("ParseNTLMChallenge() OSVersion = %s", info.OSVersion)
+	}
+}
+
+func TestHTTPNTLMChallenge(t *testing.T) {
+	challenge := append([]byte(nil), ntlmSignature...)
+	var authorized int32
+	ntlm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Add("WWW-Authenticate", "Negotiate")
+			w.Header().Add("WWW-Authenticate", "NTLM")
+		} else {
+			atomic.AddInt32(&authorized, 1)
+			w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(challenge))
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ntlm.Close()
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			atomic.AddInt32(&authorized, 1)
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer plain.Close()
+
+	got, err := httpNTLMChallenge("http", strings.TrimPrefix(ntlm.URL, "http://"), 80, 5*time.Second)
+	if err != nil || !reflect.DeepEqual(got, challenge) || atomic.LoadInt32(&authorized) != 1 {
+		t.Fatalf("httpNTLMChallenge() = %v, %v, authorized %d", got, err, atomic.LoadInt32(&authorized))
+	}
+	// 未声明 NTLM/Negotiate 认证的站点不应收到认证请求
+	if _, err := httpNTLMChallenge("http", strings.TrimPrefix(plain.URL, "http://"), 80, 5*time.Second); err == nil || atomic.LoadInt32(&authorized) != 1 {
+		t.Fatalf("httpNTLMChallenge() sent ntlm negotiate to a site without ntlm auth, err %v", err)
+	}
+}
+
+func TestSMBGhost(t *testing.T) {
+	// 64 字节 SMB2 头部 + 64 字节 NEGOTIATE 响应，随后为预认证完整性与压缩能力上下文
+	reply := make([]byte, 128)
+	copy(reply, []byte{0xfe, 'S', 'M', 'B'})
+	binary.LittleEndian.PutUint16(reply[68:70], 0x0311)
+	binary.LittleEndian.PutUint16(reply[70:72], 2)
+	binary.LittleEndian.PutUint32(reply[124:128], 128)
+	reply = append(reply, 0x01, 0x00, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00)
+	reply = append(reply, 0x03, 0x00, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00)
+	if compressed, err := smbCompressionNegotiated(reply); err != nil || !compressed {
+		t.Fatalf("smbCompressionNegotiated() = %v, %v", compressed, err)
+	}
+	binary.LittleEndian.PutUint16(reply[68:70], 0x0302)
+	if compressed, _ := smbCompressionNegotiated(reply); compressed {
+		t.Fatal("smbCompressionNegotiated() flagged SMB 3.0.2")
+	}
+
+	// 已修复或更新的系统同样协商压缩能力，不应报告
+	for _, osVersion := range []string{"Windows 10.0 Build 19041", "Windows 10.0 Build 20348", "Windows 10.0 Build 17763", "Windows 6.3 Build 9600"} {
+		if id, _, _, ok := SMBGhostVerdict(osVersion); ok {
+			t.Errorf("SMBGhostVerdict(%q) reported %s", osVersion, id)
+		}
+	}
+	if id, severity, _, ok := SMBGhostVerdict("Windows 10.0 Build 18363"); !ok || id != "CVE-2020-0796" || severity != "HIGH" {
+		t.Errorf("SMBGhostVerdict(1909) = %s %s %v", id, severity, ok)
+	}
+	if id, severity, _, ok := SMBGhostVerdict(""); !ok || id != "CVE-2020-0796 (unconfirmed)" || severity != "INFO" {
+		t.Errorf("SMBGhostVerdict(unknown) = %s %s %v", id, severity, ok)
+	}
+}
+
+func TestParseServerAlive2(t *testing.T) {
+	var words []uint16
+	for _, binding := range []string{"WIN-FILE01", "192.168.10.5", "10.10.0.5[135]"} {
+		words = append(words, towerNcacnIPTCP)
+		for _, r := range binding {
+			words = append(words, uint16(r))
+		}
+		words = append(words, 0)
+	}
+	words = append(words, 0)
+	securityOffset := len(words)
+	// 安全绑定部分不会被解析
+	words = append(words, 0x0009, 0xffff, 0, 0, 0)
+
+	stub := []byte{0x05, 0x00, 0x07, 0x00, 0x00, 0x00, 0x02, 0x00}
+	stub = append(stub, byte(len(words)), 0x00, 0x00, 0x00)
+	stub = append(stub, byte(len(words)), 0x00, byte(securityOffset), 0x00)
+	for _, w := range words {
+		stub = append(stub, byte(w), byte(w>>8))
+	}
+	info, err := ParseServerAlive2(stub)
+	if err != nil {
+		t.Fatalf("ParseServerAlive2() returned an error: %v", err)
+	}
+	if info.Hostname != "WIN-FILE01" || len(info.Addresses) != 2 || info.Addresses[1] != "10.10.0.5" {
+		t.Errorf("ParseServerAlive2() = %+v", info)
+	}
+}
+
+func TestParseICSResponses(t *testing.T) {
+	if crc := dnp3CRC([]byte("123456789")); crc != 0xea82 {
+		t.Errorf("dnp3CRC() = %#04x", crc)
+	}
+	if _, err := parseDnp3LinkStatus(dnp3Frame(0x0b, 0, 10)); err != nil {
+		t.Errorf("parseDnp3LinkStatus() returned an error: %v", err)
+	}
+
+	modbus := []byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x01, 0x2b, 0x0e, 0x01, 0x01, 0x00, 0x00, 0x03}
+	for id, value := range []string{"Schneider Electric", "BMX P34 2020", "v2.70"} {
+		modbus = append(append(modbus, byte(id), byte(len(value))), value...)
+	}
+	info, err := parseModbusDeviceIdentification(modbus)
+	if err != nil || info.Vendor != "Schneider Electric" || info.Model != "BMX P34 2020" || info.Firmware != "v2.70" {
+		t.Errorf("parseModbusDeviceIdentification() = %+v, %v", info, err)
+	}
+
+	enip := make([]byte, 63)
+	enip[0], enip[26], enip[48], enip[50], enip[54], enip[55] = 0x63, 0x0c, 0x01, 0x0e, 20, 11
+	copy(enip[36:40], []byte{192, 168, 1, 20})
+	enip[62] = byte(len("1756-L71/B LOGIX5571"))
+	enip = append(enip, "1756-L71/B LOGIX5571"...)
+	info, err = parseEnipListIdentity(enip)
+	if err != nil || info.Vendor != "Rockwell Automation/Allen-Bradley" || info.Model != "1756-L71/B LOGIX5571" || info.Firmware != "20.11" {
+		t.Errorf("parseEnipListIdentity() = %+v, %v", info, err)
+	}
+
+	bacnet := []byte{0x81, 0x0a, 0x00, 0x1d, 0x01, 0x00, 0x30, 0x01, 0x0c, 0x0c, 0x02, 0x3f, 0xff, 0xff, 0x19, 0x79, 0x3e, 0x75, 0x0a, 0x00}
+	bacnet = append(append(bacnet, "Honeywell"...), 0x3f)
+	if vendor, err := parseBACnetProperty(bacnet); err != nil || vendor != "Honeywell" {
+		t.Errorf("parseBACnetProperty() = %q, %v", vendor, err)
+	}
+
+	fox := "fox a 0 -1 fox hello\n{\nfox.version=s:1.0.1\nhostName=s:192.168.1.5\napp.name=s:Station\napp.version=s:3.8.311\nbrandId=s:vykon\n};;\n"
+	info, err = parseFoxHello([]byte(fox))
+	if err != nil || info.Vendor != "vykon" || info.Model != "Station" || info.Firmware != "3.8.311" || info.Extra["hostName"] != "192.168.1.5" {
+		t.Errorf("parseFoxHello() = %+v, %v", info, err)
+	}
+}
+
+func TestParseHttpLoginForm(t *testing.T) {
+	base, _ := url.Parse("http://127.0.0.1:8080/login")
+	body := `<html><form id="search"><input name="q"></form>
+<form action="/doLogin" method="post">
+<input type="hidden" name="_csrf" value="abc">
+<input type="text" name="remark">
+<input type="text" name="loginName">
+<input type="password" name="pwd">
+<input type="submit" name="submit" value="Login">
+</form></html>`
+	form, err := parseHttpLoginForm(base, body)
+	if err != nil {
+		t.Fatalf("parseHttpLoginForm() returned an error: %v", err)
+	}
+	if form.Action != "http://127.0.0.1:8080/doLogin" || form.Method != "POST" || form.UserField != "loginName" || form.PassField != "pwd" || !form.HasCSRF {
+		t.Errorf("parseHttpLoginForm() = %+v", form)
+	}
+	if form.Fields.Get("_csrf") != "abc" || form.Fields.Get("submit") != "Login" {
+		t.Errorf("parseHttpLoginForm() fields = %v", form.Fields)
+	}
+}
+
+func TestShareSecurityDescriptor(t *testing.T) {
+	sid := func(authority byte, subs ...uint32) []byte {
+		data := []byte{1, byte(len(subs)), 0, 0, 0, 0, 0, authority}
+		for _, sub := range subs {
+			data = binary.LittleEndian.AppendUint32(data, sub)
+		}
+		return data
+	}
+	ace := func(aceType byte, mask uint32, sid []byte) []byte {
+		data := []byte{aceType, 0x00}
+		data = binary.LittleEndian.AppendUint16(data, uint16(8+len(sid)))
+		data = binary.LittleEndian.AppendUint32(data, mask)
+		return append(data, sid...)
+	}
+	aces := append(ace(0x00, 0x001200a9, sid(1, 0)), ace(0x00, 0x001f01ff, sid(5, 32, 544))...)
+	aces = append(aces, ace(0x01, 0x001301bf, sid(5, 32, 546))...)
+	aces = append(aces, ace(0x00, 0x10000000, sid(5, 21, 1, 2, 3, 1001))...)
+	dacl := []byte{2, 0}
+	dacl = binary.LittleEndian.AppendUint16(dacl, uint16(8+len(aces)))
+	dacl = binary.LittleEndian.AppendUint16(dacl, 4)
+	dacl = append(append(dacl, 0, 0), aces...)
+	sd := []byte{1, 0, 0x04, 0x80, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 20, 0, 0, 0}
+	sd = append(sd, dacl...)
+
+	// SHARE_INFO_502：指针字段之后依次为 netname、remark、path 字符串与安全描述符
+	resp := binary.LittleEndian.AppendUint32(nil, 502)
+	resp = binary.LittleEndian.AppendUint32(resp, 0x20000)
+	for _, field := range []uint32{0x20004, 0, 0x20008, 0, 0xffffffff, 1, 0x2000c, 0, uint32(len(sd)), 0x20010} {
+		resp = binary.LittleEndian.AppendUint32(resp, field)
+	}
+	resp = append(resp, ndrString("Data")...)
+	resp = append(resp, ndrString("")...)
+	resp = append(resp, ndrString(`C:\Data`)...)
+	resp = binary.LittleEndian.AppendUint32(resp, uint32(len(sd)))
+	resp = append(resp, sd...)
+	for len(resp)%4 != 0 {
+		resp = append(resp, 0)
+	}
+	resp = binary.LittleEndian.AppendUint32(resp, 0)
+
+	descriptor, err := parseShareInfo502(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acl, err := parseShareSecurityDescriptor(descriptor)
+	want := []string{"Everyone:READ", `BUILTIN\Administrators:FULL`, `DENY BUILTIN\Guests:CHANGE`, "S-1-5-21-1-2-3-1001:FULL"}
+	if err != nil || !reflect.DeepEqual(acl, want) {
+		t.Fatalf("parseShareSecurityDescriptor() = %v, %v", acl, err)
+	}
+
+	if _, err := parseShareSecurityDescriptor(sd[:24]); err == nil {
+		t.Fatal("parseShareSecurityDescriptor() should fail on a truncated dacl")
+	}
+	// 没有 DACL 时所有人完全控制
+	noDACL := append([]byte{}, sd[:20]...)
+	binary.LittleEndian.PutUint32(noDACL[16:20], 0)
+	if acl, _ := parseShareSecurityDescriptor(noDACL); !reflect.DeepEqual(acl, []string{"Everyone:FULL"}) {
+		t.Fatalf("null dacl = %v", acl)
+	}
+	// 无权限读取时返回 ERROR_ACCESS_DENIED
+	denied := binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, 502), 0)
+	denied = binary.LittleEndian.AppendUint32(denied, 5)
+	if _, err := parseShareInfo502(denied); err == nil {
+		t.Fatal("parseShareInfo502() should fail on access denied")
+	}
+}
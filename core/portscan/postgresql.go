@@ -5,12 +5,12 @@ import (
 	"database/sql"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func PostgresScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwords []string) {
@@ -23,7 +23,7 @@ func PostgresScan(ctx, ctrlCtx context.Context, taskId, host string, usernames,
 			pass = strings.Replace(pass, "{user}", string(user), -1)
 			flag, err := PostgresConn(host, user, pass)
 			if flag && err == nil {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 					TaskId:   taskId,
 					ID:       "postgres weak password",
 					Name:     "postgres weak password",
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"sync"
@@ -20,7 +21,6 @@ import (
 	"github.com/tomatome/grdp/protocol/t125"
 	"github.com/tomatome/grdp/protocol/tpkt"
 	"github.com/tomatome/grdp/protocol/x224"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func RdpScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwords []string) {
@@ -42,7 +42,7 @@ func RdpScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passw
 				flag, err := RdpConn(host, "", user, pass, 10)
 				mutex.Lock()
 				if flag && err == nil {
-					runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+					resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 						TaskId:   taskId,
 						ID:       "rdp weak password",
 						Name:     "rdp weak password",
@@ -4,17 +4,16 @@ import (
 	"context"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func RedisScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwords []string) {
 	flag, err := RedisUnauth(host)
 	if flag && err == nil {
-		runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+		resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 			TaskId:   taskId,
 			ID:       "redis unauthorized",
 			Name:     "redis unauthorized",
@@ -35,7 +34,7 @@ func RedisScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, pas
 		pass = strings.ReplaceAll(pass, "{user}", "redis")
 		flag, err := RedisConn(host, pass)
 		if flag && err == nil {
-			runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+			resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 				TaskId:   taskId,
 				ID:       "redis weak password",
 				Name:     "redis weak password",
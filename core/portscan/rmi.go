@@ -5,10 +5,9 @@ import (
 	"fmt"
 	"regexp"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"time"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 var rmiVulRegexp = regexp.MustCompile(`^N[\s\S]{1,2}\d*\.\d*\.\d*\.\d*`)
@@ -40,7 +39,7 @@ func RmiScan(ctx, ctrlCtx context.Context, taskId, address string, usernames, pa
 					// 检查返回的数据是否包含RMI响应特征
 					result := rmiVulRegexp.Find(rev)
 					if result != nil {
-						runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+						resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 							TaskId:   taskId,
 							ID:       "rmi unauthorized",
 							Name:     "rmi unauthorized",
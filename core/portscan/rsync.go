@@ -6,17 +6,16 @@ import (
 	"fmt"
 	"net"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func RsyncScan(ctx, ctrlCtx context.Context, taskId, address string, usernames, passwords []string) {
 	flag, moduleName, err := RsyncConn(address, "", "")
 	if flag && err == nil {
-		runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+		resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 			TaskId:   taskId,
 			ID:       "rsync unauthorized",
 			Name:     "rsync unauthorized",
@@ -39,7 +38,7 @@ func RsyncScan(ctx, ctrlCtx context.Context, taskId, address string, usernames,
 			pass = strings.Replace(pass, "{user}", string(user), -1)
 			flag, moduleName, err = RsyncConn(address, user, pass)
 			if flag && err == nil {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 					TaskId:   taskId,
 					ID:       "rsync weak password",
 					Name:     "rsync weak password",
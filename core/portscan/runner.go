@@ -34,6 +34,8 @@ var crackScanners = map[string]crackFunc{
 	"activemq":   ActiveMQScan,
 	"rsync":      RsyncScan,
 	"kafka":      KafkaScan,
+	"http":       HttpLoginScan,
+	"https":      HttpLoginScan,
 }
 
 func Runner(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwords []string) {
@@ -42,8 +44,13 @@ func Runner(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwo
 		gologger.Debug(ctx, fmt.Sprintf("[!] Parse url error: %s\n", err))
 		return
 	}
+	// HTTP 登录暴破需要保留完整的 URL 路径
+	target := u.Host
+	if u.Scheme == "http" || u.Scheme == "https" {
+		target = host
+	}
 	if scanFunc, ok := crackScanners[u.Scheme]; ok {
-		scanFunc(ctx, ctrlCtx, taskId, u.Host, usernames, passwords)
+		scanFunc(ctx, ctrlCtx, taskId, target, usernames, passwords)
 	} else {
 		gologger.Error(ctx, fmt.Sprintf("[!] No brute module registered for: %s\n", u.Scheme))
 	}
@@ -51,6 +58,7 @@ func Runner(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwo
 	switch u.Scheme {
 	case "smb":
 		MS17010(ctx, taskId, u.Host)
+		SmbVulnScan(ctx, taskId, u.Host)
 	}
 	runtime.EventsEmit(ctx, fmt.Sprintf("crackDone::%s", host))
 }
@@ -5,13 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/stacktitan/smb/smb"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func SmbScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwords []string) {
@@ -24,7 +24,7 @@ func SmbScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passw
 			pass = strings.Replace(pass, "{user}", user, -1)
 			flag, err := doWithTimeOut(host, user, pass)
 			if flag && err == nil {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 					TaskId:   taskId,
 					ID:       "smb weak password",
 					Name:     "smb weak password",
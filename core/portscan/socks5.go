@@ -4,12 +4,12 @@ import (
 	"context"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strconv"
 	"strings"
 
 	"github.com/qiwentaidi/clients"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 const defaultAliveURL = "http://www.baidu.com"
@@ -23,7 +23,7 @@ func Socks5Scan(ctx, ctrlCtx context.Context, taskId, host string, usernames, pa
 	}
 	flag := Socks5Conn(hostwithoutport, port, 3, "", "", defaultAliveURL)
 	if flag {
-		runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+		resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 			TaskId:   taskId,
 			ID:       "socks5 unauthorized",
 			Name:     "socks5 unauthorized",
@@ -44,7 +44,7 @@ func Socks5Scan(ctx, ctrlCtx context.Context, taskId, host string, usernames, pa
 			pass = strings.Replace(pass, "{user}", string(user), -1)
 			flag = Socks5Conn(hostwithoutport, port, 3, user, pass, defaultAliveURL)
 			if flag {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 					TaskId:   taskId,
 					ID:       "socks5 weak password",
 					Name:     "socks5 weak password",
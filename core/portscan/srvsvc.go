@@ -0,0 +1,260 @@
+package portscan
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/projectdiscovery/go-smb2"
+)
+
+// 通过 IPC$ 下的 srvsvc 命名管道调用 NetrShareGetInfo level 502，读取共享的安全描述符
+// 参考 MS-SRVS 3.1.4.10 与 MS-DTYP 2.4.6 SECURITY_DESCRIPTOR
+
+var (
+	// SRVSVC 4b324fc8-1670-01d3-1278-5a47bf6ee188 v3
+	rpcSRVSVCSyntax = rpcSyntax("4b324fc8-1670-01d3-1278-5a47bf6ee188", 3, 0)
+)
+
+const (
+	srvsvcNetrShareGetInfo = 16
+	shareInfoLevel502      = 502
+
+	aceAccessAllowed = 0x00
+	aceAccessDenied  = 0x01
+)
+
+// 共享权限对应的访问掩码，取自共享属性中完全控制、更改、读取三档权限
+var sharePermissions = []struct {
+	name string
+	mask uint32
+}{
+	{"FULL", 0x001f01ff},
+	{"CHANGE", 0x001301bf},
+	{"READ", 0x001200a9},
+}
+
+var wellKnownSIDs = map[string]string{
+	"S-1-1-0":      "Everyone",
+	"S-1-5-7":      "ANONYMOUS LOGON",
+	"S-1-5-11":     "Authenticated Users",
+	"S-1-5-18":     "SYSTEM",
+	"S-1-5-32-544": "BUILTIN\\Administrators",
+	"S-1-5-32-545": "BUILTIN\\Users",
+	"S-1-5-32-546": "BUILTIN\\Guests",
+	"S-1-5-32-547": "BUILTIN\\Power Users",
+	"S-1-5-32-551": "BUILTIN\\Backup Operators",
+}
+
+// openRPCPipe 在 SMB 会话的 IPC$ 中打开命名管道并绑定到指定接口
+// 命名管道按消息读取，读取长度小于消息长度时服务端返回 STATUS_BUFFER_OVERFLOW，因此经缓冲区整条读取
+func openRPCPipe(ipc *smb2.Share, name string, abstractSyntax []byte) (*rpcConn, error) {
+	pipe, err := ipc.OpenFile(name, os.O_RDWR, 0666)
+	if err != nil {
+		return nil, err
+	}
+	rc := &rpcConn{conn: pipe, reader: bufio.NewReaderSize(pipe, 64*1024), callID: 1}
+	if err = rc.bind(abstractSyntax); err != nil {
+		pipe.Close()
+		return nil, err
+	}
+	return rc, nil
+}
+
+// shareACL 查询共享的安全描述符并返回各账户的共享权限，通常需要管理员权限
+func shareACL(rc *rpcConn, server, share string) ([]string, error) {
+	stub := binary.LittleEndian.AppendUint32(nil, 0x00020000) // ServerName 引用 ID
+	stub = append(stub, ndrString(server)...)
+	stub = append(stub, ndrString(share)...)
+	stub = binary.LittleEndian.AppendUint32(stub, shareInfoLevel502)
+	resp, err := rc.Call(srvsvcNetrShareGetInfo, stub)
+	if err != nil {
+		return nil, err
+	}
+	descriptor, err := parseShareInfo502(resp)
+	if err != nil {
+		return nil, err
+	}
+	return parseShareSecurityDescriptor(descriptor)
+}
+
+// ndrString 编码 conformant varying 的 UTF-16 字符串，包含结尾的空字符并按 4 字节对齐
+func ndrString(s string) []byte {
+	chars := append(utf16.Encode([]rune(s)), 0)
+	buf := binary.LittleEndian.AppendUint32(nil, uint32(len(chars))) // max_count
+	buf = binary.LittleEndian.AppendUint32(buf, 0)                   // offset
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(chars)))  // actual_count
+	for _, c := range chars {
+		buf = binary.LittleEndian.AppendUint16(buf, c)
+	}
+	for len(buf)%4 != 0 {
+		buf = append(buf, 0x00)
+	}
+	return buf
+}
+
+// ndrReader 按 NDR 对齐规则顺序读取响应
+type ndrReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *ndrReader) uint32() (uint32, error) {
+	r.offset += (4 - r.offset%4) % 4
+	if r.offset+4 > len(r.data) {
+		return 0, errors.New("srvsvc: response truncated")
+	}
+	value := binary.LittleEndian.Uint32(r.data[r.offset:])
+	r.offset += 4
+	return value, nil
+}
+
+// skipString 跳过一个 conformant varying 字符串
+func (r *ndrReader) skipString() error {
+	if _, err := r.uint32(); err != nil {
+		return err
+	}
+	if _, err := r.uint32(); err != nil {
+		return err
+	}
+	count, err := r.uint32()
+	if err != nil {
+		return err
+	}
+	r.offset += int(count) * 2
+	if r.offset > len(r.data) {
+		return errors.New("srvsvc: response truncated")
+	}
+	return nil
+}
+
+// parseShareInfo502 解析 NetrShareGetInfo 响应中的 SHARE_INFO_502，返回自相对格式的安全描述符
+func parseShareInfo502(resp []byte) ([]byte, error) {
+	if len(resp) < 4 {
+		return nil, errors.New("srvsvc: response truncated")
+	}
+	if status := binary.LittleEndian.Uint32(resp[len(resp)-4:]); status != 0 {
+		return nil, fmt.Errorf("srvsvc: NetrShareGetInfo status 0x%08x", status)
+	}
+	r := &ndrReader{data: resp[:len(resp)-4]}
+	if level, err := r.uint32(); err != nil || level != shareInfoLevel502 {
+		return nil, fmt.Errorf("srvsvc: unexpected info level %d", level)
+	}
+	if ref, err := r.uint32(); err != nil || ref == 0 {
+		return nil, errors.New("srvsvc: empty share info")
+	}
+	// netname, type, remark, permissions, max_uses, current_uses, path, passwd, reserved, security_descriptor
+	var fields [10]uint32
+	for i := range fields {
+		value, err := r.uint32()
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = value
+	}
+	// 指针指向的内容按声明顺序依次跟在结构体之后
+	for _, i := range []int{0, 2, 6, 7} {
+		if fields[i] != 0 {
+			if err := r.skipString(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if fields[9] == 0 {
+		return nil, nil
+	}
+	size, err := r.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if r.offset+int(size) > len(r.data) {
+		return nil, errors.New("srvsvc: response truncated")
+	}
+	return r.data[r.offset : r.offset+int(size)], nil
+}
+
+// parseShareSecurityDescriptor 解析安全描述符中的 DACL，返回形如 Everyone:READ 的共享权限
+func parseShareSecurityDescriptor(sd []byte) ([]string, error) {
+	// 未设置安全描述符或 DACL 为空指针时所有人完全控制
+	if len(sd) == 0 {
+		return []string{"Everyone:FULL"}, nil
+	}
+	if len(sd) < 20 || sd[0] != 1 {
+		return nil, errors.New("srvsvc: invalid security descriptor")
+	}
+	daclOffset := int(binary.LittleEndian.Uint32(sd[16:20]))
+	if daclOffset == 0 {
+		return []string{"Everyone:FULL"}, nil
+	}
+	if daclOffset+8 > len(sd) {
+		return nil, errors.New("srvsvc: invalid dacl")
+	}
+	aceCount := int(binary.LittleEndian.Uint16(sd[daclOffset+4 : daclOffset+6]))
+	var acl []string
+	offset := daclOffset + 8
+	for i := 0; i < aceCount; i++ {
+		if offset+8 > len(sd) {
+			return nil, errors.New("srvsvc: invalid ace")
+		}
+		aceType := sd[offset]
+		aceSize := int(binary.LittleEndian.Uint16(sd[offset+2 : offset+4]))
+		if aceSize < 8 || offset+aceSize > len(sd) {
+			return nil, errors.New("srvsvc: invalid ace")
+		}
+		mask := binary.LittleEndian.Uint32(sd[offset+4 : offset+8])
+		sid := parseSID(sd[offset+8 : offset+aceSize])
+		if name, ok := wellKnownSIDs[sid]; ok {
+			sid = name
+		}
+		switch aceType {
+		case aceAccessAllowed:
+			acl = append(acl, sid+":"+sharePermission(mask))
+		case aceAccessDenied:
+			acl = append(acl, "DENY "+sid+":"+sharePermission(mask))
+		}
+		offset += aceSize
+	}
+	return acl, nil
+}
+
+// sharePermission 将访问掩码转换为共享权限名称，通用权限按对应的档位处理
+func sharePermission(mask uint32) string {
+	switch {
+	case mask&0x10000000 != 0: // GENERIC_ALL
+		return "FULL"
+	case mask&0x40000000 != 0: // GENERIC_WRITE
+		return "CHANGE"
+	case mask&0x80000000 != 0: // GENERIC_READ
+		return "READ"
+	}
+	for _, permission := range sharePermissions {
+		if mask&permission.mask == permission.mask {
+			return permission.name
+		}
+	}
+	return fmt.Sprintf("0x%08x", mask)
+}
+
+// parseSID 将二进制 SID 转为 S-1-5-32-544 形式
+func parseSID(data []byte) string {
+	if len(data) < 8 {
+		return ""
+	}
+	count := int(data[1])
+	if len(data) < 8+count*4 {
+		return ""
+	}
+	var authority uint64
+	for _, b := range data[2:8] {
+		authority = authority<<8 | uint64(b)
+	}
+	sid := []string{fmt.Sprintf("S-%d-%d", data[0], authority)}
+	for i := 0; i < count; i++ {
+		sid = append(sid, fmt.Sprint(binary.LittleEndian.Uint32(data[8+i*4:])))
+	}
+	return strings.Join(sid, "-")
+}
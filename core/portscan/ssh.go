@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"net"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
 
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -38,7 +38,7 @@ func SshScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passw
 					if err != nil {
 						result = err.Error()
 					}
-					runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+					resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 						TaskId:   taskId,
 						ID:       "ssh weak password",
 						Name:     "ssh weak password",
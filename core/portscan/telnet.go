@@ -5,11 +5,10 @@ import (
 	"fmt"
 	"slack-wails/lib/gologger"
 	"slack-wails/lib/gotelnet"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strconv"
 	"strings"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func TelnetScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwords []string) {
@@ -25,7 +24,7 @@ func TelnetScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, pa
 			pass = strings.Replace(pass, "{user}", user, -1)
 			flag, err := TelnetConn(h, user, pass, p, serverType)
 			if flag && err == nil {
-				runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+				resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 					TaskId:   taskId,
 					ID:       "telnet weak password",
 					Name:     "telnet weak password",
@@ -4,12 +4,12 @@ import (
 	"context"
 	"fmt"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"strings"
 	"time"
 
 	"github.com/mitchellh/go-vnc"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func VncScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passwords []string) {
@@ -21,7 +21,7 @@ func VncScan(ctx, ctrlCtx context.Context, taskId, host string, usernames, passw
 		pass = strings.Replace(pass, "{user}", "vnc", -1)
 		flag, err := VncConn(host, pass)
 		if flag && err == nil {
-			runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
+			resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
 				TaskId:   taskId,
 				ID:       "vnc weak password",
 				Name:     "vnc weak password",
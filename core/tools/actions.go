@@ -0,0 +1,573 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slack-wails/core/portscan"
+	"slack-wails/lib/structs"
+	"slack-wails/lib/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/yaml.v2"
+)
+
+// 登录成功后按协议执行的只读命令集合，可在 actions.yaml 中追加或覆盖
+type ActionProfile struct {
+	Name     string   `yaml:"name"`
+	Protocol string   `yaml:"protocol"`
+	Actions  []Action `yaml:"actions"`
+}
+
+type Action struct {
+	Name    string `yaml:"name"`
+	Command string `yaml:"command"`
+}
+
+// actionSession 对应一次登录，Execute 执行单条命令并返回文本结果
+type actionSession interface {
+	Execute(command string) (string, error)
+	Close()
+}
+
+type sessionOpener func(ip, port, username, password string) (actionSession, error)
+
+var actionSessions = map[string]sessionOpener{
+	"ftp":       openFtpSession,
+	"ssh":       openSshSession,
+	"mysql":     openSqlSession("mysql"),
+	"mssql":     openSqlSession("mssql"),
+	"oracle":    openSqlSession("oracle"),
+	"postgres":  openSqlSession("postgres"),
+	"redis":     openRedisSession,
+	"memcached": openMemcachedSession,
+	"mongodb":   openMongodbSession,
+}
+
+// 与爆破模块名称不一致的协议
+var actionProtocolAlias = map[string]string{
+	"postgresql": "postgres",
+}
+
+const actionTimeout = 5 * time.Second
+
+var actionProfileFile = filepath.Join(utils.HomeDir(), "slack", "config", "actions.yaml")
+
+const defaultActionProfileYAML = `# 登录成功后执行的只读命令，同协议同名的配置会按顺序依次执行
+# protocol 可选: ftp ssh mysql mssql oracle postgres redis memcached mongodb
+- name: default
+  protocol: mysql
+  actions:
+    - { name: version, command: "SELECT VERSION()" }
+    - { name: current user, command: "SELECT CURRENT_USER()" }
+    - { name: databases, command: "SHOW DATABASES" }
+    - { name: users, command: "SELECT user, host FROM mysql.user" }
+    - { name: privileges, command: "SHOW GRANTS" }
+    - { name: secure_file_priv, command: "SHOW VARIABLES LIKE 'secure_file_priv'" }
+    - { name: plugin_dir, command: "SHOW VARIABLES LIKE 'plugin_dir'" }
+- name: default
+  protocol: mssql
+  actions:
+    - { name: version, command: "SELECT @@VERSION" }
+    - { name: current user, command: "SELECT SYSTEM_USER, IS_SRVROLEMEMBER('sysadmin')" }
+    - { name: databases, command: "SELECT name FROM sys.databases" }
+    - { name: xp_cmdshell, command: "SELECT name, CONVERT(INT, value_in_use) FROM sys.configurations WHERE name IN ('xp_cmdshell', 'Ole Automation Procedures', 'clr enabled')" }
+    - { name: linked servers, command: "SELECT name, product, provider, data_source FROM sys.servers WHERE is_linked = 1" }
+- name: default
+  protocol: oracle
+  actions:
+    - { name: version, command: "SELECT banner FROM v$version" }
+    - { name: current user, command: "SELECT USER FROM dual" }
+    - { name: users, command: "SELECT USERNAME FROM ALL_USERS" }
+    - { name: roles, command: "SELECT granted_role FROM user_role_privs" }
+    - { name: privileges, command: "SELECT privilege FROM user_sys_privs" }
+- name: default
+  protocol: postgres
+  actions:
+    - { name: version, command: "SELECT version()" }
+    - { name: current user, command: "SELECT current_user, usesuper FROM pg_user WHERE usename = current_user" }
+    - { name: databases, command: "SELECT datname FROM pg_database" }
+    - { name: roles, command: "SELECT rolname, rolsuper, rolcreaterole, rolcreatedb FROM pg_roles" }
+- name: default
+  protocol: redis
+  actions:
+    - { name: server, command: "INFO server" }
+    - { name: keyspace, command: "INFO keyspace" }
+    - { name: replication, command: "INFO replication" }
+    - { name: persistence, command: "INFO persistence" }
+    - { name: dir, command: "CONFIG GET dir" }
+    - { name: dbfilename, command: "CONFIG GET dbfilename" }
+- name: default
+  protocol: ssh
+  actions:
+    - { name: id, command: "id" }
+    - { name: uname, command: "uname -a" }
+    - { name: hostname, command: "hostname" }
+    - { name: interfaces, command: "ip a" }
+    - { name: sudo, command: "sudo -n -l" }
+- name: default
+  protocol: mongodb
+  actions:
+    - { name: version, command: "buildInfo" }
+    - { name: connection, command: "connectionStatus" }
+    - { name: databases, command: "listDatabases" }
+- name: default
+  protocol: ftp
+  actions:
+    - { name: pwd, command: "PWD" }
+    - { name: list, command: "LIST /" }
+- name: default
+  protocol: memcached
+  actions:
+    - { name: stats, command: "stats" }
+`
+
+// loadActionProfiles 读取动作配置，文件不存在时写入默认配置
+func loadActionProfiles() ([]ActionProfile, error) {
+	if _, err := os.Stat(actionProfileFile); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(actionProfileFile), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(actionProfileFile, []byte(defaultActionProfileYAML), 0644); err != nil {
+			return nil, err
+		}
+	}
+	data, err := os.ReadFile(actionProfileFile)
+	if err != nil {
+		return nil, err
+	}
+	var profiles []ActionProfile
+	if err := yaml.Unmarshal(data, &profiles); err != nil {
+		return nil, err
+	}
+	for i, profile := range profiles {
+		if _, ok := actionSessions[normalizeActionProtocol(profile.Protocol)]; !ok {
+			return nil, fmt.Errorf("action profile [%d] %s: unsupported protocol %q", i, profile.Name, profile.Protocol)
+		}
+	}
+	return profiles, nil
+}
+
+func normalizeActionProtocol(protocol string) string {
+	protocol = strings.ToLower(protocol)
+	if alias, ok := actionProtocolAlias[protocol]; ok {
+		return alias
+	}
+	return protocol
+}
+
+// ActionProfiles 返回协议可用的动作配置名称
+func (t *Tools) ActionProfiles(protocol string) []string {
+	profiles, err := loadActionProfiles()
+	if err != nil {
+		return nil
+	}
+	protocol = normalizeActionProtocol(protocol)
+	var names []string
+	for _, profile := range profiles {
+		if normalizeActionProtocol(profile.Protocol) == protocol && !containsString(names, profile.Name) {
+			names = append(names, profile.Name)
+		}
+	}
+	return names
+}
+
+// RunActionProfile 登录目标并依次执行动作配置中的命令，单条命令失败不影响后续命令
+func (t *Tools) RunActionProfile(protocol, ip, port, username, password, profile string) []structs.ActionResult {
+	protocol = normalizeActionProtocol(protocol)
+	open, ok := actionSessions[protocol]
+	if !ok {
+		return []structs.ActionResult{{Name: profile, Error: "not support"}}
+	}
+	profiles, err := loadActionProfiles()
+	if err != nil {
+		return []structs.ActionResult{{Name: profile, Error: err.Error()}}
+	}
+	var actions []Action
+	for _, p := range profiles {
+		if normalizeActionProtocol(p.Protocol) == protocol && p.Name == profile {
+			actions = append(actions, p.Actions...)
+		}
+	}
+	if len(actions) == 0 {
+		return []structs.ActionResult{{Name: profile, Error: "profile not found"}}
+	}
+	session, err := open(ip, port, username, password)
+	if err != nil {
+		return []structs.ActionResult{{Name: "login", Error: err.Error()}}
+	}
+	defer session.Close()
+	var results []structs.ActionResult
+	for _, action := range actions {
+		result := structs.ActionResult{Name: action.Name, Command: action.Command}
+		if output, err := session.Execute(action.Command); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Output = output
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+type sqlSession struct {
+	db *sql.DB
+}
+
+func openSqlSession(driver string) sessionOpener {
+	return func(ip, port, username, password string) (actionSession, error) {
+		host := net.JoinHostPort(ip, port)
+		var dataSourceName string
+		switch driver {
+		case "mysql":
+			dataSourceName = fmt.Sprintf("%v:%v@tcp(%v)/mysql?charset=utf8&timeout=%v", username, password, host, actionTimeout)
+		case "mssql":
+			dataSourceName = fmt.Sprintf("server=%s;user id=%s;password=%s;port=%v;encrypt=disable;timeout=%v", ip, username, password, port, actionTimeout)
+		case "oracle":
+			dataSourceName = fmt.Sprintf("oracle://%s:%s@%s/orcl", username, password, host)
+		case "postgres":
+			dataSourceName = fmt.Sprintf("postgres://%v:%v@%v/postgres?sslmode=disable", username, password, host)
+		}
+		db, err := sql.Open(driver, dataSourceName)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] 连接数据库失败: %v", driver, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+		defer cancel()
+		if err = db.PingContext(ctx); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("[%s] 连接数据库失败: %v", driver, err)
+		}
+		return &sqlSession{db: db}, nil
+	}
+}
+
+// Execute 执行查询，结果按列以 | 分隔，首行为列名
+func (s *sqlSession) Execute(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, command)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	lines := []string{strings.Join(columns, " | ")}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		for i := range values {
+			values[i] = new(interface{})
+		}
+		if err := rows.Scan(values...); err != nil {
+			continue
+		}
+		fields := make([]string, len(columns))
+		for i, v := range values {
+			switch value := (*(v.(*interface{}))).(type) {
+			case nil:
+				fields[i] = "NULL"
+			case []byte:
+				fields[i] = string(value)
+			default:
+				fields[i] = fmt.Sprint(value)
+			}
+		}
+		lines = append(lines, strings.Join(fields, " | "))
+	}
+	return strings.Join(lines, "\n"), rows.Err()
+}
+
+func (s *sqlSession) Close() {
+	s.db.Close()
+}
+
+type sshSession struct {
+	client *ssh.Client
+}
+
+func openSshSession(ip, port, username, password string) (actionSession, error) {
+	config := &ssh.ClientConfig{
+		User:            username,
+		Auth:            []ssh.AuthMethod{ssh.Password(password)},
+		Timeout:         actionTimeout,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+	client, err := ssh.Dial("tcp", net.JoinHostPort(ip, port), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial: %v", err)
+	}
+	return &sshSession{client: client}, nil
+}
+
+// Execute 每条命令使用独立的 session，返回标准输出与标准错误
+func (s *sshSession) Execute(command string) (string, error) {
+	session, err := s.client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+	output, err := session.CombinedOutput(command)
+	return string(output), err
+}
+
+func (s *sshSession) Close() {
+	s.client.Close()
+}
+
+type redisSession struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func openRedisSession(ip, port, username, password string) (actionSession, error) {
+	conn, err := portscan.WrapperTcpWithTimeout("tcp", net.JoinHostPort(ip, port), actionTimeout)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	// 未授权访问时密码记为 unauthorized
+	if password != "" && password != "unauthorized" {
+		conn.SetDeadline(time.Now().Add(actionTimeout))
+		authResponse, err := sendRedisCommand(conn, reader, fmt.Sprintf("auth %s\r\n", password))
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if !strings.Contains(authResponse, "+OK") {
+			conn.Close()
+			return nil, errors.New("password is incorrect")
+		}
+	}
+	return &redisSession{conn: conn, reader: reader}, nil
+}
+
+func (s *redisSession) Execute(command string) (string, error) {
+	s.conn.SetDeadline(time.Now().Add(actionTimeout))
+	return sendRedisCommand(s.conn, s.reader, command+"\r\n")
+}
+
+func (s *redisSession) Close() {
+	s.conn.Close()
+}
+
+type memcachedSession struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func openMemcachedSession(ip, port, username, password string) (actionSession, error) {
+	conn, err := portscan.WrapperTcpWithTimeout("tcp", net.JoinHostPort(ip, port), actionTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &memcachedSession{conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+func (s *memcachedSession) Execute(command string) (string, error) {
+	s.conn.SetDeadline(time.Now().Add(actionTimeout))
+	return sendMemcachedCommand(s.conn, s.reader, command+"\n")
+}
+
+func (s *memcachedSession) Close() {
+	s.conn.Close()
+}
+
+// 单个批量字符串或数据块的长度上限，防止异常服务端声明超大长度
+const maxResponseBlock = 16 << 20
+
+// sendRedisCommand 发送命令并按 RESP 协议读取完整的响应，返回原始响应文本
+// 连接在多条命令间复用，必须读完本条响应，否则剩余内容会出现在下一条命令的结果中
+func sendRedisCommand(conn net.Conn, reader *bufio.Reader, command string) (string, error) {
+	if _, err := conn.Write([]byte(command)); err != nil {
+		return "", err
+	}
+	var response strings.Builder
+	err := readRESP(reader, &response)
+	return response.String(), err
+}
+
+// readRESP 读取一个 RESP 值，批量字符串按声明的长度读取，数组逐个读取元素
+func readRESP(reader *bufio.Reader, response *strings.Builder) error {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	response.WriteString(line)
+	if len(line) < 3 {
+		return errors.New("redis: invalid response")
+	}
+	switch line[0] {
+	case '$':
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil || size > maxResponseBlock {
+			return fmt.Errorf("redis: invalid bulk length %q", strings.TrimSpace(line))
+		}
+		// $-1 为空值
+		if size < 0 {
+			return nil
+		}
+		return readBlock(reader, size, response)
+	case '*':
+		count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return fmt.Errorf("redis: invalid array length %q", strings.TrimSpace(line))
+		}
+		for i := 0; i < count; i++ {
+			if err := readRESP(reader, response); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sendMemcachedCommand 发送命令并读取到 END 等结束行，VALUE 数据块按声明的长度读取
+func sendMemcachedCommand(conn net.Conn, reader *bufio.Reader, command string) (string, error) {
+	if _, err := conn.Write([]byte(command)); err != nil {
+		return "", err
+	}
+	var response strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return response.String(), err
+		}
+		response.WriteString(line)
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "STAT", "ITEM":
+			continue
+		case "VALUE":
+			// VALUE <key> <flags> <bytes> [<cas unique>]
+			if len(fields) < 4 {
+				return response.String(), fmt.Errorf("memcached: invalid value line %q", strings.TrimSpace(line))
+			}
+			size, err := strconv.Atoi(fields[3])
+			if err != nil || size < 0 || size > maxResponseBlock {
+				return response.String(), fmt.Errorf("memcached: invalid value length %q", fields[3])
+			}
+			if err := readBlock(reader, size, &response); err != nil {
+				return response.String(), err
+			}
+			continue
+		}
+		// END、VERSION、ERROR 等单行响应均为结束行
+		return response.String(), nil
+	}
+}
+
+// readBlock 读取指定长度的数据及结尾的 \r\n
+func readBlock(reader *bufio.Reader, size int, response *strings.Builder) error {
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return err
+	}
+	response.Write(data)
+	return nil
+}
+
+type mongodbSession struct {
+	client *mongo.Client
+}
+
+func openMongodbSession(ip, port, username, password string) (actionSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancel()
+	clientOpts := options.Client().ApplyURI(fmt.Sprintf("mongodb://%s", net.JoinHostPort(ip, port)))
+	if username != "" && password != "unauthorized" {
+		clientOpts.SetAuth(options.Credential{Username: username, Password: password})
+	}
+	client, err := mongo.Connect(ctx, clientOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %v", err)
+	}
+	if err = client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.TODO())
+		return nil, fmt.Errorf("failed to connect to MongoDB: %v", err)
+	}
+	return &mongodbSession{client: client}, nil
+}
+
+// Execute 在 admin 库执行无参数的管理命令，例如 listDatabases
+func (s *mongodbSession) Execute(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancel()
+	raw, err := s.client.Database("admin").RunCommand(ctx, bson.D{{Key: command, Value: 1}}).Raw()
+	if err != nil {
+		return "", err
+	}
+	return raw.String(), nil
+}
+
+func (s *mongodbSession) Close() {
+	s.client.Disconnect(context.TODO())
+}
+
+type ftpSession struct {
+	conn *ftp.ServerConn
+}
+
+func openFtpSession(ip, port, username, password string) (actionSession, error) {
+	conn, err := ftp.Dial(net.JoinHostPort(ip, port), ftp.DialWithTimeout(actionTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.Login(username, password); err != nil {
+		conn.Quit()
+		return nil, err
+	}
+	return &ftpSession{conn: conn}, nil
+}
+
+// Execute 支持 PWD 与 LIST <path>
+func (s *ftpSession) Execute(command string) (string, error) {
+	verb, arg, _ := strings.Cut(strings.TrimSpace(command), " ")
+	switch strings.ToUpper(verb) {
+	case "PWD":
+		return s.conn.CurrentDir()
+	case "LIST":
+		entries, err := s.conn.List(arg)
+		if err != nil {
+			return "", err
+		}
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name)
+		}
+		return strings.Join(names, "\n"), nil
+	default:
+		return "", fmt.Errorf("unsupported ftp command %q", verb)
+	}
+}
+
+func (s *ftpSession) Close() {
+	s.conn.Quit()
+}
@@ -0,0 +1,241 @@
+package vhost
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"slack-wails/lib/structs"
+	"slack-wails/lib/utils/httputil"
+	"slack-wails/lib/utils/randutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/panjf2000/ants/v2"
+	"github.com/qiwentaidi/clients"
+	"golang.org/x/net/publicsuffix"
+)
+
+// 候选域名来源
+const (
+	SourceSubdomain = "subdomain"
+	SourceCert      = "cert"
+	SourceICP       = "icp"
+	SourceWordlist  = "wordlist"
+	SourceCustom    = "custom"
+)
+
+const (
+	MaxCandidates = 2000 // 单个目标最多尝试的 Host 数量，字典组合的候选优先被截断
+	similarLimit  = 0.9  // 响应体相似度达到该值视为同一页面
+	wildcardLimit = 5    // 超过该数量的候选返回完全相同的页面时视为泛解析，全部丢弃
+)
+
+// 内网常见的虚拟主机前缀，与已知根域名组合，同时作为单标签主机名尝试
+var builtinWords = []string{
+	"admin", "api", "app", "backup", "beta", "cms", "confluence", "console", "crm", "dev", "erp", "git", "gitlab",
+	"grafana", "harbor", "hr", "internal", "intranet", "jenkins", "jira", "k8s", "kibana", "local", "localhost",
+	"mail", "manage", "monitor", "nacos", "nexus", "oa", "ops", "portal", "pre", "prometheus", "sso", "staging",
+	"test", "uat", "vpn", "wiki", "www", "zabbix",
+}
+
+func normalize(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if strings.Contains(domain, "://") {
+		if u, err := url.Parse(domain); err == nil {
+			domain = u.Hostname()
+		}
+	}
+	domain = strings.TrimPrefix(strings.TrimSuffix(domain, "."), "*.")
+	if domain == "" || net.ParseIP(domain) != nil || strings.ContainsAny(domain, " /:") {
+		return ""
+	}
+	return domain
+}
+
+// Expand 合并本次任务收集的候选域名(域名 -> 来源)与自定义输入，不含点的输入与内置前缀一起视为字典单词，与各根域名组合
+func Expand(known map[string]string, custom []string) map[string]string {
+	result := make(map[string]string, len(known))
+	for domain, source := range known {
+		if domain = normalize(domain); domain != "" {
+			result[domain] = source
+		}
+	}
+	words := append([]string{}, builtinWords...)
+	for _, item := range custom {
+		item = normalize(item)
+		switch {
+		case item == "":
+		case strings.Contains(item, "."):
+			if _, ok := result[item]; !ok {
+				result[item] = SourceCustom
+			}
+		default:
+			words = append(words, item)
+		}
+	}
+	roots := make(map[string]bool)
+	for domain := range result {
+		if root, err := publicsuffix.EffectiveTLDPlusOne(domain); err == nil {
+			roots[root] = true
+		}
+	}
+	for _, word := range words {
+		if _, ok := result[word]; !ok {
+			result[word] = SourceWordlist
+		}
+		for root := range roots {
+			if _, ok := result[word+"."+root]; !ok {
+				result[word+"."+root] = SourceWordlist
+			}
+		}
+	}
+	return result
+}
+
+type page struct {
+	status   int
+	length   int
+	title    string
+	location string
+	body     string
+}
+
+func fetch(target, host string, headers map[string]string, client *resty.Client) *page {
+	reqHeaders := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		reqHeaders[k] = v
+	}
+	if host != "" {
+		reqHeaders["Host"] = host
+	}
+	resp, err := clients.DoRequest("GET", target, reqHeaders, nil, 10, client)
+	// 禁止跳转的客户端在遇到跳转时同时返回响应与错误
+	if resp == nil || resp.RawResponse == nil {
+		return nil
+	}
+	if err != nil && resp.Header().Get("Location") == "" {
+		return nil
+	}
+	body := string(resp.Body())
+	p := &page{
+		status: resp.StatusCode(),
+		length: len(body),
+		title:  clients.GetTitle(resp.Body()),
+		body:   body,
+	}
+	// 跳转地址中通常包含请求的 Host，替换后再比较
+	p.location = resp.Header().Get("Location")
+	if host != "" {
+		p.location = strings.ReplaceAll(p.location, host, "{host}")
+		p.body = strings.ReplaceAll(p.body, host, "{host}")
+	}
+	return p
+}
+
+// same 判断两个响应是否为同一页面
+func same(a, b *page) bool {
+	if a.status != b.status || a.location != b.location || a.title != b.title {
+		return false
+	}
+	if a.body == b.body {
+		return true
+	}
+	return httputil.JaccardSimilarity(a.body, b.body) >= similarLimit
+}
+
+// Discover 对 IP 形式的网站目标依次替换 Host 头，与默认站点及不存在的域名的响应都不同时视为独立的虚拟主机
+func Discover(ctx context.Context, target string, hosts map[string]string, headers map[string]string, client *resty.Client, thread int) []structs.VHostResult {
+	u, err := url.Parse(target)
+	if err != nil || net.ParseIP(u.Hostname()) == nil {
+		return nil
+	}
+	baselines := []*page{
+		fetch(target, "", headers, client),
+		fetch(target, randutil.RandLetters(12)+".invalid", headers, client),
+	}
+	if baselines[0] == nil && baselines[1] == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(hosts))
+	for name := range hosts {
+		names = append(names, name)
+	}
+	// 已知域名优先于字典组合
+	sort.Slice(names, func(i, j int) bool {
+		if (hosts[names[i]] == SourceWordlist) != (hosts[names[j]] == SourceWordlist) {
+			return hosts[names[j]] == SourceWordlist
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > MaxCandidates {
+		names = names[:MaxCandidates]
+	}
+
+	var (
+		mutex   sync.Mutex
+		wg      sync.WaitGroup
+		results []structs.VHostResult
+		bodies  = make(map[string]int)
+		hashes  = make(map[string]string)
+	)
+	pool, _ := ants.NewPoolWithFunc(thread, func(arg interface{}) {
+		defer wg.Done()
+		name := arg.(string)
+		if ctx.Err() != nil {
+			return
+		}
+		p := fetch(target, name, headers, client)
+		// 400/421 为服务器拒绝该 Host
+		if p == nil || p.status == 400 || p.status == 421 {
+			return
+		}
+		for _, base := range baselines {
+			if base != nil && same(p, base) {
+				return
+			}
+		}
+		vhostURL := *u
+		vhostURL.Host = name
+		if port := u.Port(); port != "" {
+			vhostURL.Host = net.JoinHostPort(name, port)
+		}
+		sum := md5.Sum([]byte(p.body))
+		hash := hex.EncodeToString(sum[:])
+		mutex.Lock()
+		defer mutex.Unlock()
+		bodies[hash]++
+		hashes[name] = hash
+		results = append(results, structs.VHostResult{
+			URL:        vhostURL.String(),
+			Address:    target,
+			Host:       name,
+			StatusCode: p.status,
+			Length:     p.length,
+			Title:      p.title,
+			Source:     hosts[name],
+		})
+	})
+	defer pool.Release()
+	for _, name := range names {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		pool.Invoke(name)
+	}
+	wg.Wait()
+
+	filtered := results[:0]
+	for _, r := range results {
+		if bodies[hashes[r.Host]] <= wildcardLimit {
+			filtered = append(filtered, r)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Host < filtered[j].Host })
+	return filtered
+}
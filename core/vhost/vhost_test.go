@@ -0,0 +1,68 @@
+package vhost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qiwentaidi/clients"
+)
+
+func TestDiscover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := strings.Cut(r.Host, ":")
+		switch {
+		case host == "oa.example.com":
+			fmt.Fprint(w, "<html><title>OA 办公系统</title><body>统一身份认证 用户名 密码 登录</body></html>")
+		case host == "gitlab.example.com":
+			http.Redirect(w, r, "/users/sign_in", http.StatusFound)
+		case strings.HasPrefix(host, "wild"):
+			// 泛匹配的站点返回相同页面
+			fmt.Fprint(w, "<html><title>wildcard</title></html>")
+		default:
+			fmt.Fprintf(w, "<html><title>Welcome to nginx!</title><body>default server %s</body></html>", host)
+		}
+	}))
+	defer server.Close()
+
+	hosts := map[string]string{
+		"oa.example.com":     SourceICP,
+		"gitlab.example.com": SourceCert,
+		"www.example.com":    SourceSubdomain,
+	}
+	for i := 0; i < 10; i++ {
+		hosts[fmt.Sprintf("wild%d.example.com", i)] = SourceWordlist
+	}
+	results := Discover(context.Background(), server.URL, hosts, nil, clients.NewRestyClient(nil, false), 5)
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Host+"/"+r.Source)
+	}
+	if strings.Join(got, ",") != "gitlab.example.com/cert,oa.example.com/icp" {
+		t.Errorf("Discover() = %v", got)
+	}
+	if len(results) > 0 && (results[0].Address != server.URL || !strings.HasPrefix(results[0].URL, "http://gitlab.example.com:")) {
+		t.Errorf("Discover() = %+v", results[0])
+	}
+}
+
+func TestExpand(t *testing.T) {
+	hosts := Expand(map[string]string{"www.example.com.cn": SourceSubdomain}, []string{"*.corp.example.org", "hrms", "10.0.0.1"})
+	for name, source := range map[string]string{
+		"www.example.com.cn":  SourceSubdomain,
+		"corp.example.org":    SourceCustom,
+		"hrms.example.com.cn": SourceWordlist,
+		"oa.example.org":      SourceWordlist,
+		"hrms":                SourceWordlist,
+	} {
+		if hosts[name] != source {
+			t.Errorf("Expand()[%s] = %q, want %q", name, hosts[name], source)
+		}
+	}
+	if _, ok := hosts["10.0.0.1"]; ok {
+		t.Error("Expand() should ignore IP addresses")
+	}
+}
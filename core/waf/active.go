@@ -0,0 +1,172 @@
+package waf
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/qiwentaidi/clients"
+)
+
+// 识别到 WAF 的主机降低扫描速率，避免触发封禁
+const (
+	ThrottledRateLimit = 10 // nuclei 每秒请求数上限
+	ThrottledWorkers   = 3  // dirsearch 线程数上限
+	ThrottledInterval  = 1  // dirsearch 每个请求的最小间隔(秒)
+)
+
+// 主动探测时附加的攻击特征参数，只用于触发 WAF 拦截，不包含有效利用载荷
+var probePayloads = []string{
+	"id=1%27%20AND%201=1%20UNION%20SELECT%201,2,3--",
+	"q=%3Cscript%3Ealert(1)%3C/script%3E",
+	"file=../../../../etc/passwd",
+	"cmd=;cat%20/etc/passwd",
+}
+
+// 常见的拦截状态码，无法匹配厂商特征时用于判断通用 WAF
+var blockStatus = map[int]bool{403: true, 405: true, 406: true, 418: true, 419: true, 493: true, 501: true, 999: true}
+
+// 厂商特征，Body 只匹配攻击请求中与正常请求不同的响应，Headers 值为空时只要求存在该响应头
+type signature struct {
+	Name    string
+	Body    []string
+	Headers map[string]string
+	Cookies []string // Cookie 名称前缀
+}
+
+var signatures = []signature{
+	{Name: "safeline", Body: []string{"safeline", "长亭科技", "<!-- event_id:"}, Headers: map[string]string{"server": "safeline"}},
+	{Name: "aliyun", Body: []string{"errors.aliyun.com", "error.aliyun.com", "aliyun_waf"}, Cookies: []string{"aliyungf_tc", "acw_tc"}},
+	{Name: "tencent", Body: []string{"waf.tencent-cloud.com", "腾讯t-sec", "tencent cloud waf"}},
+	{Name: "chuangyudun", Body: []string{"365cyd", "创宇盾"}},
+	{Name: "knownsec", Body: []string{"jiasule", "知道创宇"}, Headers: map[string]string{"x-via-jsl": ""}, Cookies: []string{"__jsluid", "jsl_tracking"}},
+	{Name: "qianxin", Body: []string{"wangzhan.360.cn", "/wzws-waf-cgi/", "360wzws", "奇安信网站卫士"}, Headers: map[string]string{"server": "360wzws", "x-powered-by-360wzb": ""}},
+	{Name: "cloudflare", Body: []string{"attention required! | cloudflare", "cloudflare ray id"}, Headers: map[string]string{"cf-ray": "", "server": "cloudflare"}, Cookies: []string{"__cf_bm", "__cfduid"}},
+	{Name: "modsecurity", Body: []string{"mod_security", "modsecurity", "not acceptable!"}, Headers: map[string]string{"server": "mod_security"}},
+	{Name: "safedog", Body: []string{"safedog", "安全狗"}, Headers: map[string]string{"server": "safedog", "x-powered-by": "waf/2.0"}, Cookies: []string{"safedog-flow-item"}},
+	{Name: "yunsuo", Body: []string{"yunsuo_session", "云锁"}, Cookies: []string{"yunsuo_session"}},
+	{Name: "huaweicloud", Body: []string{"hwclouds.com", "huaweicloud"}, Headers: map[string]string{"server": "huaweicloudwaf"}, Cookies: []string{"hwwafsesid"}},
+	{Name: "baiduyun", Body: []string{"yunjiasu"}, Headers: map[string]string{"server": "yunjiasu"}},
+	{Name: "d-shield", Body: []string{"d盾_拦截提示", "d_safe"}},
+	{Name: "xuanwudun", Body: []string{"玄武盾", "dbappwaf"}},
+	{Name: "f5-asm", Body: []string{"the requested url was rejected. please consult with your administrator."}, Cookies: []string{"TS01"}},
+	{Name: "imperva", Body: []string{"incapsula incident id"}, Headers: map[string]string{"x-iinfo": ""}, Cookies: []string{"incap_ses", "visid_incap"}},
+	{Name: "akamai", Headers: map[string]string{"server": "akamaighost"}},
+}
+
+// 本次网站扫描识别到 WAF 的主机，供漏洞扫描与之后的目录扫描降低速率，每次网站扫描开始时清空
+var detected sync.Map
+
+// Reset 清空上一次扫描的识别结果
+func Reset() {
+	detected.Clear()
+}
+
+// Remember 记录主机存在 WAF
+func Remember(host, name string) {
+	if host != "" && name != "" {
+		detected.Store(strings.ToLower(host), name)
+	}
+}
+
+// Detected 查询链接所在主机是否已识别到 WAF
+func Detected(rawURL string) (string, bool) {
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	name, ok := detected.Load(strings.ToLower(host))
+	if !ok {
+		return "", false
+	}
+	return name.(string), true
+}
+
+type probeResponse struct {
+	status  int
+	body    string
+	headers map[string]string
+	cookies []string
+}
+
+func probe(target string, headers map[string]string, client *resty.Client) *probeResponse {
+	resp, err := clients.DoRequest("GET", target, headers, nil, 10, client)
+	if err != nil {
+		return nil
+	}
+	r := &probeResponse{
+		status:  resp.StatusCode(),
+		body:    strings.ToLower(string(resp.Body())),
+		headers: make(map[string]string),
+	}
+	for name, values := range resp.Header() {
+		r.headers[strings.ToLower(name)] = strings.ToLower(strings.Join(values, ","))
+	}
+	for _, cookie := range resp.Cookies() {
+		r.cookies = append(r.cookies, strings.ToLower(cookie.Name))
+	}
+	return r
+}
+
+// ActiveDetect 发送一次正常请求与若干攻击特征请求，根据拦截页面、状态码、Cookie 与响应头识别 WAF
+func ActiveDetect(target string, headers map[string]string, client *resty.Client) *WAF {
+	baseline := probe(target, headers, client)
+	if baseline == nil {
+		return &WAF{}
+	}
+	if name := matchSignature(baseline, nil); name != "" {
+		return &WAF{Exsits: true, Name: name}
+	}
+	sep := "?"
+	if strings.Contains(target, "?") {
+		sep = "&"
+	}
+	blocked := 0
+	for _, payload := range probePayloads {
+		r := probe(target+sep+payload, headers, client)
+		if r == nil {
+			// 攻击请求被直接断开连接，同样视为拦截
+			blocked++
+			continue
+		}
+		if name := matchSignature(r, baseline); name != "" {
+			return &WAF{Exsits: true, Name: name}
+		}
+		if blockStatus[r.status] && !blockStatus[baseline.status] {
+			blocked++
+		}
+	}
+	if blocked >= 2 {
+		return &WAF{Exsits: true, Name: "unknown"}
+	}
+	return &WAF{}
+}
+
+// matchSignature baseline 不为空时，响应体中已在正常页面出现的特征不计入
+func matchSignature(r, baseline *probeResponse) string {
+	for _, sig := range signatures {
+		for name, value := range sig.Headers {
+			if v, ok := r.headers[name]; ok && strings.Contains(v, value) {
+				return sig.Name
+			}
+		}
+		for _, prefix := range sig.Cookies {
+			for _, cookie := range r.cookies {
+				if strings.HasPrefix(cookie, strings.ToLower(prefix)) {
+					return sig.Name
+				}
+			}
+		}
+		if baseline == nil || r.body == baseline.body {
+			continue
+		}
+		for _, keyword := range sig.Body {
+			keyword = strings.ToLower(keyword)
+			if strings.Contains(r.body, keyword) && !strings.Contains(baseline.body, keyword) {
+				return sig.Name
+			}
+		}
+	}
+	return ""
+}
@@ -1,8 +1,65 @@
 package waf
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/qiwentaidi/clients"
 )
 
 func TestIsWAF(t *testing.T) {
 }
+
+func TestActiveDetect(t *testing.T) {
+	client := clients.NewRestyClient(nil, false)
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{"safeline", func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(strings.ToLower(r.URL.RawQuery), "union") {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte("<html><title>请求已被拦截</title><!-- event_id: 1a2b --> SafeLine</html>"))
+				return
+			}
+			w.Write([]byte("<html>welcome</html>"))
+		}, "safeline"},
+		{"cookie", func(w http.ResponseWriter, r *http.Request) {
+			http.SetCookie(w, &http.Cookie{Name: "aliyungf_tc", Value: "1"})
+			w.Write([]byte("ok"))
+		}, "aliyun"},
+		{"generic", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.RawQuery != "" {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			w.Write([]byte("ok"))
+		}, "unknown"},
+		// 正常页面本身包含厂商关键字时不应误报
+		{"none", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("<html>安全狗 safedog 合作伙伴</html>"))
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			got := ActiveDetect(server.URL, nil, client)
+			if got.Name != tt.want || got.Exsits != (tt.want != "") {
+				t.Errorf("ActiveDetect() = %+v, want %q", got, tt.want)
+			}
+		})
+	}
+
+	Remember("Example.com", "safeline")
+	if name, ok := Detected("https://example.com:8443/login"); !ok || name != "safeline" {
+		t.Errorf("Detected() = %q, %v", name, ok)
+	}
+	Reset()
+	if _, ok := Detected("https://example.com/"); ok {
+		t.Error("Detected() should be empty after Reset()")
+	}
+}
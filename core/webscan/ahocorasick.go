@@ -0,0 +1,109 @@
+package webscan
+
+// keywordMatcher 基于 Aho-Corasick 自动机，一次遍历文本即可得到全部命中的关键字
+type keywordMatcher struct {
+	patterns []string
+	index    map[string]int
+	nodes    []acNode
+}
+
+type acNode struct {
+	next   map[byte]int32
+	fail   int32
+	dict   int32 // 沿失败链最近的带输出节点，-1 表示没有
+	output int32 // 在该节点结束的关键字编号，-1 表示没有
+}
+
+func newKeywordMatcher() *keywordMatcher {
+	return &keywordMatcher{index: make(map[string]int)}
+}
+
+func newACNode() acNode {
+	return acNode{next: make(map[byte]int32), dict: -1, output: -1}
+}
+
+// add 登记关键字并返回编号，相同关键字共用一个编号
+func (m *keywordMatcher) add(keyword string) int {
+	if id, ok := m.index[keyword]; ok {
+		return id
+	}
+	id := len(m.patterns)
+	m.patterns = append(m.patterns, keyword)
+	m.index[keyword] = id
+	return id
+}
+
+// build 在全部关键字登记完成后构建 trie 与失败链
+func (m *keywordMatcher) build() {
+	m.nodes = []acNode{newACNode()}
+	for id, pattern := range m.patterns {
+		var cur int32
+		for i := 0; i < len(pattern); i++ {
+			nxt, ok := m.nodes[cur].next[pattern[i]]
+			if !ok {
+				nxt = int32(len(m.nodes))
+				m.nodes = append(m.nodes, newACNode())
+				m.nodes[cur].next[pattern[i]] = nxt
+			}
+			cur = nxt
+		}
+		m.nodes[cur].output = int32(id)
+	}
+
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for c, child := range m.nodes[cur].next {
+			fail := m.nodes[cur].fail
+			for {
+				if nxt, ok := m.nodes[fail].next[c]; ok {
+					fail = nxt
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			m.nodes[child].fail = fail
+			if m.nodes[fail].output >= 0 {
+				m.nodes[child].dict = fail
+			} else {
+				m.nodes[child].dict = m.nodes[fail].dict
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// match 返回按关键字编号索引的命中结果
+func (m *keywordMatcher) match(text string) []bool {
+	hits := make([]bool, len(m.patterns))
+	if len(m.nodes) == 0 {
+		return hits
+	}
+	var cur int32
+	for i := 0; i < len(text); i++ {
+		for {
+			if nxt, ok := m.nodes[cur].next[text[i]]; ok {
+				cur = nxt
+				break
+			}
+			if cur == 0 {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		if out := m.nodes[cur].output; out >= 0 {
+			hits[out] = true
+		}
+		for d := m.nodes[cur].dict; d >= 0; d = m.nodes[d].dict {
+			hits[m.nodes[d].output] = true
+		}
+	}
+	return hits
+}
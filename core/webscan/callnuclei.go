@@ -2,6 +2,8 @@ package webscan
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -9,11 +11,13 @@ import (
 	"path/filepath"
 	"runtime/debug"
 	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"slack-wails/lib/utils/arrayutil"
 	"slack-wails/lib/utils/httputil"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/qiwentaidi/clients"
 
@@ -49,38 +53,23 @@ func NewNucleiEngine(ctx, ctrlCtx context.Context, taskId string, allOptions []s
 		gologger.DualLog(ctx, gologger.Level_INFO, fmt.Sprintf("[nuclei] check vuln: %s", o.URL))
 		ne.LoadTargets([]string{o.URL}, false)
 		err = ne.ExecuteWithCallback(func(event *output.ResultEvent) {
-			gologger.DualLog(ctx, gologger.Level_Success, fmt.Sprintf("[%s] [%s] %s", event.TemplateID, event.Info.SeverityHolder.Severity.String(), event.Matched))
-			var reference string
-			if event.Info.Reference != nil && !event.Info.Reference.IsEmpty() {
-				reference = strings.Join(event.Info.Reference.ToSlice(), ",")
-			}
-			runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
-				TaskId:       taskId,
-				ID:           event.TemplateID,
-				Name:         event.Info.Name,
-				Description:  event.Info.Description,
-				Reference:    reference,
-				URL:          showMatched(event),
-				Request:      showRequest(event),
-				Response:     showResponse(event),
-				ResponseTime: limitDecimalPlaces(event.ResponseTime),
-				Extract:      strings.Join(event.ExtractedResults, " | "),
-				Type:         strings.ToUpper(event.Type),
-				Severity:     strings.ToUpper(event.Info.SeverityHolder.Severity.String()),
-			})
+			saveNucleiResult(ctx, taskId, event)
 		})
 		if err != nil {
 			gologger.DualLog(ctx, gologger.Level_ERROR, fmt.Sprintf("[nuclei] execute callback err: %v", err))
 			return
 		}
 		defer ne.Close()
+		if len(o.FuzzRequests) > 0 && ctrlCtx.Err() == nil {
+			dastScan(ctx, taskId, o)
+		}
 		runtime.EventsEmit(ctx, "NucleiProgressID", i+1)
 	}
 }
 
 func NewThreadSafeNucleiEngine(ctx, ctrlCtx context.Context, taskId string, allOptions []structs.NucleiOption) {
 	count := len(allOptions)
-	ne, err := nuclei.NewThreadSafeNucleiEngineCtx(context.Background())
+	ne, err := nuclei.NewThreadSafeNucleiEngineCtx(context.Background(), threadSafeEngineOptions(allOptions)...)
 	if err != nil {
 		gologger.DualLog(ctx, gologger.Level_ERROR, fmt.Sprintf("[nuclei] init engine err: %v", err))
 		return
@@ -93,32 +82,14 @@ func NewThreadSafeNucleiEngine(ctx, ctrlCtx context.Context, taskId string, allO
 	}
 	gologger.DualLog(ctx, gologger.Level_INFO, fmt.Sprintf("[nuclei] loading %d targets to scan", count))
 	ne.GlobalResultCallback(func(event *output.ResultEvent) {
-		gologger.DualLog(ctx, gologger.Level_Success, fmt.Sprintf("[%s] [%s] %s", event.TemplateID, event.Info.SeverityHolder.Severity.String(), event.Matched))
-		var reference string
-		if event.Info.Reference != nil && !event.Info.Reference.IsEmpty() {
-			reference = strings.Join(event.Info.Reference.ToSlice(), ",")
-		}
-		runtime.EventsEmit(ctx, "nucleiResult", structs.VulnerabilityInfo{
-			TaskId:       taskId,
-			ID:           event.TemplateID,
-			Name:         event.Info.Name,
-			Description:  event.Info.Description,
-			Reference:    reference,
-			URL:          showMatched(event),
-			Request:      showRequest(event),
-			Response:     showResponse(event),
-			ResponseTime: limitDecimalPlaces(event.ResponseTime),
-			Extract:      strings.Join(event.ExtractedResults, " | "),
-			Type:         strings.ToUpper(event.Type),
-			Severity:     strings.ToUpper(event.Info.SeverityHolder.Severity.String()),
-		})
+		saveNucleiResult(ctx, taskId, event)
 	})
 
 	// 提交扫描任务
 	for _, option := range allOptions {
 		if ctrlCtx.Err() != nil {
 			gologger.Warning(ctx, "User exits vulnerability scanning")
-			return
+			break
 		}
 		sg.Add()
 		// 当URL目标为WEB时，如果无指纹以及开启跳过时，则跳过该URL
@@ -131,7 +102,7 @@ func NewThreadSafeNucleiEngine(ctx, ctrlCtx context.Context, taskId string, allO
 			gologger.DualLog(ctx, gologger.Level_INFO, fmt.Sprintf("[nuclei] %s is not web and does not have tags, scan skipped", option.URL))
 			continue
 		}
-		sdkOpt := NewNucleiSDKOptions(option)
+		sdkOpt := NewThreadSafeNucleiSDKOptions(option)
 		go func(url string, Opts []nuclei.NucleiSDKOptions) {
 			defer sg.Done()
 			defer func() {
@@ -150,12 +121,137 @@ func NewThreadSafeNucleiEngine(ctx, ctrlCtx context.Context, taskId string, allO
 			err := ne.ExecuteNucleiWithOpts([]string{url}, Opts...)
 			if err != nil {
 				gologger.DualLog(ctx, gologger.Level_ERROR, fmt.Sprintf("[nuclei] execute callback err: %v", err))
-				return
 			}
 		}(option.URL, sdkOpt)
 	}
 	sg.Wait()
+	ne.Close()
+
+	// 多线程引擎只接受链接作为目标，无法传入带请求体的请求，参数模糊测试在扫描结束后逐个执行
+	for _, option := range allOptions {
+		if ctrlCtx.Err() != nil {
+			return
+		}
+		if len(option.FuzzRequests) > 0 && !(option.SkipNucleiWithoutTags && len(option.Tags) == 0) {
+			dastScan(ctx, taskId, option)
+		}
+	}
+}
+
+// threadSafeEngineOptions 多线程引擎的全局配置，HTTP 客户端由全部扫描共享，代理只能在创建引擎时设置
+func threadSafeEngineOptions(allOptions []structs.NucleiOption) []nuclei.NucleiSDKOptions {
+	options := []nuclei.NucleiSDKOptions{
+		nuclei.DisableUpdateCheck(),
+	}
+	for _, o := range allOptions {
+		if o.Proxy != "" {
+			return append(options, nuclei.WithGlobalProxy([]string{o.Proxy}, false))
+		}
+	}
+	return options
+}
+
+// saveNucleiResult 保存并推送漏洞结果
+func saveNucleiResult(ctx context.Context, taskId string, event *output.ResultEvent) {
+	gologger.DualLog(ctx, gologger.Level_Success, fmt.Sprintf("[%s] [%s] %s", event.TemplateID, event.Info.SeverityHolder.Severity.String(), event.Matched))
+	var reference string
+	if event.Info.Reference != nil && !event.Info.Reference.IsEmpty() {
+		reference = strings.Join(event.Info.Reference.ToSlice(), ",")
+	}
+	resultstore.Vulnerability(ctx, structs.VulnerabilityInfo{
+		TaskId:       taskId,
+		ID:           event.TemplateID,
+		Name:         event.Info.Name,
+		Description:  event.Info.Description,
+		Reference:    reference,
+		URL:          showMatched(event),
+		Request:      showRequest(event),
+		Response:     showResponse(event),
+		ResponseTime: limitDecimalPlaces(event.ResponseTime),
+		Extract:      strings.Join(event.ExtractedResults, " | "),
+		Type:         strings.ToUpper(event.Type),
+		Severity:     strings.ToUpper(event.Info.SeverityHolder.Severity.String()),
+	})
+}
+
+// dastScan 使用 DAST 模板对爬虫发现的带参数请求进行参数模糊测试
+func dastScan(ctx context.Context, taskId string, o structs.NucleiOption) {
+	requestFile, err := writeFuzzRequests(o.FuzzRequests)
+	if err != nil {
+		gologger.DualLog(ctx, gologger.Level_ERROR, fmt.Sprintf("[nuclei] write dast requests err: %v", err))
+		return
+	}
+	defer os.Remove(requestFile)
+	ne, err := nuclei.NewNucleiEngineCtx(context.Background(), NewNucleiDASTOptions(o)...)
+	if err != nil {
+		gologger.DualLog(ctx, gologger.Level_ERROR, fmt.Sprintf("[nuclei] init dast engine err: %v", err))
+		return
+	}
 	defer ne.Close()
+	gologger.DualLog(ctx, gologger.Level_INFO, fmt.Sprintf("[nuclei] fuzzing %d crawled requests of %s", len(o.FuzzRequests), o.URL))
+	if err = ne.LoadTargetsWithHttpData(requestFile, "jsonl"); err != nil {
+		gologger.DualLog(ctx, gologger.Level_ERROR, fmt.Sprintf("[nuclei] load dast requests err: %v", err))
+		return
+	}
+	err = ne.ExecuteWithCallback(func(event *output.ResultEvent) {
+		saveNucleiResult(ctx, taskId, event)
+	})
+	if err != nil && !errors.Is(err, nuclei.ErrNoTemplatesAvailable) {
+		gologger.DualLog(ctx, gologger.Level_ERROR, fmt.Sprintf("[nuclei] dast execute err: %v", err))
+	}
+}
+
+// writeFuzzRequests 将模糊测试请求写入 proxify JSONL 格式的临时文件，作为 nuclei 的请求输入
+func writeFuzzRequests(requests []structs.FuzzRequest) (string, error) {
+	file, err := os.CreateTemp("", "slack-dast-*.jsonl")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	for _, request := range requests {
+		line := proxifyRequest{URL: request.URL}
+		line.Request.Raw = request.Raw
+		if err = encoder.Encode(line); err != nil {
+			os.Remove(file.Name())
+			return "", err
+		}
+	}
+	return file.Name(), nil
+}
+
+type proxifyRequest struct {
+	URL     string `json:"url"`
+	Request struct {
+		Raw string `json:"raw"`
+	} `json:"request"`
+}
+
+// NewNucleiDASTOptions 参数模糊测试的配置，只会加载模板目录中的 DAST 模板
+func NewNucleiDASTOptions(o structs.NucleiOption) []nuclei.NucleiSDKOptions {
+	options := []nuclei.NucleiSDKOptions{
+		nuclei.DisableUpdateCheck(),
+		nuclei.DASTMode(),
+		nuclei.WithTemplatesOrWorkflows(nuclei.TemplateSources{
+			Templates: expandYamlFiles(o.TemplateFolders),
+		}),
+	}
+	if o.CustomHeaders != "" {
+		options = append(options, nuclei.WithHeaders(clients.Str2HeaderList(o.CustomHeaders)))
+	}
+	if o.Proxy != "" {
+		options = append(options, nuclei.WithProxy([]string{o.Proxy}, false))
+	}
+	if o.RateLimit > 0 {
+		options = append(options, nuclei.WithGlobalRateLimitCtx(context.Background(), o.RateLimit, time.Second))
+	}
+	return options
+}
+
+// NewThreadSafeNucleiSDKOptions 多线程引擎中单个目标的配置，代理已在创建引擎时设置，传入 WithProxy 会返回 ErrOptionsNotSupported
+func NewThreadSafeNucleiSDKOptions(o structs.NucleiOption) []nuclei.NucleiSDKOptions {
+	o.Proxy = ""
+	return NewNucleiSDKOptions(o)
 }
 
 func NewNucleiSDKOptions(o structs.NucleiOption) []nuclei.NucleiSDKOptions {
@@ -177,8 +273,13 @@ func NewNucleiSDKOptions(o structs.NucleiOption) []nuclei.NucleiSDKOptions {
 		// options = append(options, nuclei.WithTemplateFilters(nuclei.TemplateFilters{
 		// 	Tags: finalTags(o.Tags, o.CustomTags),
 		// }))
+		templates := findTagsFile(finalTags(o.Tags, o.CustomTags), o.TemplateFolders)
+		// 自定义标签时以用户选择为准，否则根据指纹版本增减 CVE 模板
+		if len(o.CustomTags) == 0 {
+			templates = steerCVETemplates(templates, o.CVETemplates, o.SkipTemplates, o.TemplateFolders)
+		}
 		options = append(options, nuclei.WithTemplatesOrWorkflows(nuclei.TemplateSources{
-			Templates: findTagsFile(finalTags(o.Tags, o.CustomTags), o.TemplateFolders),
+			Templates: templates,
 		}))
 	} else {
 		// 指定poc文件的时候就要删除tags标签
@@ -189,6 +290,9 @@ func NewNucleiSDKOptions(o structs.NucleiOption) []nuclei.NucleiSDKOptions {
 	if o.Proxy != "" {
 		options = append(options, nuclei.WithProxy([]string{o.Proxy}, false)) // -proxy
 	}
+	if o.RateLimit > 0 {
+		options = append(options, nuclei.WithGlobalRateLimitCtx(context.Background(), o.RateLimit, time.Second)) // -rl
+	}
 	return options
 }
 
@@ -223,6 +327,33 @@ func findTagsFile(inputTags, templateDirs []string) []string {
 	return arrayutil.RemoveDuplicates(fileList)
 }
 
+// steerCVETemplates 追加指纹版本命中的 CVE 模板，并移除版本明确不受影响的 CVE 模板
+func steerCVETemplates(files, include, skip, templateDirs []string) []string {
+	skipped := make(map[string]bool)
+	for _, name := range skip {
+		skipped[strings.ToLower(name)] = true
+	}
+	for _, name := range include {
+		delete(skipped, strings.ToLower(name))
+	}
+	var result []string
+	for _, file := range files {
+		if !skipped[strings.ToLower(strings.TrimSuffix(filepath.Base(file), ".yaml"))] {
+			result = append(result, file)
+		}
+	}
+	for _, name := range include {
+		for _, dir := range templateDirs {
+			templateFile := path.Join(dir, name+".yaml")
+			if _, err := os.Stat(templateFile); err == nil {
+				result = append(result, templateFile)
+				break
+			}
+		}
+	}
+	return arrayutil.RemoveDuplicates(result)
+}
+
 func expandYamlFiles(dirs []string) []string {
 	var files []string
 	for _, dir := range dirs {
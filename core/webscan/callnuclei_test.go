@@ -3,7 +3,11 @@ package webscan
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"slack-wails/core/subdomain"
 	"slack-wails/core/waf"
 	"slack-wails/lib/gologger"
@@ -12,12 +16,15 @@ import (
 	"slack-wails/lib/utils/httputil"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/qiwentaidi/clients"
 
 	"github.com/panjf2000/ants/v2"
 	nuclei "github.com/projectdiscovery/nuclei/v3/lib"
+	jsonformat "github.com/projectdiscovery/nuclei/v3/pkg/input/formats/json"
+	"github.com/projectdiscovery/nuclei/v3/pkg/input/types"
 	"github.com/projectdiscovery/nuclei/v3/pkg/output"
 	syncutil "github.com/projectdiscovery/utils/sync"
 )
@@ -182,10 +189,11 @@ func TestFingerscan(t *testing.T) {
 		server = resp.Header().Get("Server")
 		contentType = resp.Header().Get("Content-Type")
 		statusCode = resp.StatusCode()
+		cert, _ := GetTLSInfo(u.Scheme, u.Host, TLSProbeOptions{})
 		web := &WebInfo{
 			HeadeString:   string(rawHeaders),
 			ContentType:   contentType,
-			Cert:          GetTLSString(u.Scheme, u.Host),
+			Cert:          cert,
 			BodyString:    string(body),
 			Path:          u.Path,
 			Title:         title,
@@ -201,7 +209,7 @@ func TestFingerscan(t *testing.T) {
 
 		s.aliveURLs = append(s.aliveURLs, u)
 
-		fingerprints := Scan(s.ctx, web, FingerprintDB)
+		fingerprints := Scan(web, FingerprintDB)
 
 		if s.generateLog4j2 {
 			fingerprints = append(fingerprints, "Generate-Log4j2")
@@ -211,7 +219,7 @@ func TestFingerscan(t *testing.T) {
 			fingerprints = append(fingerprints, "Fastjson")
 		}
 
-		if checkHoneypotWithHeaders(web.HeadeString) || checkHoneypotWithFingerprintLength(len(fingerprints)) {
+		if honeypot := ScoreHoneypot(HoneypotEvidence{Web: web, Fingerprints: len(fingerprints)}); honeypot != nil && honeypot.Score >= HoneypotThreshold {
 			fingerprints = []string{"疑似蜜罐"}
 		}
 
@@ -266,3 +274,91 @@ func TestFullFaviconURL(t *testing.T) {
 	result, _ := GetFaviconFullLink(u, clients.NewRestyClient(nil, true))
 	fmt.Printf("result: %v\n", result)
 }
+
+func TestThreadSafeNucleiProxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "direct")
+	}))
+	defer target.Close()
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host == "" {
+			http.Error(w, "proxy only", http.StatusBadRequest)
+			return
+		}
+		proxied.Add(1)
+		fmt.Fprint(w, "via-proxy")
+	}))
+	defer proxy.Close()
+	template := filepath.Join(t.TempDir(), "proxy-check.yaml")
+	os.WriteFile(template, []byte(`id: proxy-check
+info:
+  name: proxy check
+  author: slack
+  severity: info
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: word
+        words:
+          - via-proxy
+`), 0644)
+	option := structs.NucleiOption{URL: target.URL, TemplateFile: []string{template}, Proxy: proxy.URL}
+
+	ne, err := nuclei.NewThreadSafeNucleiEngineCtx(context.Background(), threadSafeEngineOptions([]structs.NucleiOption{option})...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ne.Close()
+	var matched atomic.Int32
+	ne.GlobalResultCallback(func(event *output.ResultEvent) {
+		if event.TemplateID == "proxy-check" {
+			matched.Add(1)
+		}
+	})
+	// 单个目标传入 WithProxy 时 SDK 拒绝执行
+	if err := ne.ExecuteNucleiWithOpts([]string{target.URL}, NewNucleiSDKOptions(option)...); err == nil || !strings.Contains(err.Error(), "WithProxy") {
+		t.Fatalf("expected WithProxy to be rejected, got %v", err)
+	}
+	if err := ne.ExecuteNucleiWithOpts([]string{target.URL}, NewThreadSafeNucleiSDKOptions(option)...); err != nil {
+		t.Fatalf("ExecuteNucleiWithOpts() returned an error: %v", err)
+	}
+	if proxied.Load() == 0 || matched.Load() == 0 {
+		t.Fatalf("requests should go through the global proxy, proxied: %d, matched: %d", proxied.Load(), matched.Load())
+	}
+}
+
+func TestWriteFuzzRequests(t *testing.T) {
+	raw := "POST /admin/user/save HTTP/1.1\r\nHost: www.example.com\r\nContent-Type: application/x-www-form-urlencoded\r\nContent-Length: 9\r\n\r\nid=1&q=ab"
+	requestFile, err := writeFuzzRequests([]structs.FuzzRequest{
+		{URL: "http://127.0.0.1:8080/admin/user/save", Raw: raw},
+		{URL: "http://127.0.0.1:8080/search?q=test", Raw: "GET /search?q=test HTTP/1.1\r\nHost: www.example.com\r\n\r\n"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(requestFile)
+	file, err := os.Open(requestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var requests []*types.RequestResponse
+	err = jsonformat.New().Parse(file, func(rr *types.RequestResponse) bool {
+		requests = append(requests, rr)
+		return true
+	}, requestFile)
+	if err != nil || len(requests) != 2 {
+		t.Fatalf("parsed %d requests, err: %v", len(requests), err)
+	}
+	// 请求发往 url 字段中的地址，请求方法、请求头与请求体取自原始报文
+	post := requests[0]
+	if contentType, _ := post.Request.Headers.Get("Content-Type"); post.Request.Method != "POST" || post.Request.Body != "id=1&q=ab" || contentType != "application/x-www-form-urlencoded" || post.URL.Host != "127.0.0.1:8080" {
+		t.Fatalf("unexpected POST request: %s %s %s %s", post.Request.Method, post.URL.String(), contentType, post.Request.Body)
+	}
+	if get := requests[1]; get.Request.Method != "GET" || get.URL.Query().Get("q") != "test" {
+		t.Fatalf("unexpected GET request: %s %s", get.Request.Method, get.URL.String())
+	}
+}
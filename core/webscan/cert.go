@@ -1,9 +1,17 @@
 package webscan
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"crypto/tls"
+	"crypto/x509"
 	"net"
+	"slack-wails/lib/structs"
 	"strings"
+	"sync"
 	"time"
 
 	zasn1 "github.com/zmap/zcrypto/encoding/asn1"
@@ -16,47 +24,167 @@ type CertResponse struct {
 	IssuerCN  string
 	IssuerDN  string
 	IssuerOrg []string
+
+	cert  *x509.Certificate
+	state tls.ConnectionState
+	ja3s  string
 }
 
-func GetTLSString(protocol, host string) string {
-	TLSData := getCertResponse(protocol, host)
+func (c *CertResponse) String() string {
+	if c == nil {
+		return ""
+	}
 	var result strings.Builder
 	// 预分配一个中等大小的缓冲区，以避免频繁的内存重新分配
 	result.Grow(512)
 
-	if TLSData == nil {
-		return ""
-	}
-	result.WriteString("SubjectCN: " + TLSData.SubjectCN + "\n")
-	result.WriteString("SubjectDN: " + TLSData.SubjectDN + "\n")
-	result.WriteString("IssuerCN: " + TLSData.IssuerCN + "\n")
-	result.WriteString("IssuerDN: " + TLSData.IssuerDN + "\n")
+	result.WriteString("SubjectCN: " + c.SubjectCN + "\n")
+	result.WriteString("SubjectDN: " + c.SubjectDN + "\n")
+	result.WriteString("IssuerCN: " + c.IssuerCN + "\n")
+	result.WriteString("IssuerDN: " + c.IssuerDN + "\n")
 	result.WriteString("IssuerOrg: \n")
 
-	for _, v := range TLSData.IssuerOrg {
+	for _, v := range c.IssuerOrg {
 		result.WriteString("    - " + v + "\n")
 	}
 
 	return result.String()
 }
 
-func getCertResponse(protocol, host string) *CertResponse {
-	if protocol == "https" || protocol == "tls" {
-		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Duration(3) * time.Second}, "tcp", host, &tls.Config{InsecureSkipVerify: true})
-		if err != nil {
-			return nil
-		}
-		defer conn.Close()
-		cert := conn.ConnectionState().PeerCertificates[0]
-		return &CertResponse{
-			IssuerCN:  cert.Issuer.CommonName,
-			IssuerDN:  ParseASN1DNSequenceWithZpkixOrDefault(cert.RawIssuer, cert.Issuer.String()),
-			SubjectCN: cert.Subject.CommonName,
-			SubjectDN: ParseASN1DNSequenceWithZpkixOrDefault(cert.RawSubject, cert.Subject.String()),
-			IssuerOrg: cert.Issuer.Organization,
+// TLSProbeOptions 获取证书时的连接参数
+type TLSProbeOptions struct {
+	ServerName string // SNI，虚拟主机使用域名，为空时使用连接地址中的主机
+	ProxyURL   string // 与网站扫描相同的代理，支持 socks5 与 http
+	Deep       bool   // 计算 JARM 并探测弱协议与弱加密套件，需要数十次握手，默认关闭
+}
+
+// 单个目标全部 TLS 探测的总耗时上限
+const tlsProbeTimeout = 10 * time.Second
+
+// GetTLSInfo 返回证书文本，以及 JA3S 与证书详情，开启 Deep 时包含 JARM 与弱协议/弱套件探测结果
+func GetTLSInfo(protocol, host string, options TLSProbeOptions) (string, *structs.TLSInfo) {
+	if protocol != "https" && protocol != "tls" {
+		return "", nil
+	}
+	prober, err := newTLSProber(tlsAddress(host), options)
+	if err != nil {
+		return "", nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tlsProbeTimeout)
+	defer cancel()
+	resp := prober.certResponse(ctx)
+	if resp == nil {
+		return "", nil
+	}
+	cert := resp.cert
+	info := &structs.TLSInfo{
+		JA3S:               resp.ja3s,
+		Version:            tls.VersionName(resp.state.Version),
+		Cipher:             tls.CipherSuiteName(resp.state.CipherSuite),
+		SubjectCN:          resp.SubjectCN,
+		SubjectDN:          resp.SubjectDN,
+		IssuerCN:           resp.IssuerCN,
+		IssuerDN:           resp.IssuerDN,
+		IssuerOrg:          resp.IssuerOrg,
+		SANs:               certSANs(cert),
+		NotBefore:          cert.NotBefore.Local().Format(time.DateTime),
+		NotAfter:           cert.NotAfter.Local().Format(time.DateTime),
+		KeyAlgorithm:       cert.PublicKeyAlgorithm.String(),
+		KeySize:            publicKeySize(cert.PublicKey),
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		SelfSigned:         bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil,
+		Expired:            time.Now().After(cert.NotAfter),
+	}
+	if options.Deep {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			info.JARM = prober.jarmFingerprint(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			info.WeakProtocols, info.WeakCiphers = prober.weakTLS(ctx)
+		}()
+		wg.Wait()
+	}
+	return resp.String(), info
+}
+
+func (p *tlsProber) certResponse(ctx context.Context) *CertResponse {
+	rawConn, err := p.dial(ctx)
+	if err != nil {
+		return nil
+	}
+	// 记录服务端返回的原始数据用于计算 JA3S
+	record := &recordConn{Conn: rawConn}
+	conn := tls.Client(record, &tls.Config{InsecureSkipVerify: true, ServerName: p.serverName})
+	defer conn.Close()
+	if err := conn.Handshake(); err != nil {
+		return nil
+	}
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	cert := state.PeerCertificates[0]
+	record.mu.Lock()
+	fingerprint := ja3s(record.read)
+	record.mu.Unlock()
+	return &CertResponse{
+		IssuerCN:  cert.Issuer.CommonName,
+		IssuerDN:  ParseASN1DNSequenceWithZpkixOrDefault(cert.RawIssuer, cert.Issuer.String()),
+		SubjectCN: cert.Subject.CommonName,
+		SubjectDN: ParseASN1DNSequenceWithZpkixOrDefault(cert.RawSubject, cert.Subject.String()),
+		IssuerOrg: cert.Issuer.Organization,
+		cert:      cert,
+		state:     state,
+		ja3s:      fingerprint,
+	}
+}
+
+// tlsAddress 未指定端口时使用 443
+func tlsAddress(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), "443")
+}
+
+func certSANs(cert *x509.Certificate) []string {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return sans
+}
+
+func publicKeySize(key any) int {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return k.N.BitLen()
+	case *ecdsa.PublicKey:
+		return k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return 256
+	}
+	return 0
+}
+
+// SANDomains 从证书 SAN 中提取域名，去掉通配符前缀并忽略 IP
+func SANDomains(info *structs.TLSInfo) []string {
+	if info == nil {
+		return nil
+	}
+	var domains []string
+	for _, san := range info.SANs {
+		domain := strings.ToLower(strings.TrimPrefix(san, "*."))
+		if domain == "" || net.ParseIP(domain) != nil || !strings.Contains(domain, ".") {
+			continue
 		}
+		domains = append(domains, domain)
 	}
-	return nil
+	return domains
 }
 
 // ParseASN1DNSequenceWithZpkixOrDefault return the parsed value of ASN1DNSequence or a default string value
@@ -0,0 +1,64 @@
+package webscan
+
+import (
+	"context"
+	"fmt"
+	"slack-wails/core/crawler"
+	"slack-wails/lib/gologger"
+	"slack-wails/lib/structs"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// CrawlScan 爬取存活网站的链接、表单与接口请求，结果供目录扫描、JS 接口分析与参数模糊测试使用
+func (s *FingerScanner) CrawlScan(ctrlCtx context.Context) {
+	if len(s.aliveURLs) == 0 {
+		return
+	}
+	gologger.Info(s.ctx, fmt.Sprintf("Crawling in progress, targets: %d, depth: %d", len(s.aliveURLs), s.crawlOptions.MaxDepth))
+	for _, u := range s.aliveURLs {
+		if ctrlCtx.Err() != nil {
+			return
+		}
+		options := s.crawlOptions
+		// 浏览器无法替换虚拟主机的连接地址，只进行静态爬取
+		if s.isVHost(u) {
+			options.Headless = false
+		}
+		target := u.String()
+		endpoints := crawler.Crawl(ctrlCtx, target, s.client, options)
+		if len(endpoints) == 0 {
+			continue
+		}
+		crawler.Remember(target, endpoints)
+		s.mutex.Lock()
+		s.crawled[target] = endpoints
+		s.mutex.Unlock()
+		runtime.EventsEmit(s.ctx, "webscanCrawl", structs.CrawlResult{
+			TaskId:    s.taskId,
+			Target:    target,
+			Endpoints: endpoints,
+		})
+		gologger.Info(s.ctx, fmt.Sprintf("[crawler] %s endpoints: %d, params: %d", target, len(endpoints), len(crawler.Params(endpoints))))
+	}
+}
+
+// FuzzRequests 目标爬取到的带参数请求，虚拟主机改为请求 IP，Host 头由漏洞扫描的自定义请求头指定
+func (s *FingerScanner) FuzzRequests(target string) []structs.FuzzRequest {
+	s.mutex.RLock()
+	endpoints := crawler.FuzzRequests(s.crawled[target])
+	s.mutex.RUnlock()
+	var requests []structs.FuzzRequest
+	for _, endpoint := range endpoints {
+		raw, err := crawler.RawRequest(endpoint)
+		if err != nil {
+			continue
+		}
+		address := endpoint.URL
+		if ipURL, _, ok := s.VHostAddress(endpoint.URL); ok {
+			address = ipURL
+		}
+		requests = append(requests, structs.FuzzRequest{URL: address, Raw: raw})
+	}
+	return requests
+}
@@ -0,0 +1,339 @@
+package webscan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slack-wails/lib/structs"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CVEDataset 离线 CVE 数据集，products 将指纹名称映射为 CPE 前缀，versions 为补充的版本提取规则
+type CVEDataset struct {
+	Products map[string]string   `yaml:"products"`
+	Versions map[string][]string `yaml:"versions"`
+	CVEs     []CVEEntry          `yaml:"cves"`
+}
+
+// CVEEntry 单个漏洞的受影响版本，versions 为精确版本，ranges 为版本区间，二者满足其一即受影响
+type CVEEntry struct {
+	ID        string         `yaml:"id"`
+	CPE       string         `yaml:"cpe"`
+	Severity  string         `yaml:"severity"`
+	Versions  []string       `yaml:"versions"`
+	Ranges    []VersionRange `yaml:"ranges"`
+	Templates []string       `yaml:"templates"` // 对应的 nuclei 模板名称，为空时使用漏洞编号
+}
+
+// VersionRange 与 NVD 的 cpeMatch 字段含义一致，未填写的边界不做限制
+type VersionRange struct {
+	StartIncluding string `yaml:"start_including"`
+	StartExcluding string `yaml:"start_excluding"`
+	EndIncluding   string `yaml:"end_including"`
+	EndExcluding   string `yaml:"end_excluding"`
+}
+
+var CVEDB CVEDataset
+
+// 指纹名称统一规范化后查找 CPE
+var cpeProducts map[string]string
+
+// 数据集中产品的版本提取规则，指纹库规则没有提取到版本时使用，与 versions 中的规则合并
+var builtinVersionRules = map[string][]string{
+	"Apache-HTTPD":  {`server~="apache/(?P<version>\d+\.\d+\.\d+)"`},
+	"Apache-Tomcat": {`title~="apache tomcat/(?P<version>\d+\.\d+\.\d+)"`, `body~="<h3>apache tomcat/(?P<version>\d+\.\d+\.\d+)</h3>"`},
+	"Nginx":         {`server~="nginx/(?P<version>\d+\.\d+\.\d+)"`},
+	"Weblogic":      {`body~="weblogic server version: (?P<version>\d+\.\d+\.\d+\.\d+\.\d+)"`},
+	"Grafana":       {`body~="\"version\":\"(?P<version>\d+\.\d+\.\d+)\""`, `body~="grafana v(?P<version>\d+\.\d+\.\d+)"`},
+	"ThinkPHP":      {`body~="thinkphp v(?P<version>\d+\.\d+\.\d+)"`},
+}
+
+// 规范化的产品名称 -> 版本提取规则
+var versionDB map[string][]FingerPEntity
+
+// 至少包含主、次、修订三段数字的版本才认为可靠，只有可靠的版本才会跳过 CVE 模板
+var confidentVersion = regexp.MustCompile(`^\d+(\.\d+){2,}$`)
+
+// 配置文件不存在时写入的内置 CVE 数据集
+const defaultCVEYAML = `# 离线 CVE 数据集，指纹规则通过 (?P<version>...) 命名分组提取版本后在此匹配受影响的漏洞
+# products: 指纹名称 -> CPE 前缀 (cpe:2.3:part:vendor:product)，名称不区分大小写并忽略空格、-、_、.
+# versions: 指纹名称 -> 版本提取规则，指纹库没有提取到版本时使用，语法与指纹规则相同，已内置 products 中产品的规则
+# cves: versions 为精确版本; ranges 与 NVD 一致，可选 start_including/start_excluding/end_including/end_excluding
+#       templates 为对应的 nuclei 模板名称，为空时使用漏洞编号
+products:
+  Apache-HTTPD: cpe:2.3:a:apache:http_server
+  Apache-Tomcat: cpe:2.3:a:apache:tomcat
+  Tomcat: cpe:2.3:a:apache:tomcat
+  Nginx: cpe:2.3:a:f5:nginx
+  Weblogic: cpe:2.3:a:oracle:weblogic_server
+  Oracle-Weblogic: cpe:2.3:a:oracle:weblogic_server
+  Grafana: cpe:2.3:a:grafana:grafana
+  ThinkPHP: cpe:2.3:a:thinkphp:thinkphp
+cves:
+  - id: CVE-2021-41773
+    cpe: cpe:2.3:a:apache:http_server
+    severity: high
+    versions: [2.4.49]
+  - id: CVE-2021-42013
+    cpe: cpe:2.3:a:apache:http_server
+    severity: critical
+    versions: [2.4.49, 2.4.50]
+  - id: CVE-2020-1938
+    cpe: cpe:2.3:a:apache:tomcat
+    severity: critical
+    ranges:
+      - {start_including: 6.0.0, end_including: 6.0.53}
+      - {start_including: 7.0.0, end_excluding: 7.0.100}
+      - {start_including: 8.5.0, end_excluding: 8.5.51}
+      - {start_including: 9.0.0, end_excluding: 9.0.31}
+  - id: CVE-2021-23017
+    cpe: cpe:2.3:a:f5:nginx
+    severity: high
+    ranges:
+      - {start_including: 0.6.18, end_excluding: 1.20.1}
+  - id: CVE-2019-2725
+    cpe: cpe:2.3:a:oracle:weblogic_server
+    severity: critical
+    versions: [10.3.6.0.0, 12.1.3.0.0]
+  - id: CVE-2020-14882
+    cpe: cpe:2.3:a:oracle:weblogic_server
+    severity: critical
+    versions: [10.3.6.0.0, 12.1.3.0.0, 12.2.1.3.0, 12.2.1.4.0, 14.1.1.0.0]
+  - id: CVE-2021-43798
+    cpe: cpe:2.3:a:grafana:grafana
+    severity: high
+    ranges:
+      - {start_including: 8.0.0, end_excluding: 8.0.7}
+      - {start_including: 8.1.0, end_excluding: 8.1.8}
+      - {start_including: 8.2.0, end_excluding: 8.2.7}
+      - {start_including: 8.3.0, end_excluding: 8.3.1}
+  - id: CVE-2018-20062
+    cpe: cpe:2.3:a:thinkphp:thinkphp
+    severity: critical
+    ranges:
+      - {start_including: 5.0.0, end_excluding: 5.0.23}
+      - {start_including: 5.1.0, end_excluding: 5.1.31}
+`
+
+func (config *Config) InitCVEDB(cveFile string) error {
+	CVEDB, cpeProducts, versionDB = CVEDataset{}, nil, nil
+	if cveFile == "" {
+		return nil
+	}
+	if _, err := os.Stat(cveFile); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(cveFile), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(cveFile, []byte(defaultCVEYAML), 0644); err != nil {
+			return err
+		}
+	}
+	data, err := os.ReadFile(cveFile)
+	if err != nil {
+		return err
+	}
+	var dataset CVEDataset
+	if err := yaml.Unmarshal(data, &dataset); err != nil {
+		return err
+	}
+	for i, entry := range dataset.CVEs {
+		if entry.ID == "" || entry.CPE == "" || (len(entry.Versions) == 0 && len(entry.Ranges) == 0) {
+			return fmt.Errorf("cve [%d] %s: id, cpe and versions or ranges are required", i, entry.ID)
+		}
+	}
+	products := make(map[string]string, len(dataset.Products))
+	for name, cpe := range dataset.Products {
+		products[normalizeProductName(name)] = cpe
+	}
+	versions, err := compileVersionRules(builtinVersionRules, dataset.Versions)
+	if err != nil {
+		return err
+	}
+	CVEDB, cpeProducts, versionDB = dataset, products, versions
+	return nil
+}
+
+func compileVersionRules(ruleSets ...map[string][]string) (map[string][]FingerPEntity, error) {
+	compiler := newRuleCompiler()
+	versions := make(map[string][]FingerPEntity)
+	for _, rules := range ruleSets {
+		for name, ruleList := range rules {
+			for _, rule := range ruleList {
+				node, err := compiler.compile(rule)
+				if err != nil {
+					return nil, fmt.Errorf("versions %s: %v", name, err)
+				}
+				if !ruleHasVersion(node) {
+					return nil, fmt.Errorf("versions %s: %s 缺少 (?P<version>...) 分组", name, rule)
+				}
+				key := normalizeProductName(name)
+				versions[key] = append(versions[key], FingerPEntity{ProductName: name, AllString: rule, Rule: node, versioned: true})
+			}
+		}
+	}
+	compiler.build()
+	// Tomcat 与 Apache-Tomcat、Weblogic 与 Oracle-Weblogic 为同一产品的不同指纹名称
+	for alias, name := range map[string]string{"tomcat": "apachetomcat", "oracleweblogic": "weblogic"} {
+		if _, ok := versions[alias]; !ok {
+			versions[alias] = versions[name]
+		}
+	}
+	return versions, nil
+}
+
+// identifyVersions 为已命中但指纹规则没有提取到版本的产品补充版本
+func identifyVersions(state *matchState, fingerprints []string, versions map[string]string) {
+	for _, name := range fingerprints {
+		if versions[name] != "" {
+			continue
+		}
+		for _, finger := range versionDB[normalizeProductName(name)] {
+			state.version = ""
+			if finger.Rule.eval(state) && state.version != "" {
+				versions[name] = state.version
+				break
+			}
+		}
+	}
+}
+
+// FingerprintDetails 为识别到的指纹关联 CPE 与受影响的 CVE，既没有版本也没有 CPE 的指纹不输出
+func FingerprintDetails(fingerprints []string, versions map[string]string) []structs.FingerprintDetail {
+	var details []structs.FingerprintDetail
+	for _, name := range fingerprints {
+		version := versions[name]
+		prefix := cpeProducts[normalizeProductName(name)]
+		if version == "" && prefix == "" {
+			continue
+		}
+		detail := structs.FingerprintDetail{Name: name, Version: version}
+		if prefix != "" {
+			detail.CPE = formatCPE(prefix, version)
+			for _, entry := range affectedCVEs(prefix, version, true) {
+				detail.CVEs = append(detail.CVEs, structs.FingerprintCVE{ID: entry.ID, Severity: entry.Severity})
+			}
+		}
+		details = append(details, detail)
+	}
+	return details
+}
+
+// CVETemplates 根据指纹版本返回需要追加扫描的 CVE 模板，以及版本明确不受影响、可以跳过的 CVE 模板
+func CVETemplates(details []structs.FingerprintDetail) (include, skip []string) {
+	for _, detail := range details {
+		prefix := cpeProducts[normalizeProductName(detail.Name)]
+		if prefix == "" || detail.Version == "" {
+			continue
+		}
+		for _, entry := range affectedCVEs(prefix, detail.Version, true) {
+			include = append(include, entry.templates()...)
+		}
+		// 版本不完整时可能误判，只追加不跳过
+		if !confidentVersion.MatchString(strings.TrimPrefix(strings.ToLower(detail.Version), "v")) {
+			continue
+		}
+		for _, entry := range affectedCVEs(prefix, detail.Version, false) {
+			skip = append(skip, entry.templates()...)
+		}
+	}
+	return include, skip
+}
+
+// affectedCVEs 返回该产品版本受影响(affected 为 true)或不受影响的漏洞，版本为空时不做判断
+func affectedCVEs(prefix, version string, affected bool) []CVEEntry {
+	if version == "" {
+		return nil
+	}
+	var entries []CVEEntry
+	for _, entry := range CVEDB.CVEs {
+		if strings.EqualFold(entry.CPE, prefix) && entry.affects(version) == affected {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (entry *CVEEntry) affects(version string) bool {
+	for _, v := range entry.Versions {
+		if compareVersion(version, v) == 0 {
+			return true
+		}
+	}
+	for _, r := range entry.Ranges {
+		if r.contains(version) {
+			return true
+		}
+	}
+	return false
+}
+
+func (entry *CVEEntry) templates() []string {
+	if len(entry.Templates) == 0 {
+		return []string{entry.ID}
+	}
+	return entry.Templates
+}
+
+func (r *VersionRange) contains(version string) bool {
+	if r.StartIncluding != "" && compareVersion(version, r.StartIncluding) < 0 {
+		return false
+	}
+	if r.StartExcluding != "" && compareVersion(version, r.StartExcluding) <= 0 {
+		return false
+	}
+	if r.EndIncluding != "" && compareVersion(version, r.EndIncluding) > 0 {
+		return false
+	}
+	if r.EndExcluding != "" && compareVersion(version, r.EndExcluding) >= 0 {
+		return false
+	}
+	return true
+}
+
+// formatCPE 补全为 CPE 2.3 格式化字符串，版本未知时为 *
+func formatCPE(prefix, version string) string {
+	if version == "" {
+		version = "*"
+	}
+	return prefix + ":" + version + strings.Repeat(":*", 7)
+}
+
+// compareVersion 按 . - _ 分段比较版本号，数字段按数值比较，缺少的段视为 0，如 2.4 == 2.4.0 < 2.4.10
+func compareVersion(a, b string) int {
+	split := func(v string) []string {
+		return strings.FieldsFunc(strings.TrimPrefix(strings.ToLower(v), "v"), func(r rune) bool {
+			return r == '.' || r == '-' || r == '_'
+		})
+	}
+	as, bs := split(a), split(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xerr := strconv.Atoi(x)
+		yn, yerr := strconv.Atoi(y)
+		switch {
+		case xerr == nil && yerr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -1,22 +1,329 @@
 package webscan
 
-import "strings"
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slack-wails/lib/structs"
+	"sort"
+	"strings"
+	"sync"
+)
 
-var honeypotHeaders = []string{"Cacti", "grafana_session", "X-Jenkins", "Mime-Version", "Composed-By", "zbx_session", "akaunting_session", "DSSIGNIN", "X-Drupal", "drupal", "X-Influxdb", "X-Cmd-Response", "X-Root", "couchdb"}
+// 蜜罐评分达到该值时指纹替换为 疑似蜜罐
+const HoneypotThreshold = 60
 
-func checkHoneypotWithHeaders(rawHeaders string) bool {
-	var count int
-	for _, header := range honeypotHeaders {
-		if strings.Contains(rawHeaders, header) {
-			count++
+// 不同产品的响应头特征，HeadeString 为小写，关键字同样使用小写
+var honeypotHeaders = []string{"cacti", "grafana_session", "x-jenkins", "mime-version", "composed-by", "zbx_session", "akaunting_session", "dssignin", "x-drupal", "drupal", "x-influxdb", "x-cmd-response", "x-root", "couchdb"}
+
+// 已知蜜罐产品的特征，关键字均为小写，任一位置命中即可
+type honeypotProduct struct {
+	Name   string
+	Title  []string
+	Body   []string
+	Header []string
+	Cert   []string
+}
+
+var honeypotProducts = []honeypotProduct{
+	{Name: "HFish", Title: []string{"hfish"}, Body: []string{"hfish"}, Header: []string{"hfish"}, Cert: []string{"hfish"}},
+	{Name: "T-Pot", Title: []string{"t-pot"}, Body: []string{"tpotce", "t-pot"}, Cert: []string{"t-pot", "tpotce"}},
+	{Name: "Glutton", Body: []string{"glutton"}, Header: []string{"glutton"}},
+	{Name: "Conpot", Title: []string{"technodrome"}, Body: []string{"conpot", "technodrome"}, Header: []string{"conpot"}},
+	{Name: "OpenCanary", Body: []string{"opencanary"}, Header: []string{"opencanary"}, Cert: []string{"opencanary"}},
+	{Name: "Glastopf", Body: []string{"glastopf"}, Header: []string{"glastopf"}},
+	{Name: "Dionaea", Body: []string{"dionaea"}, Header: []string{"dionaea"}, Cert: []string{"dionaea"}},
+}
+
+// 蜜罐默认模板中固定不变的 Server 头或页面片段
+var cannedBanners = []struct {
+	Header string // 小写的 Server 头前缀
+	Body   string
+	Reason string
+}{
+	{Header: "apache/2.0.48", Reason: "Glastopf 默认 Server 头"},
+	{Body: "siemens, simatic, s7-200", Reason: "Conpot 默认 S7-200 页面"},
+}
+
+// OpenSSL 生成证书时的默认组织名称，蜜罐常直接使用默认参数生成自签名证书
+const defaultCertOrg = "internet widgits pty ltd"
+
+// HoneypotHosts 同一主机在多个端口上的汇总信息，开放端口数来自端口扫描结果，Server 头与 JARM 在指纹扫描过程中累积
+// 指纹结果先按主机暂存，主机的全部 URL 识别完成后再计算主机级信号并评分，评分不受并发识别顺序影响
+type HoneypotHosts struct {
+	mu        sync.Mutex
+	ports     map[string]map[string]bool
+	servers   map[string]map[string]bool
+	jarms     map[string]map[string]map[string]bool // 主机 -> JARM -> Server 产品
+	remaining map[string]int                        // 主机尚未完成识别的 URL 数量
+	pending   map[string][]pendingHoneypot
+}
+
+// pendingHoneypot 等待主机级信号的指纹结果，Evidence 只包含单个 URL 的信息
+type pendingHoneypot struct {
+	result   structs.InfoResult
+	evidence HoneypotEvidence
+}
+
+func NewHoneypotHosts() *HoneypotHosts {
+	return &HoneypotHosts{
+		ports:     make(map[string]map[string]bool),
+		servers:   make(map[string]map[string]bool),
+		jarms:     make(map[string]map[string]map[string]bool),
+		remaining: make(map[string]int),
+		pending:   make(map[string][]pendingHoneypot),
+	}
+}
+
+// AddTarget 记录目标所在主机的开放端口，支持 URL 与 host:port 格式
+func (h *HoneypotHosts) AddTarget(target string) {
+	host, port := splitTarget(target)
+	if host == "" || port == "" {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.ports[host] == nil {
+		h.ports[host] = make(map[string]bool)
+	}
+	h.ports[host][port] = true
+}
+
+// Expect 登记主机一个待识别的 URL，每个 URL 识别结束后需调用 Done
+func (h *HoneypotHosts) Expect(host string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remaining[host]++
+}
+
+// Hold 暂存主机某端口的指纹结果，并记录该端口的 Server 产品与 JARM
+func (h *HoneypotHosts) Hold(host string, result structs.InfoResult, evidence HoneypotEvidence) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if evidence.Web != nil {
+		if product := serverProduct(evidence.Web.Server); product != "" {
+			if h.servers[host] == nil {
+				h.servers[host] = make(map[string]bool)
+			}
+			h.servers[host][product] = true
+			// 多个声称不同产品的端口共用同一 TLS 实现，通常是同一个蜜罐程序在模拟多种服务
+			if jarm := evidence.Web.JARM; jarm != "" {
+				if h.jarms[host] == nil {
+					h.jarms[host] = make(map[string]map[string]bool)
+				}
+				if h.jarms[host][jarm] == nil {
+					h.jarms[host][jarm] = make(map[string]bool)
+				}
+				h.jarms[host][jarm][product] = true
+			}
+		}
+	}
+	h.pending[host] = append(h.pending[host], pendingHoneypot{result: result, evidence: evidence})
+}
+
+// Done 主机的一个 URL 识别结束，全部结束时返回该主机暂存的结果，结果已完成蜜罐评分
+func (h *HoneypotHosts) Done(host string) []structs.InfoResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.remaining[host]--; h.remaining[host] > 0 {
+		return nil
+	}
+	delete(h.remaining, host)
+	return h.releaseLocked(host)
+}
+
+// Flush 返回所有主机暂存的结果，用于扫描中止时仍有 URL 未识别的情况
+func (h *HoneypotHosts) Flush() []structs.InfoResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var results []structs.InfoResult
+	for host := range h.pending {
+		results = append(results, h.releaseLocked(host)...)
+	}
+	clear(h.remaining)
+	return results
+}
+
+// releaseLocked 补充主机级信号后评分，达到阈值的结果指纹替换为 疑似蜜罐
+func (h *HoneypotHosts) releaseLocked(host string) []structs.InfoResult {
+	var results []structs.InfoResult
+	for _, p := range h.pending[host] {
+		e := p.evidence
+		e.OpenPorts = len(h.ports[host])
+		e.Servers = len(h.servers[host])
+		if e.Web != nil && e.Web.JARM != "" {
+			e.SharedJARM = len(h.jarms[host][e.Web.JARM])
+		}
+		result := p.result
+		result.Honeypot = ScoreHoneypot(e)
+		if result.Honeypot != nil && result.Honeypot.Score >= HoneypotThreshold {
+			result.Fingerprints = []string{"疑似蜜罐"}
+			result.FingerprintDetails = nil
+		}
+		results = append(results, result)
+	}
+	delete(h.pending, host)
+	return results
+}
+
+func (h *HoneypotHosts) OpenPorts(host string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.ports[host])
+}
+
+func splitTarget(target string) (string, string) {
+	if strings.Contains(target, "://") {
+		u, err := url.Parse(target)
+		if err != nil {
+			return "", ""
 		}
-		if count >= 3 {
-			return true
+		port := u.Port()
+		if port == "" {
+			port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
 		}
+		return u.Hostname(), port
+	}
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		return "", ""
+	}
+	return host, port
+}
+
+// serverProduct 取 Server 头中的产品名称，如 nginx/1.18.0 (Ubuntu) -> nginx
+func serverProduct(server string) string {
+	server = strings.ToLower(strings.TrimSpace(server))
+	if i := strings.IndexAny(server, "/ ("); i >= 0 {
+		server = server[:i]
 	}
-	return false
+	return server
 }
 
-func checkHoneypotWithFingerprintLength(length int) bool {
-	return length > 15
+type honeypotSignal struct {
+	weight int // 单项信号的置信度 0-100
+	reason string
+}
+
+// HoneypotEvidence 评分所需的单个 URL 信息与主机汇总信息
+type HoneypotEvidence struct {
+	Web          *WebInfo
+	Fingerprints int // 被动指纹命中数量
+	OpenPorts    int // 同一主机开放端口数量
+	Servers      int // 同一主机不同端口返回的 Server 产品数量
+	TLS          *structs.TLSInfo
+	SharedJARM   int // 同一主机使用相同 JARM 的不同 Server 产品数量
+}
+
+// ScoreHoneypot 综合各项信号计算蜜罐置信度，各信号视为独立证据按 1-Π(1-p) 合并，没有任何信号时返回 nil
+func ScoreHoneypot(e HoneypotEvidence) *structs.HoneypotInfo {
+	var signals []honeypotSignal
+	add := func(weight int, format string, args ...interface{}) {
+		signals = append(signals, honeypotSignal{weight: weight, reason: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case e.Fingerprints > 15:
+		add(60, "同时命中 %d 个指纹", e.Fingerprints)
+	case e.Fingerprints > 8:
+		add(25, "同时命中 %d 个指纹", e.Fingerprints)
+	}
+
+	if e.Web != nil {
+		header := strings.ToLower(e.Web.HeadeString)
+		var decoys []string
+		for _, keyword := range honeypotHeaders {
+			if strings.Contains(header, keyword) {
+				decoys = append(decoys, keyword)
+			}
+		}
+		switch {
+		case len(decoys) >= 3:
+			add(60, "响应头同时包含多个产品特征: %s", strings.Join(decoys, ", "))
+		case len(decoys) == 2:
+			add(20, "响应头同时包含多个产品特征: %s", strings.Join(decoys, ", "))
+		}
+
+		title := strings.ToLower(e.Web.Title)
+		body := strings.ToLower(e.Web.BodyString)
+		cert := strings.ToLower(e.Web.Cert)
+		for _, product := range honeypotProducts {
+			// 页面正文可能只是提到了蜜罐产品，权重低于标题、响应头与证书
+			switch where := matchHoneypotProduct(product, title, body, header, cert); where {
+			case "":
+			case "页面":
+				add(50, "%s 中命中 %s 蜜罐特征", where, product.Name)
+			default:
+				add(90, "%s 中命中 %s 蜜罐特征", where, product.Name)
+			}
+		}
+
+		server := strings.ToLower(strings.TrimSpace(e.Web.Server))
+		for _, banner := range cannedBanners {
+			if (banner.Header == "" || strings.HasPrefix(server, banner.Header)) && (banner.Body == "" || strings.Contains(body, banner.Body)) {
+				add(35, "%s", banner.Reason)
+			}
+		}
+	}
+
+	if e.TLS != nil && e.TLS.SelfSigned {
+		for _, org := range e.TLS.IssuerOrg {
+			if strings.ToLower(org) == defaultCertOrg {
+				add(20, "证书为 OpenSSL 默认参数生成的自签名证书")
+				break
+			}
+		}
+	}
+
+	if e.SharedJARM >= 3 {
+		add(40, "同一主机 %d 种 Server 使用相同的 JARM 指纹", e.SharedJARM)
+	}
+
+	switch {
+	case e.OpenPorts >= 100:
+		add(55, "主机开放 %d 个端口", e.OpenPorts)
+	case e.OpenPorts >= 30:
+		add(25, "主机开放 %d 个端口", e.OpenPorts)
+	}
+
+	switch {
+	case e.Servers >= 4:
+		add(45, "同一主机不同端口返回 %d 种 Server", e.Servers)
+	case e.Servers == 3:
+		add(15, "同一主机不同端口返回 %d 种 Server", e.Servers)
+	}
+
+	if len(signals) == 0 {
+		return nil
+	}
+	sort.SliceStable(signals, func(i, j int) bool { return signals[i].weight > signals[j].weight })
+	info := &structs.HoneypotInfo{}
+	benign := 1.0
+	for _, s := range signals {
+		benign *= 1 - float64(s.weight)/100
+		info.Reasons = append(info.Reasons, s.reason)
+	}
+	info.Score = int((1-benign)*100 + 0.5)
+	return info
+}
+
+func matchHoneypotProduct(product honeypotProduct, title, body, header, cert string) string {
+	fields := []struct {
+		where    string
+		value    string
+		keywords []string
+	}{
+		{"标题", title, product.Title},
+		{"响应头", header, product.Header},
+		{"证书", cert, product.Cert},
+		{"页面", body, product.Body},
+	}
+	for _, f := range fields {
+		for _, keyword := range f.keywords {
+			if f.value != "" && strings.Contains(f.value, keyword) {
+				return f.where
+			}
+		}
+	}
+	return ""
 }
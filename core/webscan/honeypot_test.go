@@ -0,0 +1,124 @@
+package webscan
+
+import (
+	"fmt"
+	"slack-wails/lib/structs"
+	"strings"
+	"testing"
+)
+
+func TestScoreHoneypot(t *testing.T) {
+	tests := []struct {
+		name     string
+		evidence HoneypotEvidence
+		min, max int
+	}{
+		{"normal", HoneypotEvidence{Web: &WebInfo{Title: "登录", Server: "nginx/1.18.0"}, Fingerprints: 2, OpenPorts: 3, Servers: 1}, 0, 0},
+		{"hfish title", HoneypotEvidence{Web: &WebInfo{Title: "HFish 蜜罐"}}, 90, 90},
+		{"body mention", HoneypotEvidence{Web: &WebInfo{BodyString: "如何部署 hfish"}}, 50, 50},
+		{"decoy headers", HoneypotEvidence{Web: &WebInfo{HeadeString: strings.ToLower("X-Jenkins: 2.1\r\nSet-Cookie: zbx_session=1\r\nX-Drupal-Cache: HIT\r\n")}}, HoneypotThreshold, 100},
+		{"default cert", HoneypotEvidence{Web: &WebInfo{}, TLS: &structs.TLSInfo{SelfSigned: true, IssuerOrg: []string{"Internet Widgits Pty Ltd"}}}, 20, 20},
+		{"shared jarm", HoneypotEvidence{Web: &WebInfo{}, SharedJARM: 3}, 40, 40},
+		{"fingerprints", HoneypotEvidence{Web: &WebInfo{}, Fingerprints: 9}, 25, 25},
+		{"host", HoneypotEvidence{Web: &WebInfo{}, OpenPorts: 120, Servers: 4}, 75, 75},
+	}
+	for _, tt := range tests {
+		got := ScoreHoneypot(tt.evidence)
+		score := 0
+		if got != nil {
+			score = got.Score
+			if len(got.Reasons) == 0 {
+				t.Errorf("%s: score without reasons", tt.name)
+			}
+		}
+		if score < tt.min || score > tt.max {
+			t.Errorf("%s: ScoreHoneypot() = %+v, want score in [%d, %d]", tt.name, got, tt.min, tt.max)
+		}
+	}
+
+	hosts := NewHoneypotHosts()
+	for port := 1; port <= 5; port++ {
+		hosts.AddTarget(fmt.Sprintf("http://10.0.0.1:%d", port))
+	}
+	hosts.AddTarget("10.0.0.1:3306")
+	hosts.AddTarget("https://10.0.0.1")
+	if n := hosts.OpenPorts("10.0.0.1"); n != 7 {
+		t.Errorf("OpenPorts() = %d, want 7", n)
+	}
+}
+
+func TestHoneypotHosts(t *testing.T) {
+	type service struct {
+		port, server, jarm string
+	}
+	// 按给定顺序识别主机的各端口，返回主机全部识别完成后的结果
+	scan := func(hosts *HoneypotHosts, host string, services []service) []structs.InfoResult {
+		for range services {
+			hosts.Expect(host)
+		}
+		var results []structs.InfoResult
+		for i, svc := range services {
+			u := fmt.Sprintf("http://%s:%s", host, svc.port)
+			hosts.Hold(host, structs.InfoResult{URL: u, Fingerprints: []string{svc.server}}, HoneypotEvidence{Web: &WebInfo{Server: svc.server, JARM: svc.jarm}, Fingerprints: 1})
+			released := hosts.Done(host)
+			if i < len(services)-1 && len(released) != 0 {
+				t.Fatalf("%s: results released before all ports were fingerprinted", host)
+			}
+			results = append(results, released...)
+		}
+		if len(results) != len(services) {
+			t.Fatalf("%s: released %d results, want %d", host, len(results), len(services))
+		}
+		return results
+	}
+
+	// 正常业务主机上运行多个不同服务，各自使用独立的 TLS 实现，不应判定为蜜罐
+	legit := []service{
+		{"80", "nginx/1.18.0 (Ubuntu)", ""},
+		{"443", "nginx/1.18.0 (Ubuntu)", "27d40d40d29d40d1dc42d43d00041d4689ee210389f4f6b4b5b1b93f92252d"},
+		{"8080", "Jetty(9.4.43)", "2ad2ad16d2ad2ad00042d42d00000023f2ae3bb7f1f4e3e0c1ab5d7bc9bf0e"},
+		{"8443", "Apache-Coyote/1.1", "2ad2ad0002ad2ad22c2ad2ad2ad2ad2eb3b8b3ed1d8d1a5e8b1d8b5c3c9c1b"},
+		{"9000", "Microsoft-IIS/10.0", "2ad2ad0002ad2ad00042d42d00000069d641f34fe76acdc05c40262f8815e5"},
+	}
+	for _, order := range [][]int{{0, 1, 2, 3, 4}, {4, 3, 2, 1, 0}, {2, 0, 4, 1, 3}} {
+		hosts := NewHoneypotHosts()
+		for _, svc := range legit {
+			hosts.AddTarget("10.0.0.2:" + svc.port)
+		}
+		var services []service
+		for _, i := range order {
+			services = append(services, legit[i])
+		}
+		for _, result := range scan(hosts, "10.0.0.2", services) {
+			if result.Honeypot == nil || result.Honeypot.Score >= HoneypotThreshold || result.Fingerprints[0] == "疑似蜜罐" {
+				t.Errorf("order %v: %s flagged as honeypot: %+v", order, result.URL, result.Honeypot)
+			}
+		}
+	}
+
+	// 同一程序模拟多种服务，所有端口共用相同 JARM，第一个识别完成的端口同样需要被标记
+	hosts := NewHoneypotHosts()
+	jarm := "2ad2ad0002ad2ad00042d42d00000069d641f34fe76acdc05c40262f8815e5"
+	var decoys []service
+	for i, server := range []string{"nginx", "Microsoft-IIS/10.0", "Jetty(9.4)", "Apache/2.4.41"} {
+		decoys = append(decoys, service{fmt.Sprint(8000 + i), server, jarm})
+		hosts.AddTarget(fmt.Sprintf("10.0.0.3:%d", 8000+i))
+	}
+	for _, result := range scan(hosts, "10.0.0.3", decoys) {
+		if result.Honeypot == nil || result.Honeypot.Score < HoneypotThreshold || result.Fingerprints[0] != "疑似蜜罐" {
+			t.Errorf("%s not flagged as honeypot: %+v", result.URL, result.Honeypot)
+		}
+	}
+
+	// 扫描中止时未识别完成的主机结果同样需要返回
+	hosts = NewHoneypotHosts()
+	hosts.Expect("10.0.0.4")
+	hosts.Expect("10.0.0.4")
+	hosts.Hold("10.0.0.4", structs.InfoResult{URL: "http://10.0.0.4"}, HoneypotEvidence{Web: &WebInfo{}})
+	if n := len(hosts.Done("10.0.0.4")); n != 0 {
+		t.Errorf("Done() released %d results, want 0", n)
+	}
+	if n := len(hosts.Flush()); n != 1 {
+		t.Errorf("Flush() = %d results, want 1", n)
+	}
+}
@@ -0,0 +1,836 @@
+package webscan
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slack-wails/lib/structs"
+	"slack-wails/lib/utils/arrayutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+	"gopkg.in/yaml.v2"
+)
+
+// 支持导入的第三方指纹库格式
+const (
+	ImportEHole          = "ehole"          // EHole finger.json
+	ImportFingerprintHub = "fingerprinthub" // FingerprintHub web_fingerprint_v3.json 或 v4 模板目录
+	ImportWappalyzer     = "wappalyzer"     // Wappalyzer technologies 目录或单个 json
+	ImportNuclei         = "nuclei"         // nuclei tech-detect 等带 tech 标签的模板
+	ImportTideFinger     = "tidefinger"     // TideFinger cms_finger.db
+)
+
+// importedRule 转换后的规则，Path 不为空时同时写入主动探测路径
+type importedRule struct {
+	Product string
+	Rule    string
+	Path    string
+}
+
+type importBatch struct {
+	rules   []importedRule
+	skipped int
+}
+
+func (b *importBatch) add(product, path, rule string) {
+	product = strings.TrimSpace(product)
+	if product == "" || rule == "" {
+		b.skipped++
+		return
+	}
+	b.rules = append(b.rules, importedRule{Product: product, Rule: rule, Path: path})
+}
+
+// ImportFingerprints 将其他工具的指纹库转换为规则，按产品名称合并到 fingerprintFile 与 activeFile
+func ImportFingerprints(format, source, fingerprintFile, activeFile string) (*structs.FingerprintImportResult, error) {
+	b := &importBatch{}
+	var err error
+	switch format {
+	case ImportEHole:
+		err = b.readFiles(source, map[string]func([]byte) error{".json": b.ehole})
+	case ImportFingerprintHub:
+		err = b.readFiles(source, map[string]func([]byte) error{
+			".json": b.fingerprintHub,
+			".yaml": func(data []byte) error { return b.nuclei(data, false) },
+		})
+	case ImportWappalyzer:
+		err = b.readFiles(source, map[string]func([]byte) error{".json": b.wappalyzer})
+	case ImportNuclei:
+		err = b.readFiles(source, map[string]func([]byte) error{
+			".yaml": func(data []byte) error { return b.nuclei(data, true) },
+		})
+	case ImportTideFinger:
+		err = b.tideFinger(source)
+	default:
+		return nil, fmt.Errorf("不支持的指纹库格式 %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return mergeFingerprints(b, fingerprintFile, activeFile)
+}
+
+// readFiles source 为目录时遍历其中的文件，单个文件解析失败只计入跳过数
+func (b *importBatch) readFiles(source string, parsers map[string]func([]byte) error) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		parse, ok := parsers[importExt(source)]
+		if !ok {
+			return fmt.Errorf("不支持的文件类型 %s", source)
+		}
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return err
+		}
+		return parse(data)
+	}
+	return filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		parse, ok := parsers[importExt(path)]
+		if !ok {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || parse(data) != nil {
+			b.skipped++
+		}
+		return nil
+	})
+}
+
+func importExt(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yml" {
+		return ".yaml"
+	}
+	return ext
+}
+
+// condRule 生成单个条件，值以反斜杠结尾时会与结束引号组成转义，无法表示
+func condRule(key, op, value string) string {
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	if strings.HasSuffix(value, `\`) {
+		return ""
+	}
+	return key + op + `"` + value + `"`
+}
+
+// joinRules 连接多个条件，任意条件无法转换时整体放弃
+func joinRules(op string, parts []string) string {
+	if len(parts) == 0 {
+		return ""
+	}
+	wrapped := make([]string, len(parts))
+	for i, part := range parts {
+		if part == "" {
+			return ""
+		}
+		if len(parts) > 1 && (strings.Contains(part, " && ") || strings.Contains(part, " || ")) {
+			part = "(" + part + ")"
+		}
+		wrapped[i] = part
+	}
+	return strings.Join(wrapped, " "+op+" ")
+}
+
+// importPath 根路径的规则为被动指纹，其余路径需要主动探测并限定 path 条件
+func importPath(path string) (string, string) {
+	path = strings.TrimSpace(path)
+	if path == "" || path == "/" {
+		return "", ""
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path, condRule("path", "=", path)
+}
+
+func withPath(pathCond, rule string) string {
+	if pathCond == "" || rule == "" {
+		return rule
+	}
+	return joinRules("&&", []string{pathCond, rule})
+}
+
+// iconRule 数字为 mmh3 哈希，否则视为 md5
+func iconRule(hash string) string {
+	hash = strings.TrimSpace(hash)
+	if _, err := strconv.Atoi(hash); err == nil {
+		return condRule("icon_hash", "=", hash)
+	}
+	return condRule("icon_mdhash", "=", hash)
+}
+
+func (b *importBatch) ehole(data []byte) error {
+	var finger struct {
+		Fingerprint []struct {
+			Cms      string   `json:"cms"`
+			Method   string   `json:"method"`
+			Location string   `json:"location"`
+			Keyword  []string `json:"keyword"`
+		} `json:"fingerprint"`
+	}
+	if err := json.Unmarshal(data, &finger); err != nil {
+		return err
+	}
+	for _, fp := range finger.Fingerprint {
+		switch fp.Method {
+		case "faviconhash":
+			for _, hash := range fp.Keyword {
+				b.add(fp.Cms, "", iconRule(hash))
+			}
+		case "keyword", "regula":
+			op := "="
+			if fp.Method == "regula" {
+				op = "~="
+			}
+			var parts []string
+			for _, keyword := range fp.Keyword {
+				parts = append(parts, condRule(fp.Location, op, keyword))
+			}
+			b.add(fp.Cms, "", joinRules("&&", parts))
+		default:
+			b.skipped++
+		}
+	}
+	return nil
+}
+
+// fingerprintHub 解析 v3 版本的 web_fingerprint_v3.json
+func (b *importBatch) fingerprintHub(data []byte) error {
+	var fingers []struct {
+		Name           string            `json:"name"`
+		Path           string            `json:"path"`
+		RequestMethod  string            `json:"request_method"`
+		RequestHeaders map[string]string `json:"request_headers"`
+		RequestData    string            `json:"request_data"`
+		StatusCode     int               `json:"status_code"`
+		Headers        map[string]string `json:"headers"`
+		Keyword        []string          `json:"keyword"`
+		FaviconHash    []string          `json:"favicon_hash"`
+	}
+	if err := json.Unmarshal(data, &fingers); err != nil {
+		return err
+	}
+	for _, fp := range fingers {
+		// 需要自定义请求方法、请求头或请求体的指纹无法复现
+		if (fp.RequestMethod != "" && !strings.EqualFold(fp.RequestMethod, "get")) || fp.RequestData != "" || len(fp.RequestHeaders) > 0 {
+			b.skipped++
+			continue
+		}
+		for _, hash := range fp.FaviconHash {
+			b.add(fp.Name, "", iconRule(hash))
+		}
+		if len(fp.Keyword) == 0 && len(fp.Headers) == 0 {
+			continue
+		}
+		path, pathCond := importPath(fp.Path)
+		var parts []string
+		if fp.StatusCode != 0 {
+			parts = append(parts, condRule("status", "=", strconv.Itoa(fp.StatusCode)))
+		}
+		for _, key := range sortedKeys(fp.Headers) {
+			parts = append(parts, condRule("header", "=", key))
+			if value := fp.Headers[key]; value != "*" && value != "" {
+				parts = append(parts, condRule("header", "=", value))
+			}
+		}
+		for _, keyword := range fp.Keyword {
+			parts = append(parts, condRule("body", "=", keyword))
+		}
+		b.add(fp.Name, path, withPath(pathCond, joinRules("&&", parts)))
+	}
+	return nil
+}
+
+type nucleiTemplate struct {
+	ID   string `yaml:"id"`
+	Info struct {
+		Name string      `yaml:"name"`
+		Tags interface{} `yaml:"tags"`
+	} `yaml:"info"`
+	HTTP     []nucleiRequest `yaml:"http"`
+	Requests []nucleiRequest `yaml:"requests"`
+}
+
+type nucleiRequest struct {
+	Method            string            `yaml:"method"`
+	Path              []string          `yaml:"path"`
+	Raw               []string          `yaml:"raw"`
+	Body              string            `yaml:"body"`
+	Headers           map[string]string `yaml:"headers"`
+	MatchersCondition string            `yaml:"matchers-condition"`
+	Matchers          []nucleiMatcher   `yaml:"matchers"`
+}
+
+type nucleiMatcher struct {
+	Type      string   `yaml:"type"`
+	Part      string   `yaml:"part"`
+	Name      string   `yaml:"name"`
+	Condition string   `yaml:"condition"`
+	Negative  bool     `yaml:"negative"`
+	Words     []string `yaml:"words"`
+	Regex     []string `yaml:"regex"`
+	Status    []int    `yaml:"status"`
+	Hash      []string `yaml:"hash"` // FingerprintHub v4 的 favicon 匹配
+	DSL       []string `yaml:"dsl"`
+}
+
+// nuclei 转换 nuclei 模板，tech-detect 这类带名称的 or 匹配器每个名称对应一个产品
+func (b *importBatch) nuclei(data []byte, requireTech bool) error {
+	var tpl nucleiTemplate
+	if err := yaml.Unmarshal(data, &tpl); err != nil {
+		return err
+	}
+	if requireTech && !hasTag(tpl.Info.Tags, "tech") {
+		return nil
+	}
+	product := tpl.Info.Name
+	if product == "" {
+		product = tpl.ID
+	}
+	for _, req := range append(tpl.HTTP, tpl.Requests...) {
+		if len(req.Raw) > 0 || req.Body != "" || len(req.Headers) > 0 || (req.Method != "" && !strings.EqualFold(req.Method, "GET")) {
+			b.skipped++
+			continue
+		}
+		for _, p := range req.Path {
+			if !strings.HasPrefix(p, "{{BaseURL}}") {
+				b.skipped++
+				continue
+			}
+			path, pathCond := importPath(strings.TrimPrefix(p, "{{BaseURL}}"))
+			if req.MatchersCondition == "and" {
+				var parts []string
+				for _, m := range req.Matchers {
+					parts = append(parts, nucleiMatcherRule(m))
+				}
+				b.add(product, path, withPath(pathCond, joinRules("&&", parts)))
+				continue
+			}
+			for _, m := range req.Matchers {
+				// or 条件下单独的状态码匹配会命中所有页面
+				if m.Type == "status" {
+					continue
+				}
+				name := m.Name
+				if name == "" {
+					name = product
+				}
+				b.add(name, path, withPath(pathCond, nucleiMatcherRule(m)))
+			}
+		}
+	}
+	return nil
+}
+
+func hasTag(tags interface{}, tag string) bool {
+	var list []string
+	switch t := tags.(type) {
+	case string:
+		list = strings.Split(t, ",")
+	case []interface{}:
+		for _, item := range t {
+			list = append(list, fmt.Sprint(item))
+		}
+	}
+	for _, item := range list {
+		if strings.TrimSpace(item) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func nucleiMatcherRule(m nucleiMatcher) string {
+	var parts []string
+	op := "||"
+	if m.Condition == "and" {
+		op = "&&"
+	}
+	switch m.Type {
+	case "word":
+		for _, word := range m.Words {
+			parts = append(parts, nucleiPartRule(m.Part, "=", word))
+		}
+	case "regex":
+		for _, re := range m.Regex {
+			parts = append(parts, nucleiPartRule(m.Part, "~=", re))
+		}
+	case "status":
+		op = "||"
+		for _, status := range m.Status {
+			parts = append(parts, condRule("status", "=", strconv.Itoa(status)))
+		}
+	case "favicon":
+		op = "||"
+		for _, hash := range m.Hash {
+			parts = append(parts, iconRule(hash))
+		}
+	case "dsl":
+		for _, expr := range m.DSL {
+			parts = append(parts, dslRule(expr))
+		}
+	}
+	rule := joinRules(op, parts)
+	if m.Negative && rule != "" {
+		rule = "!(" + rule + ")"
+	}
+	return rule
+}
+
+func nucleiPartRule(part, op, value string) string {
+	switch part {
+	case "", "body":
+		return condRule("body", op, value)
+	case "header", "all_headers":
+		return condRule("header", op, value)
+	case "all", "response", "raw":
+		return joinRules("||", []string{condRule("header", op, value), condRule("body", op, value)})
+	}
+	return ""
+}
+
+var (
+	dslContains = regexp.MustCompile(`^contains\((?:to_?lower\()?(body|header|all_headers|response)\)?,\s*["'](.*)["']\)$`)
+	dslRegex    = regexp.MustCompile(`^regex\(["'](.*)["'],\s*(body|header|all_headers|response)\)$`)
+	dslStatus   = regexp.MustCompile(`^status_code\s*==\s*(\d+)$`)
+	dslFavicon  = regexp.MustCompile(`^(?:["']?(-?\d+)["']?\s*==\s*mmh3\(base64_py\(body\)\)|mmh3\(base64_py\(body\)\)\s*==\s*["']?(-?\d+)["']?)$`)
+)
+
+// dslRule 仅转换由 && 或 || 连接的 contains、regex、status_code 与 favicon 哈希判断
+func dslRule(expr string) string {
+	op := "&&"
+	atoms := strings.Split(expr, "&&")
+	if strings.Contains(expr, "||") {
+		if len(atoms) > 1 {
+			return ""
+		}
+		op, atoms = "||", strings.Split(expr, "||")
+	}
+	var parts []string
+	for _, atom := range atoms {
+		atom = strings.TrimSpace(atom)
+		unquote := strings.NewReplacer(`\"`, `"`, `\'`, `'`)
+		if m := dslContains.FindStringSubmatch(atom); m != nil {
+			parts = append(parts, nucleiPartRule(dslPart(m[1]), "=", unquote.Replace(m[2])))
+		} else if m := dslRegex.FindStringSubmatch(atom); m != nil {
+			parts = append(parts, nucleiPartRule(dslPart(m[2]), "~=", unquote.Replace(m[1])))
+		} else if m := dslStatus.FindStringSubmatch(atom); m != nil {
+			parts = append(parts, condRule("status", "=", m[1]))
+		} else if m := dslFavicon.FindStringSubmatch(atom); m != nil {
+			parts = append(parts, condRule("icon_hash", "=", m[1]+m[2]))
+		} else {
+			return ""
+		}
+	}
+	return joinRules(op, parts)
+}
+
+func dslPart(part string) string {
+	if part == "all_headers" {
+		return "header"
+	}
+	return part
+}
+
+type wappalyzerTech struct {
+	Headers   map[string]string      `json:"headers"`
+	Cookies   map[string]string      `json:"cookies"`
+	Meta      map[string]interface{} `json:"meta"`
+	HTML      interface{}            `json:"html"`
+	Text      interface{}            `json:"text"`
+	Scripts   interface{}            `json:"scripts"`
+	ScriptSrc interface{}            `json:"scriptSrc"`
+	JS        map[string]string      `json:"js"`
+	DOM       interface{}            `json:"dom"`
+}
+
+// wappalyzer 转换首页响应中的特征，js、dom 转换为需要无头浏览器计算的 js.*、dom 规则
+func (b *importBatch) wappalyzer(data []byte) error {
+	var techs map[string]json.RawMessage
+	if err := json.Unmarshal(data, &techs); err != nil {
+		return err
+	}
+	// 旧版 apps.json 将技术放在 apps 或 technologies 字段下
+	for _, key := range []string{"technologies", "apps"} {
+		if nested, ok := techs[key]; ok {
+			techs = nil
+			if err := json.Unmarshal(nested, &techs); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	for _, name := range sortedKeys(techs) {
+		var tech wappalyzerTech
+		if err := json.Unmarshal(techs[name], &tech); err != nil {
+			b.skipped++
+			continue
+		}
+		for _, list := range []interface{}{tech.HTML, tech.Text, tech.Scripts} {
+			for _, p := range stringList(list) {
+				if p = wappalyzerPattern(p); p != "" {
+					b.add(name, "", condRule("body", "~=", p))
+				}
+			}
+		}
+		for _, p := range stringList(tech.ScriptSrc) {
+			b.add(name, "", namedValueRule("script", p))
+		}
+		for _, key := range sortedKeys(tech.Headers) {
+			b.addNamed(name, "header."+strings.ToLower(key), tech.Headers[key])
+		}
+		for _, key := range sortedKeys(tech.Cookies) {
+			if wappalyzerPattern(tech.Cookies[key]) == "" {
+				b.add(name, "", condRule("cookie", "~=", `(?m)^`+regexp.QuoteMeta(strings.ToLower(key))+`$`))
+			} else {
+				b.add(name, "", condRule("header", "~=", valuePattern(`(?m)^set-cookie:\s*`+regexp.QuoteMeta(key)+`=`, `[^;\n]*`, `[;\r\n]`, tech.Cookies[key])))
+			}
+		}
+		for _, key := range sortedKeys(tech.Meta) {
+			for _, p := range stringList(tech.Meta[key]) {
+				b.addNamed(name, "meta."+strings.ToLower(key), p)
+			}
+		}
+		for _, key := range sortedKeys(tech.JS) {
+			b.addNamed(name, "js."+key, tech.JS[key])
+		}
+		for _, selector := range wappalyzerSelectors(tech.DOM) {
+			b.add(name, "", condRule("dom", "=", selector))
+		}
+	}
+	return nil
+}
+
+// addNamed 添加 header.*、meta.*、js.* 规则，名称中存在规则关键字不支持的字符时跳过
+func (b *importBatch) addNamed(product, key, p string) {
+	for i := 0; i < len(key); i++ {
+		if !isRuleKeyChar(key[i]) {
+			b.skipped++
+			return
+		}
+	}
+	b.add(product, "", namedValueRule(key, p))
+}
+
+// namedValueRule 模式为空时仅要求该字段存在，多个值以换行分隔，^ 与 $ 对应单个值的起止位置
+func namedValueRule(key, p string) string {
+	if p = wappalyzerPattern(p); p == "" {
+		return condRule(key, "=", "")
+	}
+	return condRule(key, "~=", "(?m)"+p)
+}
+
+// wappalyzerSelectors dom 可以是选择器、选择器列表或以选择器为键的对象，对象中的 text、attributes 等条件忽略，只判断选择器是否存在
+func wappalyzerSelectors(v interface{}) []string {
+	if m, ok := v.(map[string]interface{}); ok {
+		return sortedKeys(m)
+	}
+	return stringList(v)
+}
+
+// wappalyzerPattern 去掉 \;version:\1 等附加信息
+func wappalyzerPattern(p string) string {
+	p, _, _ = strings.Cut(p, `\;`)
+	return p
+}
+
+// valuePattern 将针对单个值的正则嵌入到响应中，^ 与 $ 分别替换为值的起止位置
+func valuePattern(prefix, any, end, p string) string {
+	if p = wappalyzerPattern(p); p == "" {
+		return prefix
+	}
+	if strings.HasPrefix(p, "^") {
+		p = p[1:]
+	} else {
+		prefix += any
+	}
+	suffix := ""
+	if strings.HasSuffix(p, "$") && !strings.HasSuffix(p, `\$`) {
+		p, suffix = p[:len(p)-1], end
+	}
+	return prefix + "(?:" + p + ")" + suffix
+}
+
+func stringList(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var list []string
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var fofaStatusKey = regexp.MustCompile(`\bstatus_code(\s*[!=~<>]?=)`)
+
+// tideFinger 读取 cms_finger.db，按列识别两类表：
+// cms_name/path/match_pattern/options 为指定路径的关键字指纹，name/keys 为 fofa 语法的指纹
+func (b *importBatch) tideFinger(file string) error {
+	if _, err := os.Stat(file); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite3", file)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table'")
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if rows.Scan(&table) == nil {
+			tables = append(tables, table)
+		}
+	}
+	rows.Close()
+
+	found := false
+	for _, table := range tables {
+		columns, err := tableColumns(db, table)
+		if err != nil {
+			continue
+		}
+		switch {
+		case columns["cms_name"] && columns["path"] && columns["match_pattern"] && columns["options"]:
+			found = true
+			err = b.scanTideRows(db, fmt.Sprintf("SELECT cms_name, path, match_pattern, options FROM `%s`", table), func(values []string) {
+				path, pathCond := importPath(values[1])
+				switch values[3] {
+				case "keyword":
+					b.add(values[0], path, withPath(pathCond, condRule("body", "=", values[2])))
+				case "regx", "regex":
+					b.add(values[0], path, withPath(pathCond, condRule("body", "~=", values[2])))
+				default:
+					// md5 为整个文件的哈希，规则中无法表示
+					b.skipped++
+				}
+			})
+		case columns["name"] && columns["keys"]:
+			found = true
+			err = b.scanTideRows(db, fmt.Sprintf("SELECT name, keys FROM `%s`", table), func(values []string) {
+				b.add(values[0], "", fofaStatusKey.ReplaceAllString(strings.TrimSpace(values[1]), "status$1"))
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if !found {
+		return fmt.Errorf("%s 中未找到 TideFinger 指纹表", file)
+	}
+	return nil
+}
+
+func tableColumns(db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT name FROM pragma_table_info('%s')", strings.ReplaceAll(table, "'", "''")))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[strings.ToLower(name)] = true
+	}
+	return columns, rows.Err()
+}
+
+func (b *importBatch) scanTideRows(db *sql.DB, query string, handle func(values []string)) error {
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, _ := rows.Columns()
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			b.skipped++
+			continue
+		}
+		strs := make([]string, len(values))
+		for i, v := range values {
+			strs[i] = v.String
+		}
+		handle(strs)
+	}
+	return rows.Err()
+}
+
+// normalizeProductName 合并时忽略大小写、空格与连接符，如 Apache-Tomcat 与 apache tomcat 视为同一产品
+func normalizeProductName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_', '.':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// mergeFingerprints 校验转换后的规则并合并，同一产品下语义相同的规则只保留一条，覆盖前保留 .bak 备份
+func mergeFingerprints(b *importBatch, fingerprintFile, activeFile string) (*structs.FingerprintImportResult, error) {
+	result := &structs.FingerprintImportResult{Skipped: b.skipped}
+	fps, err := readMapSlice(fingerprintFile)
+	if err != nil {
+		return nil, err
+	}
+	compiler := newRuleCompiler()
+	index := make(map[string]int)
+	existing := make(map[string]map[string]bool)
+	for i, item := range fps {
+		product := fmt.Sprint(item.Key)
+		index[normalizeProductName(product)] = i
+		seen := make(map[string]bool)
+		rules, _ := item.Value.([]interface{})
+		for _, r := range rules {
+			rule := fmt.Sprint(r)
+			if node, err := compiler.compile(rule); err == nil {
+				rule = formatRule(node)
+			}
+			seen[rule] = true
+		}
+		existing[product] = seen
+	}
+
+	original := len(fps)
+	merged := make(map[int]bool)
+	activePaths := make(map[string][]string)
+	for _, item := range b.rules {
+		node, err := compiler.compile(item.Rule)
+		if err != nil {
+			result.Skipped++
+			continue
+		}
+		result.Converted++
+		i, ok := index[normalizeProductName(item.Product)]
+		if !ok {
+			i = len(fps)
+			index[normalizeProductName(item.Product)] = i
+			fps = append(fps, yaml.MapItem{Key: item.Product, Value: []interface{}{}})
+			existing[item.Product] = make(map[string]bool)
+			result.NewProducts++
+		}
+		product := fmt.Sprint(fps[i].Key)
+		if item.Path != "" && !arrayutil.ArrayContains(item.Path, activePaths[product]) {
+			activePaths[product] = append(activePaths[product], item.Path)
+		}
+		canonical := formatRule(node)
+		if existing[product][canonical] {
+			result.Duplicates++
+			continue
+		}
+		existing[product][canonical] = true
+		rules, _ := fps[i].Value.([]interface{})
+		fps[i].Value = append(rules, item.Rule)
+		result.Added++
+		if i < original {
+			merged[i] = true
+		}
+	}
+	result.MergedProducts = len(merged)
+
+	if result.Added > 0 {
+		if err := writeMapSlice(fingerprintFile, fps); err != nil {
+			return nil, err
+		}
+	}
+	if len(activePaths) == 0 {
+		return result, nil
+	}
+
+	dirs, err := readMapSlice(activeFile)
+	if err != nil {
+		return nil, err
+	}
+	dirIndex := make(map[string]int)
+	for i, item := range dirs {
+		dirIndex[fmt.Sprint(item.Key)] = i
+	}
+	for _, product := range sortedKeys(activePaths) {
+		i, ok := dirIndex[product]
+		if !ok {
+			i = len(dirs)
+			dirs = append(dirs, yaml.MapItem{Key: product, Value: []interface{}{}})
+		}
+		paths, _ := dirs[i].Value.([]interface{})
+		for _, path := range activePaths[product] {
+			if !arrayutil.ArrayContains(interface{}(path), paths) {
+				paths = append(paths, path)
+				result.ActivePaths++
+			}
+		}
+		dirs[i].Value = paths
+	}
+	if result.ActivePaths > 0 {
+		if err := writeMapSlice(activeFile, dirs); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func readMapSlice(file string) (yaml.MapSlice, error) {
+	var items yaml.MapSlice
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return items, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return items, yaml.Unmarshal(data, &items)
+}
+
+func writeMapSlice(file string, items yaml.MapSlice) error {
+	data, err := yaml.Marshal(items)
+	if err != nil {
+		return err
+	}
+	if original, err := os.ReadFile(file); err == nil {
+		if err := os.WriteFile(file+".bak", original, 0644); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(file, data, 0644)
+}
@@ -0,0 +1,86 @@
+package webscan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportFingerprints(t *testing.T) {
+	dir := t.TempDir()
+	fingerFile := filepath.Join(dir, "webfinger.yaml")
+	activeFile := filepath.Join(dir, "dir.yaml")
+	os.WriteFile(fingerFile, []byte("Apache-Tomcat:\n  - 'title=\"apache tomcat\"'\n"), 0644)
+
+	sources := map[string]string{
+		"finger.json": `{"fingerprint":[
+			{"cms":"apache tomcat","method":"keyword","location":"title","keyword":["Apache Tomcat"]},
+			{"cms":"seeyon","method":"keyword","location":"body","keyword":["/seeyon/USER-DATA/IMAGES/LOGIN/login.gif","seeyon"]},
+			{"cms":"seeyon","method":"faviconhash","location":"body","keyword":["-1234"]}]}`,
+		"wappalyzer.json": `{"Nginx":{"headers":{"Server":"nginx(?:/([\\d.]+))?\\;version:\\1"}},
+			"WordPress":{"meta":{"generator":"^WordPress ?([\\d.]+)?\\;version:\\1"},"html":"<link rel=[\"']stylesheet[\"'] [^>]+/wp-(?:content|includes)/"},
+			"Vue.js":{"js":{"Vue.version":"^(.+)$\\;version:\\1"},"dom":"div[data-v-app]"}}`,
+		"tech-detect.yaml": `id: tech-detect
+info:
+  name: Wappalyzer Technology Detection
+  tags: tech
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}"
+      - "{{BaseURL}}/login.jsp"
+    matchers:
+      - type: word
+        name: jenkins
+        part: header
+        words:
+          - "X-Jenkins"
+      - type: dsl
+        name: grafana
+        dsl:
+          - 'contains(tolower(body), "grafana") && status_code == 200'
+`,
+	}
+	formats := map[string]string{"finger.json": ImportEHole, "wappalyzer.json": ImportWappalyzer, "tech-detect.yaml": ImportNuclei}
+	for name, content := range sources {
+		source := filepath.Join(dir, name)
+		os.WriteFile(source, []byte(content), 0644)
+		if _, err := ImportFingerprints(formats[name], source, fingerFile, activeFile); err != nil {
+			t.Fatalf("ImportFingerprints(%s) returned an error: %v", name, err)
+		}
+	}
+
+	report, err := LintFingerprints(fingerFile, activeFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(LintError) != 0 {
+		t.Fatalf("imported rules have errors: %v", report.Issues)
+	}
+	data, _ := os.ReadFile(fingerFile)
+	for _, want := range []string{`body="/seeyon/USER-DATA/IMAGES/LOGIN/login.gif" && body="seeyon"`, `icon_hash="-1234"`, `jenkins:`, `path="/login.jsp" && header="X-Jenkins"`, `body="grafana" && status="200"`, `header.server~="(?m)nginx(?:/([\d.]+))?"`, `js.Vue.version~="(?m)^(.+)$"`, `dom="div[data-v-app]"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("webfinger.yaml missing %s:\n%s", want, data)
+		}
+	}
+	// apache tomcat 与已有的 Apache-Tomcat 规则相同，应当合并去重
+	if strings.Contains(string(data), "apache tomcat:") || strings.Count(string(data), "apache tomcat") != 1 {
+		t.Errorf("duplicate product was not merged:\n%s", data)
+	}
+	if dirs, _ := os.ReadFile(activeFile); !strings.Contains(string(dirs), "/login.jsp") {
+		t.Errorf("dir.yaml missing /login.jsp:\n%s", dirs)
+	}
+
+	db := &Config{}
+	FingerprintDB = nil
+	if err := db.InitFingprintDB(context.Background(), fingerFile); err != nil {
+		t.Fatal(err)
+	}
+	web := &WebInfo{HeadeString: "http/1.1 200 ok\r\nserver: nginx/1.20.1\r\n", BodyString: `<meta name="generator" content="wordpress 6.1">`}
+	web.parseResponse([]byte(web.BodyString))
+	if got := Scan(web, FingerprintDB); len(got) != 2 {
+		t.Errorf("Scan() = %v, want Nginx and WordPress", got)
+	}
+}
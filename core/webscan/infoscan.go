@@ -3,25 +3,36 @@ package webscan
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"net"
 	"net/url"
+	"slack-wails/core/crawler"
 	"slack-wails/core/subdomain"
 	"slack-wails/core/waf"
 	"slack-wails/lib/gologger"
 	"slack-wails/lib/gomessage"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"slack-wails/lib/utils/arrayutil"
 	"slack-wails/lib/utils/httputil"
 	"slack-wails/lib/utils/randutil"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/qiwentaidi/clients"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/go-resty/resty/v2"
 	"github.com/panjf2000/ants/v2"
+	"github.com/twmb/murmur3"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
@@ -42,22 +53,98 @@ type WebInfo struct {
 	ContentType   string
 	Server        string
 	ContentLength int
-	Banner        string // tcp指纹
-	Cert          string // TLS证书
+	Banner        string            // tcp指纹
+	Cert          string            // TLS证书
+	JARM          string            // TLS 服务端 JARM 指纹
+	Headers       map[string]string // 响应头名称 -> 值，同名响应头换行拼接
+	Cookies       string            // Set-Cookie 中的 Cookie 名称，换行分隔
+	Location      string            // 跳转地址
+	BodyHash      string            // 响应体 mmh3
+	BodyMd5       string            // 响应体 md5
+	Meta          map[string]string // meta 标签 name/property/http-equiv -> content
+	Scripts       string            // script 标签的 src，换行分隔
+	JSGlobals     map[string]string // 无头浏览器中读取到的 JS 全局变量
+	DOM           map[string]bool   // 无头浏览器中各 CSS 选择器是否存在
+}
+
+// parseResponse 从小写的响应头与响应体中提取具名响应头、Cookie、跳转地址、meta 与 script，rawBody 用于计算响应体哈希
+func (web *WebInfo) parseResponse(rawBody []byte) {
+	web.Headers = make(map[string]string)
+	var cookies []string
+	for _, line := range strings.Split(web.HeadeString, "\n") {
+		name, value, ok := strings.Cut(strings.TrimRight(line, "\r"), ":")
+		if !ok || strings.HasPrefix(name, "http/") {
+			continue
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if web.Headers[name] != "" {
+			web.Headers[name] += "\n" + value
+		} else {
+			web.Headers[name] = value
+		}
+		switch name {
+		case "set-cookie":
+			cookie, _, _ := strings.Cut(value, "=")
+			if cookie = strings.TrimSpace(cookie); cookie != "" && !arrayutil.ArrayContains(cookie, cookies) {
+				cookies = append(cookies, cookie)
+			}
+		case "location":
+			web.Location = value
+		}
+	}
+	web.Cookies = strings.Join(cookies, "\n")
+
+	web.BodyHash = fmt.Sprint(int32(murmur3.Sum32(rawBody)))
+	sum := md5.Sum(rawBody)
+	web.BodyMd5 = hex.EncodeToString(sum[:])
+
+	web.Meta = make(map[string]string)
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(web.BodyString))
+	if err != nil {
+		return
+	}
+	doc.Find("meta[content]").Each(func(_ int, meta *goquery.Selection) {
+		for _, attr := range []string{"name", "property", "http-equiv"} {
+			if name, ok := meta.Attr(attr); ok && name != "" {
+				web.Meta[strings.TrimSpace(name)], _ = meta.Attr("content")
+				break
+			}
+		}
+	})
+	var scripts []string
+	doc.Find("script[src]").Each(func(_ int, script *goquery.Selection) {
+		if src, _ := script.Attr("src"); src != "" {
+			scripts = append(scripts, src)
+		}
+	})
+	web.Scripts = strings.Join(scripts, "\n")
 }
 
 type FingerScanner struct {
 	ctx                     context.Context
 	taskId                  string // 任务ID
 	urls                    []*url.URL
-	aliveURLs               []*url.URL          // 默认指纹扫描结束后，存活的URL，以便后续主动指纹过滤目标
-	screenshot              bool                // 是否截屏
-	thread                  int                 // 指纹线程
-	deepScan                bool                // 代表主动指纹探测
-	rootPath                bool                // 主动指纹是否采取根路径扫描
-	basicURLWithFingerprint map[string][]string // 后续nuclei需要扫描的目标列表
-	headers                 map[string]string   // 请求头
-	generateLog4j2          bool                // 是否添加Log4j2指纹，后续nuclei可以添加扫描
+	aliveURLs               []*url.URL                             // 默认指纹扫描结束后，存活的URL，以便后续主动指纹过滤目标
+	screenshot              bool                                   // 是否截屏
+	thread                  int                                    // 指纹线程
+	deepScan                bool                                   // 代表主动指纹探测
+	rootPath                bool                                   // 主动指纹是否采取根路径扫描
+	basicURLWithFingerprint map[string][]string                    // 后续nuclei需要扫描的目标列表
+	basicURLWithDetails     map[string][]structs.FingerprintDetail // 目标指纹的版本信息，用于挑选 CVE 模板
+	headers                 map[string]string                      // 请求头
+	generateLog4j2          bool                                   // 是否添加Log4j2指纹，后续nuclei可以添加扫描
+	wafProbe                bool                                   // CNAME 未识别到 WAF 时主动探测
+	tlsProbe                bool                                   // 计算 JARM 并探测弱协议与弱加密套件
+	proxyURL                string                                 // 证书获取等直接建立的连接同样经过代理
+	honeypotHosts           *HoneypotHosts                         // 蜜罐评分所需的主机开放端口与 Server 汇总
+	candidateDomains        map[string]bool                        // 证书 SAN 中发现的、不在扫描目标中的域名
+	vhostDiscovery          bool                                   // 对 IP 形式的网站目标发现虚拟主机
+	vhostDomains            []string                               // 额外的虚拟主机候选域名或字典单词
+	vhostSubdomains         []string                               // 当前项目的子域名
+	vhostICPDomains         []string                               // 当前项目的 ICP 备案域名
+	vhosts                  map[string]string                      // 虚拟主机 域名:端口 -> 实际访问的 IP:端口
+	crawlOptions            crawler.Options                        // 爬虫配置，Crawl 未开启时为空
+	crawled                 map[string][]structs.CrawlEndpoint     // 存活网站 -> 爬取到的请求
 	client                  *resty.Client
 	notFollowClient         *resty.Client
 	mutex                   sync.RWMutex
@@ -102,6 +189,15 @@ func NewWebscanEngine(ctx context.Context, taskId string, proxyURL string, optio
 		}
 	}
 
+	// 端口扫描传入的全部目标用于统计同一主机的开放端口数
+	honeypotHosts := NewHoneypotHosts()
+	for _, u := range urls {
+		honeypotHosts.AddTarget(u.String())
+	}
+	for target := range options.TcpTarget {
+		honeypotHosts.AddTarget(target)
+	}
+
 	// 可以兼容其他协议目标进行漏洞扫描
 	basicURLWithFingerprint := make(map[string][]string)
 	var mutex sync.RWMutex
@@ -116,6 +212,13 @@ func NewWebscanEngine(ctx context.Context, taskId string, proxyURL string, optio
 		gologger.Error(ctx, "No available targets found, please check input")
 		return nil
 	}
+	crawlOptions := crawler.DefaultOptions
+	crawlOptions.Scope = options.CrawlScope
+	crawlOptions.Headless = options.CrawlHeadless
+	crawlOptions.Headers = clients.Str2HeadersMap(options.CustomHeaders)
+	if options.CrawlDepth > 0 {
+		crawlOptions.MaxDepth = options.CrawlDepth
+	}
 	return &FingerScanner{
 		ctx:                     ctx,
 		taskId:                  taskId,
@@ -127,18 +230,37 @@ func NewWebscanEngine(ctx context.Context, taskId string, proxyURL string, optio
 		deepScan:                options.DeepScan,
 		rootPath:                options.RootPath,
 		basicURLWithFingerprint: basicURLWithFingerprint,
+		basicURLWithDetails:     make(map[string][]structs.FingerprintDetail),
 		headers:                 clients.Str2HeadersMap(options.CustomHeaders),
 		generateLog4j2:          options.GenerateLog4j2,
+		wafProbe:                options.WAFProbe,
+		tlsProbe:                options.TLSProbe,
+		proxyURL:                proxyURL,
+		honeypotHosts:           honeypotHosts,
+		candidateDomains:        make(map[string]bool),
+		vhostDiscovery:          options.VHostDiscovery,
+		vhostDomains:            options.VHostDomains,
+		vhostSubdomains:         options.VHostSubdomains,
+		vhostICPDomains:         options.VHostICPDomains,
+		crawlOptions:            crawlOptions,
+		crawled:                 make(map[string][]structs.CrawlEndpoint),
 	}
 }
 
 func (s *FingerScanner) FingerScan(ctrlCtx context.Context) {
+	s.fingerScan(ctrlCtx, s.urls)
+	if s.vhostDiscovery && ctrlCtx.Err() == nil {
+		s.VHostScan(ctrlCtx)
+	}
+}
+
+func (s *FingerScanner) fingerScan(ctrlCtx context.Context, urls []*url.URL) {
 	var wg sync.WaitGroup
 	single := make(chan struct{})
-	retChan := make(chan structs.InfoResult, len(s.urls))
+	retChan := make(chan structs.InfoResult, len(urls))
 	go func() {
 		for pr := range retChan {
-			runtime.EventsEmit(s.ctx, "webFingerScan", pr)
+			resultstore.Fingerprint(s.ctx, pr)
 		}
 		close(single)
 	}()
@@ -196,6 +318,8 @@ func (s *FingerScanner) FingerScan(ctrlCtx context.Context) {
 		// 发送shiro探测
 		rawHeaders = append(rawHeaders, fmt.Appendf(nil, "Set-Cookie: %s", s.ShiroScan(u))...)
 
+		// 响应体哈希只计算首页本身
+		rawBody := body
 		// 跟随JS重定向，并替换成重定向后的数据
 		redirectBody := s.GetJSRedirectResponse(u, string(body))
 		if redirectBody != nil {
@@ -208,10 +332,12 @@ func (s *FingerScanner) FingerScan(ctrlCtx context.Context) {
 		server = resp.Header().Get("Server")
 		contentType = resp.Header().Get("Content-Type")
 		statusCode = resp.StatusCode()
+		cert, tlsInfo := GetTLSInfo(u.Scheme, s.address(u), TLSProbeOptions{ServerName: u.Hostname(), ProxyURL: s.proxyURL, Deep: s.tlsProbe})
+		s.addCandidateDomains(SANDomains(tlsInfo))
 		web := &WebInfo{
 			HeadeString:   strings.ToLower(string(rawHeaders)),
 			ContentType:   strings.ToLower(contentType),
-			Cert:          strings.ToLower(GetTLSString(u.Scheme, u.Host)),
+			Cert:          strings.ToLower(cert),
 			BodyString:    strings.ToLower(string(body)),
 			Path:          strings.ToLower(u.Path),
 			Title:         strings.ToLower(title),
@@ -222,12 +348,29 @@ func (s *FingerScanner) FingerScan(ctrlCtx context.Context) {
 			IconMd5:       faviconMd5,
 			StatusCode:    statusCode,
 		}
+		if tlsInfo != nil {
+			web.JARM = tlsInfo.JARM
+		}
+		web.parseResponse(rawBody)
+		// 开启截屏时浏览器可用，计算规则中引用的 JS 全局变量与 DOM 选择器，浏览器无法解析虚拟主机域名
+		if s.screenshot && (u.Scheme == "https" || u.Scheme == "http") && !s.isVHost(u) && (len(browserGlobals) > 0 || len(browserSelectors) > 0) {
+			if web.JSGlobals, web.DOM, err = EvaluatePage(u.String(), browserGlobals, browserSelectors); err != nil {
+				gologger.Debug(s.ctx, err)
+			}
+		}
 
 		wafInfo := *waf.ResolveAndWafIdentify(u.Hostname(), subdomain.DefaultDnsServers)
+		if !wafInfo.Exsits && s.wafProbe {
+			wafInfo = *waf.ActiveDetect(u.String(), s.headers, s.notFollowClient)
+		}
+		if wafInfo.Exsits {
+			waf.Remember(u.Hostname(), wafInfo.Name)
+		}
 
 		s.aliveURLs = append(s.aliveURLs, u)
 
-		fingerprints := Scan(s.ctx, web, FingerprintDB)
+		fingerprints, versions := Identify(web, FingerprintDB)
+		details := FingerprintDetails(fingerprints, versions)
 
 		if s.generateLog4j2 {
 			fingerprints = append(fingerprints, "Generate-Log4j2")
@@ -237,24 +380,19 @@ func (s *FingerScanner) FingerScan(ctrlCtx context.Context) {
 			fingerprints = append(fingerprints, "Fastjson")
 		}
 
-		if checkHoneypotWithHeaders(web.HeadeString) || checkHoneypotWithFingerprintLength(len(fingerprints)) {
-			fingerprints = []string{"疑似蜜罐"}
-		}
-
 		// 截屏
-		var screenshotPath string
+		var screenshotPath, screenshotHash string
 		// 截屏条件要满足协议, fix in v2.0.8
-		if s.screenshot && (u.Scheme == "https" || u.Scheme == "http") {
+		if s.screenshot && (u.Scheme == "https" || u.Scheme == "http") && !s.isVHost(u) {
 			if screenshotPath, err = GetScreenshot(u.String()); err != nil {
 				gologger.Debug(s.ctx, err)
+			} else if screenshotHash, err = ScreenshotHash(screenshotPath); err != nil {
+				gologger.Debug(s.ctx, err)
 			}
 		}
 
-		s.mutex.Lock()
-		s.basicURLWithFingerprint[u.String()] = append(s.basicURLWithFingerprint[u.String()], fingerprints...)
-		s.mutex.Unlock()
-
-		retChan <- structs.InfoResult{
+		// 蜜罐评分依赖同一主机其他端口的识别结果，结果暂存到该主机全部识别完成
+		s.honeypotHosts.Hold(u.Hostname(), structs.InfoResult{
 			TaskId:       s.taskId,
 			URL:          u.String(),
 			Scheme:       u.Scheme,
@@ -268,34 +406,56 @@ func (s *FingerScanner) FingerScan(ctrlCtx context.Context) {
 			WAF:          wafInfo.Name,
 			Detect:       "Default",
 			Screenshot:   screenshotPath,
+
+			FingerprintDetails: details,
+			ScreenshotHash:     screenshotHash,
+			TLSInfo:            tlsInfo,
+		}, HoneypotEvidence{
+			Web:          web,
+			Fingerprints: len(fingerprints),
+			TLS:          tlsInfo,
+		})
+	}
+	release := func(results []structs.InfoResult) {
+		for _, result := range results {
+			s.mutex.Lock()
+			s.basicURLWithFingerprint[result.URL] = append(s.basicURLWithFingerprint[result.URL], result.Fingerprints...)
+			s.basicURLWithDetails[result.URL] = append(s.basicURLWithDetails[result.URL], result.FingerprintDetails...)
+			s.mutex.Unlock()
+			retChan <- result
 		}
 	}
+	for _, u := range urls {
+		s.honeypotHosts.Expect(u.Hostname())
+	}
 	threadPool, _ := ants.NewPoolWithFunc(s.thread, func(target interface{}) {
 		defer wg.Done()
-		if ctrlCtx.Err() != nil {
-			return
-		}
 		t := target.(*url.URL)
-		fscan(t)
+		if ctrlCtx.Err() == nil {
+			fscan(t)
+		}
+		release(s.honeypotHosts.Done(t.Hostname()))
 	})
 	defer threadPool.Release()
-	for _, target := range s.urls {
+	for _, target := range urls {
 		if ctrlCtx.Err() != nil {
-			return
+			break
 		}
 		wg.Add(1)
 		threadPool.Invoke(target)
 	}
 	wg.Wait()
+	release(s.honeypotHosts.Flush())
 	close(retChan)
 	gologger.Info(s.ctx, "FingerScan Finished")
 	<-single
 }
 
 type ActiveFingerDetect struct {
-	URL  *url.URL
-	Fpe  []FingerPEntity
-	Path string
+	URL         *url.URL
+	ProductName string
+	Fpe         []FingerPEntity
+	Probe       ActiveProbe
 }
 
 const activeTimeoutLimit = 15 // 超过该次数就不再扫描该目标
@@ -315,7 +475,7 @@ func (s *FingerScanner) ActiveFingerScan(ctrlCtx context.Context) {
 
 	go func() {
 		for pr := range retChan {
-			runtime.EventsEmit(s.ctx, "webFingerScan", pr)
+			resultstore.Fingerprint(s.ctx, pr)
 		}
 		close(single)
 	}()
@@ -324,7 +484,8 @@ func (s *FingerScanner) ActiveFingerScan(ctrlCtx context.Context) {
 	threadPool, _ := ants.NewPoolWithFunc(s.thread, func(tfp interface{}) {
 		defer wg.Done()
 		fp := tfp.(ActiveFingerDetect)
-		fullURL := fp.URL.String() + fp.Path
+		probe := fp.Probe
+		fullURL := fp.URL.String() + probe.Path
 		baseURL := fp.URL.String()
 
 		// 检查是否已超出超时限制
@@ -333,43 +494,90 @@ func (s *FingerScanner) ActiveFingerScan(ctrlCtx context.Context) {
 			return
 		}
 
-		// 去重：URL + path
+		// 去重：请求方式 + URL + path + body
 		// 使用 sync.Map 检查是否已访问
-		if _, ok := visited.Load(fullURL); ok {
+		key := probe.Method + " " + fullURL + "\n" + probe.Body
+		if _, ok := visited.Load(key); ok {
 			return
 		}
-		visited.Store(fullURL, true)
+		visited.Store(key, true)
 
-		resp, err := clients.DoRequest("GET", fullURL, s.headers, nil, 5, s.client)
-		if err != nil {
-			// 累计超时次数
-			v, _ := timeoutCounter.LoadOrStore(baseURL, 1)
-			timeoutCounter.Store(baseURL, v.(int)+1)
-			return
-		}
+		var ti *WebInfo
+		var title string
+		if probe.Method == "RAW" {
+			banner, err := s.rawProbe(fp.URL, probe.Body)
+			if err != nil {
+				v, _ := timeoutCounter.LoadOrStore(baseURL, 1)
+				timeoutCounter.Store(baseURL, v.(int)+1)
+				return
+			}
+			ti = &WebInfo{
+				Banner:        strings.ToLower(banner),
+				BodyString:    strings.ToLower(banner),
+				ContentLength: len(banner),
+				Port:          httputil.GetPort(fp.URL),
+			}
+			ti.parseResponse([]byte(banner))
+		} else {
+			headers := s.headers
+			if len(probe.Headers) > 0 {
+				headers = make(map[string]string, len(s.headers)+len(probe.Headers))
+				for k, v := range s.headers {
+					headers[k] = v
+				}
+				for k, v := range probe.Headers {
+					headers[k] = v
+				}
+			}
+			var reqBody io.Reader
+			if probe.Body != "" {
+				reqBody = strings.NewReader(probe.Body)
+			}
+			resp, err := clients.DoRequest(probe.Method, fullURL, headers, reqBody, 5, s.client)
+			if err != nil {
+				// 累计超时次数
+				v, _ := timeoutCounter.LoadOrStore(baseURL, 1)
+				timeoutCounter.Store(baseURL, v.(int)+1)
+				return
+			}
 
-		body := resp.Body()
-		server := resp.Header().Get("Server")
-		contentType := resp.Header().Get("Content-Type")
-		title := clients.GetTitle(body)
+			body := resp.Body()
+			server := resp.Header().Get("Server")
+			contentType := resp.Header().Get("Content-Type")
+			title = clients.GetTitle(body)
+
+			respHeaders, _, _ := httputil.DumpResponseHeadersAndRaw(resp.RawResponse)
+			ti = &WebInfo{
+				HeadeString:   strings.ToLower(string(respHeaders)),
+				ContentType:   strings.ToLower(contentType),
+				BodyString:    strings.ToLower(string(body)),
+				Path:          strings.ToLower(probe.Path),
+				Title:         strings.ToLower(title),
+				Server:        strings.ToLower(server),
+				ContentLength: len(body),
+				Port:          httputil.GetPort(fp.URL),
+				StatusCode:    resp.StatusCode(),
+			}
+			ti.parseResponse(body)
+		}
 
-		headers, _, _ := httputil.DumpResponseHeadersAndRaw(resp.RawResponse)
-		ti := &WebInfo{
-			HeadeString:   strings.ToLower(string(headers)),
-			ContentType:   strings.ToLower(contentType),
-			BodyString:    strings.ToLower(string(body)),
-			Path:          strings.ToLower(fp.Path),
-			Title:         strings.ToLower(title),
-			Server:        strings.ToLower(server),
-			ContentLength: len(body),
-			Port:          httputil.GetPort(fp.URL),
-			StatusCode:    resp.StatusCode(),
+		var result []string
+		var versions map[string]string
+		if probe.fpe != nil {
+			// 指定了 match 时以规则为准，不再排除 404
+			result, versions = Identify(ti, probe.fpe)
+		} else {
+			result, versions = Identify(ti, fp.Fpe)
+			if ti.StatusCode == 404 && !arrayutil.ArrayContains("ThinkPHP", result) {
+				result = nil
+			}
 		}
-		result := Scan(s.ctx, ti, fp.Fpe)
 
-		if (len(result) > 0 && ti.StatusCode != 404) || arrayutil.ArrayContains("ThinkPHP", result) {
+		if len(result) > 0 {
+			details := FingerprintDetails(result, versions)
 			s.mutex.Lock()
 			s.basicURLWithFingerprint[fp.URL.String()] = append(s.basicURLWithFingerprint[fp.URL.String()], result...)
+			s.basicURLWithDetails[fp.URL.String()] = append(s.basicURLWithDetails[fp.URL.String()], details...)
 			s.mutex.Unlock()
 
 			retChan <- structs.InfoResult{
@@ -378,11 +586,13 @@ func (s *FingerScanner) ActiveFingerScan(ctrlCtx context.Context) {
 				StatusCode:   ti.StatusCode,
 				Length:       ti.ContentLength,
 				Title:        title,
-				Fingerprints: []string{fp.Fpe[0].ProductName},
+				Fingerprints: []string{fp.ProductName},
 				Detect:       "Active",
 				Port:         ti.Port,
 				Scheme:       fp.URL.Scheme,
 				Host:         fp.URL.Host,
+
+				FingerprintDetails: details,
 			}
 		}
 	})
@@ -391,9 +601,16 @@ func (s *FingerScanner) ActiveFingerScan(ctrlCtx context.Context) {
 	s.ActiveCounts()
 
 	// 开始提交任务
-	for _, target := range s.aliveURLs {
+	for _, alive := range s.aliveURLs {
+		target := alive
+		if s.rootPath {
+			target, _ = url.Parse(httputil.GetBasicURL(alive.String()))
+		}
+		s.mutex.Lock()
+		fingerprints := append([]string{}, s.basicURLWithFingerprint[alive.String()]...)
+		s.mutex.Unlock()
 		for _, item := range ActiveFingerprintDB {
-			for _, path := range item.Path {
+			for _, probe := range item.Probes {
 				if ctrlCtx.Err() != nil {
 					return
 				}
@@ -403,18 +620,20 @@ func (s *FingerScanner) ActiveFingerScan(ctrlCtx context.Context) {
 					s.IncreaseActiveProgress(&id)
 					continue // 已超时限制，跳过该目标
 				}
+				// 依赖的被动指纹未识别到时跳过
+				if len(probe.Requires) > 0 && !hasAnyFingerprint(fingerprints, probe.Requires) {
+					s.IncreaseActiveProgress(&id)
+					continue
+				}
 
 				wg.Add(1)
 				s.IncreaseActiveProgress(&id)
 
-				if s.rootPath {
-					target, _ = url.Parse(httputil.GetBasicURL(target.String()))
-				}
-
 				threadPool.Invoke(ActiveFingerDetect{
-					URL:  target,
-					Fpe:  item.Fpe,
-					Path: path,
+					URL:         target,
+					ProductName: item.ProductName,
+					Fpe:         item.Fpe,
+					Probe:       probe,
 				})
 			}
 		}
@@ -426,11 +645,55 @@ func (s *FingerScanner) ActiveFingerScan(ctrlCtx context.Context) {
 	<-single
 }
 
+// hasAnyFingerprint 忽略大小写判断已识别的指纹中是否存在任意一个依赖的指纹
+func hasAnyFingerprint(fingerprints, requires []string) bool {
+	for _, require := range requires {
+		for _, fingerprint := range fingerprints {
+			if strings.EqualFold(fingerprint, require) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rawProbe 直接向网站端口发送原始数据并读取返回内容，HTTPS 目标先完成 TLS 握手，如 Weblogic 的 T3 协议握手，设置代理时经代理连接
+func (s *FingerScanner) rawProbe(u *url.URL, payload string) (string, error) {
+	address := s.address(u)
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, strconv.Itoa(httputil.GetPort(u)))
+	}
+	dialer, err := newProxyDialer(s.proxyURL, 5*time.Second)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{InsecureSkipVerify: true, ServerName: u.Hostname()})
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte(payload)); err != nil {
+		return "", err
+	}
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if n == 0 {
+		return "", err
+	}
+	return string(buf[:n]), nil
+}
+
 // 统计主动指纹总共要扫描的目标
 func (s *FingerScanner) ActiveCounts() {
 	var id = 0
 	for _, afdb := range ActiveFingerprintDB {
-		id += len(afdb.Path)
+		id += len(afdb.Probes)
 	}
 	count := len(s.aliveURLs) * id
 	runtime.EventsEmit(s.ctx, "ActiveCounts", count)
@@ -441,74 +704,74 @@ func (s *FingerScanner) IncreaseActiveProgress(id *int32) {
 	runtime.EventsEmit(s.ctx, "ActiveProgressID", id)
 }
 
+func (s *FingerScanner) addCandidateDomains(domains []string) {
+	if len(domains) == 0 {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, domain := range domains {
+		s.candidateDomains[domain] = true
+	}
+}
+
+// CandidateDomains 证书 SAN 中发现的新域名，已在扫描目标中的主机不再返回
+func (s *FingerScanner) CandidateDomains() []string {
+	scanned := make(map[string]bool, len(s.urls))
+	for _, u := range s.urls {
+		scanned[strings.ToLower(u.Hostname())] = true
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var domains []string
+	for domain := range s.candidateDomains {
+		if !scanned[domain] {
+			domains = append(domains, domain)
+		}
+	}
+	sort.Strings(domains)
+	return domains
+}
+
 func (s *FingerScanner) URLWithFingerprintMap() map[string][]string {
 	return s.basicURLWithFingerprint
 }
 
-func Scan(ctx context.Context, web *WebInfo, targetDB []FingerPEntity) []string {
-	var fingerPrintResults []string
-
-	for _, finger := range targetDB {
-		expr := finger.AllString
-		for _, rule := range finger.Rule {
-			var result bool
-			switch rule.Key {
-			case "header":
-				result = dataCheckString(rule.Op, web.HeadeString, rule.Value)
-			case "body":
-				result = dataCheckString(rule.Op, web.BodyString, rule.Value)
-			case "server":
-				result = dataCheckString(rule.Op, web.Server, rule.Value)
-			case "title":
-				result = dataCheckString(rule.Op, web.Title, rule.Value)
-			case "cert":
-				result = dataCheckString(rule.Op, web.Cert, rule.Value)
-			case "port":
-				value, err := strconv.Atoi(rule.Value)
-				if err == nil {
-					result = dataCheckInt(rule.Op, web.Port, value)
-				}
-			case "protocol":
-				result = (rule.Op == 0 && web.Protocol == rule.Value) || (rule.Op == 1 && web.Protocol != rule.Value)
-			case "path":
-				result = dataCheckString(rule.Op, web.Path, rule.Value)
-			case "icon_hash":
-				value, err := strconv.Atoi(rule.Value)
-				hashIcon, errHash := strconv.Atoi(web.IconHash)
-				if err == nil && errHash == nil {
-					result = dataCheckInt(rule.Op, hashIcon, value)
-				}
-			case "icon_mdhash":
-				result = dataCheckString(rule.Op, web.IconMd5, rule.Value)
-			case "status":
-				value, err := strconv.Atoi(rule.Value)
-				if err == nil {
-					result = dataCheckInt(rule.Op, web.StatusCode, value)
-				}
-			case "content_type":
-				result = dataCheckString(rule.Op, web.ContentType, rule.Value)
-			case "banner":
-				result = dataCheckString(rule.Op, web.Banner, rule.Value)
-			}
+func (s *FingerScanner) URLWithFingerprintDetails() map[string][]structs.FingerprintDetail {
+	return s.basicURLWithDetails
+}
 
-			if result {
-				expr = expr[:rule.Start] + "T" + expr[rule.End:]
-			} else {
-				expr = expr[:rule.Start] + "F" + expr[rule.End:]
-			}
-		}
+// Scan 使用加载时编译好的规则识别指纹，同一产品命中后不再计算其余规则
+func Scan(web *WebInfo, targetDB []FingerPEntity) []string {
+	fingerprints, _ := Identify(web, targetDB)
+	return fingerprints
+}
 
-		r, err := boolEval(expr)
-		if err != nil {
-			gologger.DualLog(ctx, gologger.Level_ERROR, fmt.Sprintf("[fingerprint] 错误指纹: %v", finger.AllString))
+// Identify 识别指纹并返回规则中 version 命名分组提取到的产品版本。
+// 产品已命中但尚未得到版本时，继续计算该产品可以提取版本的规则
+func Identify(web *WebInfo, targetDB []FingerPEntity) ([]string, map[string]string) {
+	var fingerPrintResults []string
+	versions := make(map[string]string)
+	state := newMatchState(web)
+	matched := make(map[string]bool)
+	for _, finger := range targetDB {
+		if matched[finger.ProductName] && (!finger.versioned || versions[finger.ProductName] != "") {
 			continue
 		}
-		if r {
-			fingerPrintResults = append(fingerPrintResults, finger.ProductName)
+		state.version = ""
+		if finger.Rule.eval(state) {
+			if !matched[finger.ProductName] {
+				matched[finger.ProductName] = true
+				fingerPrintResults = append(fingerPrintResults, finger.ProductName)
+			}
+			if state.version != "" && versions[finger.ProductName] == "" {
+				versions[finger.ProductName] = state.version
+			}
 		}
 	}
+	identifyVersions(state, fingerPrintResults, versions)
 
-	return arrayutil.RemoveDuplicates(fingerPrintResults)
+	return fingerPrintResults, versions
 }
 
 func (s *FingerScanner) GetJSRedirectResponse(u *url.URL, respRaw string) []byte {
@@ -0,0 +1,537 @@
+package webscan
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	LintError   = "error"   // 规则无法正常工作
+	LintWarning = "warning" // 规则可以工作但存在问题
+)
+
+// 真值表分析的条件数量上限，超过后跳过可达性检查
+const maxLintConds = 12
+
+type LintIssue struct {
+	Level   string
+	File    string
+	Product string
+	Rule    string
+	Message string
+}
+
+func (issue LintIssue) String() string {
+	s := fmt.Sprintf("[%s] %s", issue.Level, issue.File)
+	if issue.Product != "" {
+		s += " " + issue.Product
+	}
+	if issue.Rule != "" {
+		s += " `" + issue.Rule + "`"
+	}
+	return s + ": " + issue.Message
+}
+
+type LintReport struct {
+	Products int
+	Rules    int
+	Samples  int
+	Issues   []LintIssue
+}
+
+func (report *LintReport) Count(level string) int {
+	var count int
+	for _, issue := range report.Issues {
+		if issue.Level == level {
+			count++
+		}
+	}
+	return count
+}
+
+func (report *LintReport) add(level, file, product, rule, message string) {
+	report.Issues = append(report.Issues, LintIssue{Level: level, File: file, Product: product, Rule: rule, Message: message})
+}
+
+// FingerprintSample 指纹回归样本，字段与规则关键字一一对应
+type FingerprintSample struct {
+	Protocol    string `yaml:"protocol"`
+	Port        int    `yaml:"port"`
+	Path        string `yaml:"path"`
+	Status      int    `yaml:"status"`
+	Title       string `yaml:"title"`
+	Header      string `yaml:"header"`
+	Body        string `yaml:"body"`
+	Server      string `yaml:"server"`
+	ContentType string `yaml:"content_type"`
+	Cert        string `yaml:"cert"`
+	IconHash    string `yaml:"icon_hash"`
+	IconMd5     string `yaml:"icon_mdhash"`
+	Banner      string `yaml:"banner"`
+	JARM        string `yaml:"jarm"`
+	Version     string `yaml:"version"` // 正样本期望提取到的版本，为空时不检查
+
+	JS  map[string]string `yaml:"js"`  // 无头浏览器中的 JS 全局变量
+	DOM []string          `yaml:"dom"` // 页面中存在的 CSS 选择器
+}
+
+// FingerprintSamples 产品的正样本必须命中，负样本不能命中
+type FingerprintSamples struct {
+	Positive []FingerprintSample `yaml:"positive"`
+	Negative []FingerprintSample `yaml:"negative"`
+}
+
+// webInfo 与扫描时一致，除协议外的文本字段统一转为小写
+func (sample *FingerprintSample) webInfo() *WebInfo {
+	web := &WebInfo{
+		Protocol:    sample.Protocol,
+		Port:        sample.Port,
+		Path:        strings.ToLower(sample.Path),
+		StatusCode:  sample.Status,
+		Title:       strings.ToLower(sample.Title),
+		HeadeString: strings.ToLower(sample.Header),
+		BodyString:  strings.ToLower(sample.Body),
+		Server:      strings.ToLower(sample.Server),
+		ContentType: strings.ToLower(sample.ContentType),
+		Cert:        strings.ToLower(sample.Cert),
+		IconHash:    sample.IconHash,
+		IconMd5:     strings.ToLower(sample.IconMd5),
+		Banner:      strings.ToLower(sample.Banner),
+		JARM:        strings.ToLower(sample.JARM),
+	}
+	web.ContentLength = len(sample.Body)
+	web.parseResponse([]byte(sample.Body))
+	if len(sample.JS) > 0 || len(sample.DOM) > 0 {
+		web.JSGlobals, web.DOM = make(map[string]string), make(map[string]bool)
+		for path, value := range sample.JS {
+			web.JSGlobals[path] = strings.ToLower(value)
+		}
+		for _, selector := range sample.DOM {
+			web.DOM[selector] = true
+		}
+	}
+	return web
+}
+
+// LintFingerprints 检查指纹规则与主动探测规则，activeFile、samplesFile 为空时跳过对应检查
+func LintFingerprints(fingerprintFile, activeFile, samplesFile string) (*LintReport, error) {
+	report := &LintReport{}
+	db, err := report.lintFingerprintFile(fingerprintFile)
+	if err != nil {
+		return nil, err
+	}
+	if activeFile != "" {
+		if err := report.lintActiveFile(activeFile, db); err != nil {
+			return nil, err
+		}
+	}
+	if samplesFile != "" {
+		if err := report.lintSamples(samplesFile, db); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+func (report *LintReport) lintFingerprintFile(file string) ([]FingerPEntity, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	// 使用 MapSlice 保留重复的产品名称，加载时重复的键会被静默覆盖
+	var fps yaml.MapSlice
+	if err := yaml.Unmarshal(data, &fps); err != nil {
+		return nil, err
+	}
+
+	compiler := newRuleCompiler()
+	products := make(map[string]string)
+	var db []FingerPEntity
+	for _, item := range fps {
+		product := fmt.Sprint(item.Key)
+		if prev, ok := products[strings.ToLower(product)]; ok {
+			if prev == product {
+				report.add(LintError, file, product, "", "产品名称重复，加载时只保留最后一处的规则")
+			} else {
+				report.add(LintWarning, file, product, "", fmt.Sprintf("产品名称与 %s 仅大小写不同", prev))
+			}
+		} else {
+			products[strings.ToLower(product)] = product
+			report.Products++
+		}
+
+		rules, ok := item.Value.([]interface{})
+		if !ok {
+			report.add(LintError, file, product, "", "规则应为字符串列表")
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, ruleInterface := range rules {
+			rule, ok := ruleInterface.(string)
+			if !ok {
+				report.add(LintError, file, product, fmt.Sprint(ruleInterface), "规则应为字符串，注意 yes/no/on/off 等值需要加引号")
+				continue
+			}
+			report.Rules++
+			if seen[rule] {
+				report.add(LintWarning, file, product, rule, "规则重复")
+				continue
+			}
+			seen[rule] = true
+			node, err := compiler.compile(rule)
+			if err != nil {
+				report.add(LintError, file, product, rule, err.Error())
+				continue
+			}
+			db = append(db, FingerPEntity{ProductName: product, AllString: rule, Rule: node, versioned: ruleHasVersion(node)})
+		}
+	}
+	compiler.build()
+
+	for _, fpe := range db {
+		for _, issue := range analyzeRule(fpe.Rule) {
+			report.add(issue.Level, file, fpe.ProductName, fpe.AllString, issue.Message)
+		}
+	}
+	return db, nil
+}
+
+func (report *LintReport) lintActiveFile(file string, db []FingerPEntity) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var sensitive yaml.MapSlice
+	if err := yaml.Unmarshal(data, &sensitive); err != nil {
+		return err
+	}
+	products := make(map[string]bool)
+	for _, fpe := range db {
+		products[strings.ToLower(fpe.ProductName)] = true
+	}
+	compiler := newRuleCompiler()
+	seen := make(map[string]bool)
+	for _, item := range sensitive {
+		product := fmt.Sprint(item.Key)
+		if seen[product] {
+			report.add(LintError, file, product, "", "产品名称重复，加载时只保留最后一处的路径")
+		}
+		seen[product] = true
+		paths, ok := item.Value.([]interface{})
+		if !ok || len(paths) == 0 {
+			report.add(LintError, file, product, "", "路径应为非空的列表")
+			continue
+		}
+		seenPath := make(map[string]bool)
+		for _, p := range paths {
+			probe, ok := report.activeProbe(file, product, p)
+			if !ok {
+				continue
+			}
+			label := probe.Path
+			if probe.Method != "GET" {
+				label = probe.Method + " " + probe.Path
+			}
+			if probe.Method != "RAW" && !strings.HasPrefix(probe.Path, "/") {
+				report.add(LintWarning, file, product, label, "路径应以 / 开头，否则会直接拼接在目标地址后")
+			}
+			if probe.Method == "RAW" && probe.Body == "" {
+				report.add(LintError, file, product, label, "RAW 探测需要在 body 中指定发送的数据")
+			}
+			if probe.Match != "" {
+				if _, err := compiler.compile(probe.Match); err != nil {
+					report.add(LintError, file, product, probe.Match, err.Error())
+				}
+			} else if !products[strings.ToLower(product)] {
+				report.add(LintWarning, file, product, label, "未指定 match 且指纹库中不存在该产品的有效规则，该探测不会生效")
+			}
+			for _, require := range probe.Requires {
+				if !products[strings.ToLower(require)] {
+					report.add(LintWarning, file, product, label, fmt.Sprintf("依赖的指纹 %s 在指纹库中不存在，该探测不会发送", require))
+				}
+			}
+			key := probe.Method + " " + probe.Path + "\n" + probe.Body
+			if seenPath[key] {
+				report.add(LintWarning, file, product, label, "路径重复")
+			}
+			seenPath[key] = true
+		}
+	}
+	return nil
+}
+
+// activeProbe 解析单条探测，字符串为 GET 路径，对象中不允许出现未知字段
+func (report *LintReport) activeProbe(file, product string, value interface{}) (ActiveProbe, bool) {
+	var probe ActiveProbe
+	if path, ok := value.(string); ok {
+		probe.Path = path
+	} else {
+		data, err := yaml.Marshal(value)
+		if err == nil {
+			err = yaml.UnmarshalStrict(data, &probe)
+		}
+		if _, isMap := value.(yaml.MapSlice); !isMap || err != nil {
+			report.add(LintError, file, product, fmt.Sprint(value), "路径应为字符串或包含 path、method、headers、body、match、requires、unsafe 的对象")
+			return probe, false
+		}
+	}
+	if err := checkActiveMethod(&probe); err != nil {
+		report.add(LintError, file, product, probe.Method+" "+probe.Path, err.Error())
+		return probe, false
+	}
+	return probe, true
+}
+
+func (report *LintReport) lintSamples(file string, db []FingerPEntity) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	samples := make(map[string]FingerprintSamples)
+	if err := yaml.Unmarshal(data, &samples); err != nil {
+		return err
+	}
+	rules := make(map[string][]FingerPEntity)
+	for _, fpe := range db {
+		rules[fpe.ProductName] = append(rules[fpe.ProductName], fpe)
+	}
+	var products []string
+	for product := range samples {
+		products = append(products, product)
+	}
+	sort.Strings(products)
+
+	for _, product := range products {
+		if len(rules[product]) == 0 {
+			report.add(LintWarning, file, product, "", "指纹库中不存在该产品的有效规则，样本已跳过")
+			continue
+		}
+		for i, sample := range samples[product].Positive {
+			report.Samples++
+			fingerprints, versions := Identify(sample.webInfo(), rules[product])
+			if len(fingerprints) == 0 {
+				report.add(LintError, file, product, "", fmt.Sprintf("第 %d 个正样本未被识别", i+1))
+			} else if sample.Version != "" && versions[product] != sample.Version {
+				report.add(LintError, file, product, "", fmt.Sprintf("第 %d 个正样本提取到的版本为 %q，期望 %q", i+1, versions[product], sample.Version))
+			}
+		}
+		for i, sample := range samples[product].Negative {
+			report.Samples++
+			web := sample.webInfo()
+			for _, fpe := range rules[product] {
+				if fpe.Rule.eval(newMatchState(web)) {
+					report.add(LintError, file, product, fpe.AllString, fmt.Sprintf("第 %d 个负样本被误识别", i+1))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// analyzeRule 通过真值表检查规则是否永远无法命中，以及可以移除而不影响结果的子表达式。
+// 同一字段上的两个条件之间的取值约束由候选输入推导，例如 status="200" 与 status="404" 不能同时成立
+func analyzeRule(root ruleNode) []LintIssue {
+	a := &ruleAnalyzer{vars: make(map[string]int)}
+	a.collect(root)
+	if len(a.conds) > maxLintConds {
+		return nil
+	}
+	a.constrain()
+
+	var base []bool
+	satisfiable := false
+	for mask := uint32(0); mask < 1<<len(a.conds); mask++ {
+		if !a.consistent(mask) {
+			continue
+		}
+		a.masks = append(a.masks, mask)
+		result := a.eval(root, mask, nil, false)
+		base = append(base, result)
+		satisfiable = satisfiable || result
+	}
+	if !satisfiable {
+		return []LintIssue{{Level: LintError, Message: "规则永远无法命中"}}
+	}
+
+	var issues []LintIssue
+	reported := make(map[string]bool)
+	var walk func(node ruleNode)
+	check := func(child ruleNode, value bool) {
+		for i, mask := range a.masks {
+			if a.eval(root, mask, child, value) != base[i] {
+				walk(child)
+				return
+			}
+		}
+		if text := formatRule(child); !reported[text] {
+			reported[text] = true
+			issues = append(issues, LintIssue{Level: LintWarning, Message: fmt.Sprintf("子表达式 %s 不影响匹配结果，可以移除", text)})
+		}
+	}
+	// || 的分支替换为 false、&& 的分支替换为 true 后结果不变，说明该分支不可达或冗余
+	walk = func(node ruleNode) {
+		switch n := node.(type) {
+		case *orNode:
+			check(n.left, false)
+			check(n.right, false)
+		case *andNode:
+			check(n.left, true)
+			check(n.right, true)
+		case *notNode:
+			walk(n.node)
+		}
+	}
+	walk(root)
+	return issues
+}
+
+type ruleAnalyzer struct {
+	conds []*ruleCond
+	vars  map[string]int // 相同的条件共用一个变量
+	pairs []condPair
+	masks []uint32
+}
+
+// condPair 同一字段上两个条件可能出现的取值组合，下标为 左*2+右
+type condPair struct {
+	left, right int
+	combos      [4]bool
+}
+
+func (a *ruleAnalyzer) collect(node ruleNode) {
+	switch n := node.(type) {
+	case *orNode:
+		a.collect(n.left)
+		a.collect(n.right)
+	case *andNode:
+		a.collect(n.left)
+		a.collect(n.right)
+	case *notNode:
+		a.collect(n.node)
+	case *ruleCond:
+		if _, ok := a.vars[formatRule(n)]; !ok {
+			a.vars[formatRule(n)] = len(a.conds)
+			a.conds = append(a.conds, n)
+		}
+	}
+}
+
+func (a *ruleAnalyzer) constrain() {
+	for i := range a.conds {
+		for j := i + 1; j < len(a.conds); j++ {
+			left, right := a.conds[i], a.conds[j]
+			if left.key != right.key || left.op == opRegex || right.op == opRegex {
+				continue
+			}
+			pair := condPair{left: i, right: j}
+			for _, web := range candidateWebInfos(left, right) {
+				state := newMatchState(web)
+				idx := 0
+				if left.eval(state) {
+					idx += 2
+				}
+				if right.eval(state) {
+					idx++
+				}
+				pair.combos[idx] = true
+			}
+			a.pairs = append(a.pairs, pair)
+		}
+	}
+}
+
+// candidateWebInfos 构造能覆盖两个条件全部可能取值组合的输入
+func candidateWebInfos(left, right *ruleCond) []*WebInfo {
+	var webs []*WebInfo
+	switch {
+	case left.key == "protocol":
+		for _, v := range []string{"", "\x00", left.value, right.value} {
+			webs = append(webs, &WebInfo{Protocol: v})
+		}
+	case intRuleKeys[left.key]:
+		var nums []int
+		for _, n := range []int{left.num, right.num} {
+			nums = append(nums, n-1, n, n+1)
+		}
+		for _, n := range nums {
+			webs = append(webs, &WebInfo{Port: n, StatusCode: n, IconHash: fmt.Sprint(n), BodyHash: fmt.Sprint(n), ContentLength: n})
+		}
+		webs = append(webs, &WebInfo{})
+	default:
+		for _, v := range []string{"", "\x00", left.value, right.value, left.value + right.value} {
+			web := &WebInfo{}
+			web.setStringField(left.key, v)
+			webs = append(webs, web)
+		}
+	}
+	return webs
+}
+
+func (a *ruleAnalyzer) consistent(mask uint32) bool {
+	for _, pair := range a.pairs {
+		idx := 0
+		if mask&(1<<pair.left) != 0 {
+			idx += 2
+		}
+		if mask&(1<<pair.right) != 0 {
+			idx++
+		}
+		if !pair.combos[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// eval 按变量取值计算表达式，replaced 不为空时将该子表达式替换为 value
+func (a *ruleAnalyzer) eval(node ruleNode, mask uint32, replaced ruleNode, value bool) bool {
+	if replaced != nil && node == replaced {
+		return value
+	}
+	switch n := node.(type) {
+	case *orNode:
+		return a.eval(n.left, mask, replaced, value) || a.eval(n.right, mask, replaced, value)
+	case *andNode:
+		return a.eval(n.left, mask, replaced, value) && a.eval(n.right, mask, replaced, value)
+	case *notNode:
+		return !a.eval(n.node, mask, replaced, value)
+	case *ruleCond:
+		return mask&(1<<a.vars[formatRule(n)]) != 0
+	}
+	return false
+}
+
+// formatRule 将表达式树还原为规则文本
+func formatRule(node ruleNode) string {
+	switch n := node.(type) {
+	case *orNode:
+		return formatRule(n.left) + " || " + formatRule(n.right)
+	case *andNode:
+		return formatOperand(n.left) + " && " + formatOperand(n.right)
+	case *notNode:
+		if _, ok := n.node.(*ruleCond); ok {
+			return "!" + formatRule(n.node)
+		}
+		return "!(" + formatRule(n.node) + ")"
+	case *ruleCond:
+		var token string
+		for _, item := range ruleOperators {
+			if item.op == n.op {
+				token = item.token
+			}
+		}
+		return n.key + token + `"` + strings.ReplaceAll(n.value, `"`, `\"`) + `"`
+	}
+	return ""
+}
+
+func formatOperand(node ruleNode) string {
+	if _, ok := node.(*orNode); ok {
+		return "(" + formatRule(node) + ")"
+	}
+	return formatRule(node)
+}
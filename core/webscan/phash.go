@@ -0,0 +1,175 @@
+package webscan
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"math/bits"
+	"os"
+	"slack-wails/lib/structs"
+	"sort"
+	"strconv"
+)
+
+// 截图视觉相似度的默认汉明距离阈值，64 位感知哈希差异不超过该值视为同一类页面
+const DefaultScreenshotThreshold = 10
+
+// 计算哈希时只取页面首屏，避免整页截图因页面长度不同导致同一模板的缩放比例不一致
+const screenshotViewportRatio = 0.75
+
+// ScreenshotHash 计算截图的感知哈希 (pHash)，返回 16 位十六进制字符串
+func ScreenshotHash(fp string) (string, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return "", fmt.Errorf("%s 截图解码失败: %v", fp, err)
+	}
+	return fmt.Sprintf("%016x", perceptualHash(img)), nil
+}
+
+// perceptualHash 将图片缩放为 32x32 灰度图并做二维 DCT，取左上角 8x8 低频系数与中位数比较生成 64 位哈希
+func perceptualHash(img image.Image) uint64 {
+	const size, low = 32, 8
+	bounds := img.Bounds()
+	if h := int(float64(bounds.Dx()) * screenshotViewportRatio); h > 0 && bounds.Dy() > h {
+		bounds.Max.Y = bounds.Min.Y + h
+	}
+	gray := resizeGray(img, bounds, size)
+
+	// 行列分别做 DCT-II
+	var cosTable [size][size]float64
+	for u := 0; u < size; u++ {
+		for x := 0; x < size; x++ {
+			cosTable[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * size))
+		}
+	}
+	var rows [size][size]float64
+	for y := 0; y < size; y++ {
+		for u := 0; u < low; u++ {
+			var sum float64
+			for x := 0; x < size; x++ {
+				sum += gray[y][x] * cosTable[u][x]
+			}
+			rows[y][u] = sum
+		}
+	}
+	var coeffs [low * low]float64
+	for v := 0; v < low; v++ {
+		for u := 0; u < low; u++ {
+			var sum float64
+			for y := 0; y < size; y++ {
+				sum += rows[y][u] * cosTable[v][y]
+			}
+			coeffs[v*low+u] = sum
+		}
+	}
+
+	// 直流分量只反映整体亮度，不参与中位数计算
+	sorted := make([]float64, 0, len(coeffs)-1)
+	sorted = append(sorted, coeffs[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	var hash uint64
+	for i, c := range coeffs {
+		if c > median {
+			hash |= 1 << uint(len(coeffs)-1-i)
+		}
+	}
+	return hash
+}
+
+// resizeGray 按区域平均将 bounds 范围内的图片缩放为 size x size 的灰度矩阵
+func resizeGray(img image.Image, bounds image.Rectangle, size int) [][]float64 {
+	gray := make([][]float64, size)
+	w, h := bounds.Dx(), bounds.Dy()
+	for y := 0; y < size; y++ {
+		gray[y] = make([]float64, size)
+		y0, y1 := bounds.Min.Y+y*h/size, bounds.Min.Y+(y+1)*h/size
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < size; x++ {
+			x0, x1 := bounds.Min.X+x*w/size, bounds.Min.X+(x+1)*w/size
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var sum float64
+			var n int
+			for py := y0; py < y1 && py < bounds.Max.Y; py++ {
+				for px := x0; px < x1 && px < bounds.Max.X; px++ {
+					r, g, b, _ := img.At(px, py).RGBA()
+					sum += 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+					n++
+				}
+			}
+			if n > 0 {
+				gray[y][x] = sum / float64(n)
+			}
+		}
+	}
+	return gray
+}
+
+// hammingDistance 比较两个十六进制哈希，格式错误时返回 -1
+func hammingDistance(a, b string) int {
+	x, err := strconv.ParseUint(a, 16, 64)
+	if err != nil {
+		return -1
+	}
+	y, err := strconv.ParseUint(b, 16, 64)
+	if err != nil {
+		return -1
+	}
+	return bits.OnesCount64(x ^ y)
+}
+
+// ClusterScreenshots 按截图感知哈希将结果聚类，首个加入的成员作为代表图，聚类按成员数量降序排列。
+// 缺少哈希的结果会读取截图文件补算，没有截图的结果不参与聚类
+func ClusterScreenshots(results []structs.InfoResult, threshold int) []structs.ScreenshotCluster {
+	if threshold < 0 {
+		threshold = DefaultScreenshotThreshold
+	}
+	var clusters []structs.ScreenshotCluster
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if result.Screenshot == "" || seen[result.URL] {
+			continue
+		}
+		hash := result.ScreenshotHash
+		if hash == "" {
+			var err error
+			if hash, err = ScreenshotHash(result.Screenshot); err != nil {
+				continue
+			}
+		}
+		seen[result.URL] = true
+		joined := false
+		for i := range clusters {
+			if d := hammingDistance(clusters[i].Hash, hash); d >= 0 && d <= threshold {
+				clusters[i].URLs = append(clusters[i].URLs, result.URL)
+				joined = true
+				break
+			}
+		}
+		if !joined {
+			clusters = append(clusters, structs.ScreenshotCluster{
+				Hash:       hash,
+				Screenshot: result.Screenshot,
+				URL:        result.URL,
+				Title:      result.Title,
+				URLs:       []string{result.URL},
+			})
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].URLs) > len(clusters[j].URLs)
+	})
+	return clusters
+}
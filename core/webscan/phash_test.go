@@ -0,0 +1,74 @@
+package webscan
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slack-wails/lib/structs"
+	"testing"
+)
+
+func writeScreenshot(t *testing.T, name string, height int, draw func(x, y int) uint8) string {
+	img := image.NewGray(image.Rect(0, 0, 320, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < 320; x++ {
+			img.SetGray(x, y, color.Gray{Y: draw(x, y)})
+		}
+	}
+	fp := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	return fp
+}
+
+func TestClusterScreenshots(t *testing.T) {
+	// 以 16 像素为单位的块状纹理模拟页面布局，seed 不同即为不同页面
+	page := func(seed uint32) func(x, y int) uint8 {
+		return func(x, y int) uint8 {
+			v := uint32(x/16)*73856093 ^ uint32(y/16)*19349663 ^ seed
+			v ^= v >> 13
+			v *= 0x5bd1e995
+			return uint8(v >> 24)
+		}
+	}
+	// 同一登录页，其中一张页面更长且标题栏文字略有不同
+	a := writeScreenshot(t, "a.png", 240, page(1))
+	b := writeScreenshot(t, "b.png", 600, func(x, y int) uint8 {
+		if y > 10 && y < 20 && x > 20 && x < 60 {
+			return 255
+		}
+		return page(1)(x, y)
+	})
+	c := writeScreenshot(t, "c.png", 240, page(2))
+
+	results := []structs.InfoResult{
+		{URL: "http://a", Screenshot: a, Title: "login"},
+		{URL: "http://b", Screenshot: b},
+		{URL: "http://c", Screenshot: c},
+		{URL: "http://d"},
+	}
+	hash, err := ScreenshotHash(a)
+	if err != nil || len(hash) != 16 {
+		t.Fatalf("ScreenshotHash() = %q, %v", hash, err)
+	}
+	results[0].ScreenshotHash = hash
+
+	clusters := ClusterScreenshots(results, DefaultScreenshotThreshold)
+	if len(clusters) != 2 {
+		t.Fatalf("ClusterScreenshots() returned %d clusters: %+v", len(clusters), clusters)
+	}
+	if clusters[0].URL != "http://a" || clusters[0].Title != "login" || len(clusters[0].URLs) != 2 || clusters[0].URLs[1] != "http://b" {
+		t.Errorf("first cluster = %+v", clusters[0])
+	}
+	if len(clusters[1].URLs) != 1 || clusters[1].URLs[0] != "http://c" {
+		t.Errorf("second cluster = %+v", clusters[1])
+	}
+}
@@ -10,10 +10,10 @@ import (
 	"regexp"
 	"slack-wails/lib/gologger"
 	"slack-wails/lib/utils/arrayutil"
+	"strconv"
 	"strings"
 	"sync"
 
-	"github.com/Knetic/govaluate"
 	"gopkg.in/yaml.v2"
 )
 
@@ -24,31 +24,71 @@ type Config struct {
 	FingerprintRuleFile string
 	// 主动探测的规则文件
 	ActiveRuleFile string
+	// Web 管理后台默认口令库
+	WebCredentialFile string
+	// 离线 CVE 数据集
+	CVEFile string
 }
 
 type FingerPEntity struct {
 	ProductName string
 	AllString   string
-	Rule        []RuleData
+	Rule        ruleNode // 加载时编译好的规则表达式
+	versioned   bool     // 规则中存在 (?P<version>...) 命名分组，可提取产品版本
 }
 
-type RuleData struct {
-	Start int
-	End   int
-	Op    int16  // 0= 1!= 2== 3>= 4<= 5~=
-	Key   string // body="123"中的body
-	Value string // body="123"中的123
-	All   string // body="123"
+type ActiveFingerPEntity struct {
+	ProductName string
+	Probes      []ActiveProbe
+	Fpe         []FingerPEntity // 产品的被动指纹规则，探测未指定 match 时使用
 }
 
-type ActiveFingerPEntity struct {
-	Path []string
-	Fpe  []FingerPEntity
+// ActiveProbe 主动探测请求，dir.yaml 中直接写路径时为 GET 请求
+type ActiveProbe struct {
+	Path     string            `yaml:"path"`
+	Method   string            `yaml:"method"` // 默认 GET，RAW 表示通过 TCP/TLS 直接发送 body 并读取返回的数据
+	Headers  map[string]string `yaml:"headers"`
+	Body     string            `yaml:"body"`
+	Match    string            `yaml:"match"`    // 响应需满足的规则，语法与指纹规则相同，为空时使用该产品的指纹规则
+	Requires []string          `yaml:"requires"` // 目标已识别到其中任意一个指纹时才发送，为空时不限制
+	Unsafe   bool              `yaml:"unsafe"`   // 声明后才允许使用 PUT/DELETE/PATCH 等可能修改目标数据的请求方式
+	fpe      []FingerPEntity   // match 编译后的规则
+}
+
+// 主动探测支持的请求方式
+var activeMethods = map[string]bool{
+	"GET":     true,
+	"POST":    true,
+	"PUT":     true,
+	"DELETE":  true,
+	"PATCH":   true,
+	"OPTIONS": true,
+	"RAW":     true,
+}
+
+// 可能修改目标数据的请求方式，规则需要通过 unsafe: true 显式声明
+var unsafeMethods = map[string]bool{
+	"PUT":    true,
+	"DELETE": true,
+	"PATCH":  true,
+}
+
+func (p *ActiveProbe) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+	if err := unmarshal(&path); err == nil {
+		p.Path = path
+		return nil
+	}
+	type plain ActiveProbe
+	return unmarshal((*plain)(p))
 }
 
 var FingerprintDB []FingerPEntity
 var ActiveFingerprintDB []ActiveFingerPEntity
 
+// 指纹规则中需要在无头浏览器中计算的 JS 全局变量与 CSS 选择器
+var browserGlobals, browserSelectors []string
+
 func (config *Config) InitFingprintDB(ctx context.Context, fingerprintFile string) error {
 	data, err := os.ReadFile(fingerprintFile)
 	if err != nil {
@@ -81,219 +121,623 @@ func (config *Config) InitFingprintDB(ctx context.Context, fingerprintFile strin
 		}
 	}
 
+	compiler := newRuleCompiler()
 	for productName, ruleList := range m {
 		for _, rule := range ruleList {
+			node, err := compiler.compile(rule)
+			if err != nil {
+				gologger.Error(ctx, fmt.Sprintf("[fingerprint] %s 规则错误 %s: %v", productName, rule, err))
+				continue
+			}
 			FingerprintDB = append(FingerprintDB, FingerPEntity{
 				ProductName: productName,
-				Rule:        ParseRule(rule),
+				Rule:        node,
 				AllString:   rule,
+				versioned:   ruleHasVersion(node),
 			})
 		}
 	}
+	compiler.build()
+	browserGlobals, browserSelectors = compiler.browserProbes()
 
 	return nil
 }
-func (config *Config) InitActiveScanPath(activefingerFile string) error {
+
+func (config *Config) InitActiveScanPath(ctx context.Context, activefingerFile string) error {
 	data, err := os.ReadFile(activefingerFile)
 	if err != nil {
 		return err
 	}
-	sensitive := make(map[string][]string)
+	sensitive := make(map[string][]ActiveProbe)
 	err = yaml.Unmarshal(data, &sensitive)
 	if err != nil {
 		return err
 	}
-	for name, paths := range sensitive {
+	compiler := newRuleCompiler()
+	for name, probes := range sensitive {
 		var fpes []FingerPEntity
 		for _, fpe := range FingerprintDB {
 			if fpe.ProductName == name {
 				fpes = append(fpes, fpe)
 			}
 		}
-		if len(fpes) != 0 {
+		var valid []ActiveProbe
+		for _, probe := range probes {
+			if err := checkActiveMethod(&probe); err != nil {
+				gologger.Error(ctx, fmt.Sprintf("[active] %s %v", name, err))
+				continue
+			}
+			if probe.Match != "" {
+				node, err := compiler.compile(probe.Match)
+				if err != nil {
+					gologger.Error(ctx, fmt.Sprintf("[active] %s 规则错误 %s: %v", name, probe.Match, err))
+					continue
+				}
+				probe.fpe = []FingerPEntity{{
+					ProductName: name,
+					Rule:        node,
+					AllString:   probe.Match,
+					versioned:   ruleHasVersion(node),
+				}}
+			} else if len(fpes) == 0 {
+				// 既没有 match 也没有被动指纹时无法判断结果
+				continue
+			}
+			valid = append(valid, probe)
+		}
+		if len(valid) != 0 {
 			ActiveFingerprintDB = append(ActiveFingerprintDB, ActiveFingerPEntity{
-				Path: paths,
-				Fpe:  fpes,
+				ProductName: name,
+				Probes:      valid,
+				Fpe:         fpes,
 			})
 		}
 	}
+	compiler.build()
 	return nil
 }
 
-func ParseRule(rule string) []RuleData {
-	var result []RuleData
-	empty := RuleData{}
+// checkActiveMethod 补全默认的请求方式，并检查规则是否允许使用该请求方式
+func checkActiveMethod(probe *ActiveProbe) error {
+	probe.Method = strings.ToUpper(probe.Method)
+	if probe.Method == "" {
+		probe.Method = "GET"
+	}
+	if !activeMethods[probe.Method] {
+		return fmt.Errorf("不支持的请求方式 %s", probe.Method)
+	}
+	if unsafeMethods[probe.Method] && !probe.Unsafe {
+		return fmt.Errorf("请求方式 %s 可能修改目标数据，需要声明 unsafe: true", probe.Method)
+	}
+	return nil
+}
 
-	for {
-		data := getRuleData(rule)
-		if data == empty {
-			break
+// 规则运算符
+const (
+	opContains    int16 = iota // =
+	opNotContains              // !=
+	opEqual                    // ==
+	opGreater                  // >=
+	opLess                     // <=
+	opRegex                    // ~=
+)
+
+var ruleOperators = []struct {
+	token string
+	op    int16
+}{
+	{"==", opEqual},
+	{"!=", opNotContains},
+	{">=", opGreater},
+	{"<=", opLess},
+	{"~=", opRegex},
+	{"=", opContains},
+}
+
+var stringRuleKeys = map[string]bool{
+	"header":       true,
+	"body":         true,
+	"server":       true,
+	"title":        true,
+	"cert":         true,
+	"path":         true,
+	"icon_mdhash":  true,
+	"content_type": true,
+	"banner":       true,
+	"cookie":       true,
+	"location":     true,
+	"body_mdhash":  true,
+	"script":       true,
+	"jarm":         true,
+}
+
+var intRuleKeys = map[string]bool{
+	"port":      true,
+	"status":    true,
+	"icon_hash": true,
+	"body_hash": true,
+	"length":    true,
+}
+
+// 带名称的关键字，如 header.x-powered-by、meta.generator、js.jQuery.fn.jquery
+var namedRuleKeys = map[string]bool{
+	"header": true,
+	"meta":   true,
+	"js":     true,
+}
+
+type ruleNode interface {
+	eval(s *matchState) bool
+}
+
+type andNode struct{ left, right ruleNode }
+
+type orNode struct{ left, right ruleNode }
+
+type notNode struct{ node ruleNode }
+
+// ruleCond 单个条件，如 body="123"
+type ruleCond struct {
+	key     string
+	op      int16
+	value   string
+	num     int
+	re      *regexp.Regexp
+	matcher *keywordMatcher // 包含类条件的关键字所在的自动机，关键字为空时为 nil
+	keyword int
+	version int // 正则中 version 命名分组的下标，-1 表示没有
+}
+
+func (n *andNode) eval(s *matchState) bool { return n.left.eval(s) && n.right.eval(s) }
+
+func (n *orNode) eval(s *matchState) bool { return n.left.eval(s) || n.right.eval(s) }
+
+func (n *notNode) eval(s *matchState) bool { return !n.node.eval(s) }
+
+func (c *ruleCond) eval(s *matchState) bool {
+	if c.key == "protocol" {
+		return (c.op == opContains && s.web.Protocol == c.value) || (c.op == opNotContains && s.web.Protocol != c.value)
+	}
+	if c.key == "dom" {
+		// 未使用无头浏览器时 DOM 为空，= 与 != 均不成立
+		if s.web.DOM == nil {
+			return false
 		}
-		result = append(result, data)
-		rule = rule[:data.Start] + "T" + rule[data.End:]
-	}
-	return result
-}
-
-func getRuleData(rule string) RuleData {
-	if !strings.Contains(rule, "=\"") {
-		return RuleData{}
-	}
-	pos := strings.Index(rule, "=\"")
-	op := 0
-	switch rule[pos-1] {
-	case 33: // !
-		op = 1 // !=
-	case 61: // =
-		op = 2 // ==
-	case 62: // >
-		op = 3 // >=
-	case 60: // <
-		op = 4 // <=
-	case 126: // ~
-		op = 5 // ~=
-	}
-
-	start := 0
-	ti := 0
-	if op > 0 {
-		ti = 1
-	}
-	for i := pos - 1 - ti; i >= 0; i-- {
-		if (rule[i] > 122 || rule[i] < 97) && rule[i] != 95 {
-			start = i + 1
-			break
+		return s.web.DOM[c.value] == (c.op == opContains)
+	}
+	if intRuleKeys[c.key] {
+		source, ok := s.web.intField(c.key)
+		if !ok {
+			return false
+		}
+		switch c.op {
+		case opContains, opEqual:
+			return source == c.num
+		case opNotContains:
+			return source != c.num
+		case opGreater:
+			return source >= c.num
+		case opLess:
+			return source <= c.num
+		}
+		return false
+	}
+	source := s.web.stringField(c.key)
+	if source == "" {
+		return false
+	}
+	switch c.op {
+	case opContains:
+		return s.contains(c, source)
+	case opNotContains:
+		return !s.contains(c, source)
+	case opEqual:
+		return source == c.value
+	case opRegex:
+		if c.version < 0 {
+			return c.re.MatchString(source)
 		}
+		match := c.re.FindStringSubmatch(source)
+		if match == nil {
+			return false
+		}
+		if s.version == "" {
+			s.version = match[c.version]
+		}
+		return true
 	}
-	key := rule[start : pos-ti]
+	return false
+}
 
-	end := pos + 2
-	for i := pos + 2; i < len(rule)-1; i++ {
-		if rule[i] != 92 && rule[i+1] == 34 {
-			end = i + 2
-			break
+// ruleHasVersion 判断规则中是否存在可以提取版本的正则
+func ruleHasVersion(node ruleNode) bool {
+	switch n := node.(type) {
+	case *andNode:
+		return ruleHasVersion(n.left) || ruleHasVersion(n.right)
+	case *orNode:
+		return ruleHasVersion(n.left) || ruleHasVersion(n.right)
+	case *notNode:
+		return false
+	case *ruleCond:
+		return n.version >= 0
+	}
+	return false
+}
+
+// matchState 单次识别过程中各字段的关键字命中结果，按需计算
+type matchState struct {
+	web     *WebInfo
+	hits    map[*keywordMatcher][]bool
+	version string // 当前规则提取到的版本，每条规则计算前重置
+}
+
+func newMatchState(web *WebInfo) *matchState {
+	return &matchState{web: web, hits: make(map[*keywordMatcher][]bool)}
+}
+
+func (s *matchState) contains(c *ruleCond, source string) bool {
+	if c.matcher == nil {
+		return true
+	}
+	hits, ok := s.hits[c.matcher]
+	if !ok {
+		hits = c.matcher.match(source)
+		s.hits[c.matcher] = hits
+	}
+	return hits[c.keyword]
+}
+
+func (web *WebInfo) stringField(key string) string {
+	switch key {
+	case "header":
+		return web.HeadeString
+	case "body":
+		return web.BodyString
+	case "server":
+		return web.Server
+	case "title":
+		return web.Title
+	case "cert":
+		return web.Cert
+	case "path":
+		return web.Path
+	case "icon_mdhash":
+		return web.IconMd5
+	case "content_type":
+		return web.ContentType
+	case "banner":
+		return web.Banner
+	case "cookie":
+		return web.Cookies
+	case "location":
+		return web.Location
+	case "body_mdhash":
+		return web.BodyMd5
+	case "script":
+		return web.Scripts
+	case "jarm":
+		return web.JARM
+	}
+	if kind, name, ok := strings.Cut(key, "."); ok {
+		switch kind {
+		case "header":
+			return web.Headers[name]
+		case "meta":
+			return web.Meta[name]
+		case "js":
+			return web.JSGlobals[name]
 		}
 	}
-	// 增加错误判断，防止切片越界
-	if end-1 > len(rule) || pos+2 > len(rule) || end-1 < pos+2 {
-		fmt.Printf("Error: rule [%s] pos [%d] end [%d] len [%d]\n", rule, pos, end, len(rule))
-		return RuleData{}
+	return ""
+}
+
+// setStringField 与 stringField 对应，用于构造指定字段取值的输入
+func (web *WebInfo) setStringField(key, value string) {
+	switch key {
+	case "header":
+		web.HeadeString = value
+	case "body":
+		web.BodyString = value
+	case "server":
+		web.Server = value
+	case "title":
+		web.Title = value
+	case "cert":
+		web.Cert = value
+	case "path":
+		web.Path = value
+	case "icon_mdhash":
+		web.IconMd5 = value
+	case "content_type":
+		web.ContentType = value
+	case "banner":
+		web.Banner = value
+	case "cookie":
+		web.Cookies = value
+	case "location":
+		web.Location = value
+	case "body_mdhash":
+		web.BodyMd5 = value
+	case "script":
+		web.Scripts = value
+	case "jarm":
+		web.JARM = value
+	case "dom":
+		web.DOM = map[string]bool{value: value != ""}
+	}
+	if kind, name, ok := strings.Cut(key, "."); ok {
+		field := map[string]string{name: value}
+		switch kind {
+		case "header":
+			web.Headers = field
+		case "meta":
+			web.Meta = field
+		case "js":
+			web.JSGlobals = field
+		}
 	}
-	value := rule[pos+2 : end-1]
-	all := rule[start:end]
+}
 
-	return RuleData{Start: start, End: end, Op: int16(op), Key: key, Value: value, All: all}
+func (web *WebInfo) intField(key string) (int, bool) {
+	switch key {
+	case "port":
+		return web.Port, true
+	case "status":
+		return web.StatusCode, true
+	case "icon_hash":
+		hash, err := strconv.Atoi(web.IconHash)
+		return hash, err == nil
+	case "body_hash":
+		hash, err := strconv.Atoi(web.BodyHash)
+		return hash, err == nil
+	case "length":
+		return web.ContentLength, true
+	}
+	return 0, false
 }
 
-// 将 T/F 替换为 true/false，并转换逻辑运算符符号
-func normalizeExpression(expr string) string {
-	expr = strings.ReplaceAll(expr, " ", "") // 去空格
-	expr = strings.ReplaceAll(expr, "T", "true")
-	expr = strings.ReplaceAll(expr, "F", "false")
-	return expr
+// ruleCompiler 编译规则，同一字段的包含类关键字汇总到一个自动机中
+type ruleCompiler struct {
+	matchers  map[string]*keywordMatcher
+	globals   map[string]bool // js.* 引用的 JS 全局变量
+	selectors map[string]bool // dom 引用的 CSS 选择器
 }
 
-// 使用 govaluate 实现 boolEval
-func boolEval(expression string) (bool, error) {
-	// 检查是否有 T 或 F
-	if !strings.Contains(expression, "T") && !strings.Contains(expression, "F") {
-		return false, errors.New("纯布尔表达式错误，没有包含T/F")
+func newRuleCompiler() *ruleCompiler {
+	return &ruleCompiler{
+		matchers:  make(map[string]*keywordMatcher),
+		globals:   make(map[string]bool),
+		selectors: make(map[string]bool),
 	}
+}
+
+// browserProbes 返回需要在无头浏览器中计算的 JS 全局变量与 CSS 选择器
+func (c *ruleCompiler) browserProbes() (globals, selectors []string) {
+	return sortedKeys(c.globals), sortedKeys(c.selectors)
+}
 
-	// 标准化表达式
-	exprStr := normalizeExpression(expression)
+// build 所有规则编译完成后调用，构建各字段的自动机
+func (c *ruleCompiler) build() {
+	for _, m := range c.matchers {
+		m.build()
+	}
+}
 
-	// 创建表达式对象
-	expr, err := govaluate.NewEvaluableExpression(exprStr)
+// compile 将 body="a" && (title="b" || !header="c") 形式的规则编译为表达式树
+func (c *ruleCompiler) compile(rule string) (ruleNode, error) {
+	p := &ruleParser{rule: rule, compiler: c}
+	node, err := p.parseOr()
 	if err != nil {
-		return false, fmt.Errorf("无法解析表达式 [%s]: %v", exprStr, err)
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.rule) {
+		return nil, fmt.Errorf("第 %d 个字符处存在多余内容 %q", p.pos+1, p.rule[p.pos:])
+	}
+	return node, nil
+}
 
+func (c *ruleCompiler) cond(key string, op int16, value string) (ruleNode, error) {
+	cond := &ruleCond{key: key, op: op, value: value, version: -1}
+	kind, name, named := strings.Cut(key, ".")
+	switch {
+	case key == "protocol" || key == "dom":
+		if op != opContains && op != opNotContains {
+			return nil, fmt.Errorf("%s 仅支持 = 与 !=", key)
+		}
+		if key == "dom" {
+			if value == "" {
+				return nil, errors.New("dom 的选择器不能为空")
+			}
+			c.selectors[value] = true
+		}
+	case named && (!namedRuleKeys[kind] || name == ""):
+		return nil, fmt.Errorf("未知的规则关键字 %s", key)
+	case intRuleKeys[key]:
+		if op == opRegex {
+			return nil, fmt.Errorf("%s 不支持 ~=", key)
+		}
+		num, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s 的值 %q 不是整数", key, value)
+		}
+		cond.num = num
+	case stringRuleKeys[key] || named:
+		if kind == "js" {
+			c.globals[name] = true
+		}
+		cond.value = strings.ToLower(value)
+		switch op {
+		case opGreater, opLess:
+			return nil, fmt.Errorf("%s 不支持 >= 与 <=", key)
+		case opRegex:
+			// 正则保留原始大小写，避免 \S、\D 等被转换为含义相反的 \s、\d
+			re, err := regexp.Compile("(?i)" + value)
+			if err != nil {
+				return nil, fmt.Errorf("正则 %q 编译失败: %v", value, err)
+			}
+			cond.value, cond.re = value, re
+			cond.version = re.SubexpIndex("version")
+		case opContains, opNotContains:
+			if cond.value != "" {
+				m, ok := c.matchers[key]
+				if !ok {
+					m = newKeywordMatcher()
+					c.matchers[key] = m
+				}
+				cond.matcher = m
+				cond.keyword = m.add(cond.value)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("未知的规则关键字 %s", key)
 	}
+	return cond, nil
+}
 
-	// 求值
-	result, err := expr.Evaluate(nil) // 不需要变量
-	if err != nil {
-		return false, fmt.Errorf("执行表达式出错 [%s]: %v", exprStr, err)
+type ruleParser struct {
+	rule     string
+	pos      int
+	compiler *ruleCompiler
+}
+
+func (p *ruleParser) skipSpace() {
+	for p.pos < len(p.rule) && (p.rule[p.pos] == ' ' || p.rule[p.pos] == '\t') {
+		p.pos++
 	}
+}
 
-	// 类型断言并返回布尔结果
-	if booleanResult, ok := result.(bool); ok {
-		return booleanResult, nil
+func (p *ruleParser) consume(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.rule[p.pos:], token) {
+		p.pos += len(token)
+		return true
 	}
-	return false, errors.New("结果不是布尔类型")
+	return false
 }
 
-func regexMatch(pattern string, s string) (bool, error) {
-	matched, err := regexp.MatchString(pattern, s)
+func (p *ruleParser) parseOr() (ruleNode, error) {
+	left, err := p.parseAnd()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	return matched, nil
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left, right}
+	}
+	return left, nil
 }
 
-// body="123"  op=0  dataSource为http.body dataRule=123
-func dataCheckString(op int16, dataSource string, dataRule string) bool {
-	if dataSource == "" {
-		return false
+func (p *ruleParser) parseAnd() (ruleNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
 	}
-	dataRule = strings.ToLower(dataRule)
-	dataRule = strings.ReplaceAll(dataRule, "\\\"", "\"")
-	switch op {
-	case 0:
-		if strings.Contains(dataSource, dataRule) {
-			return true
+	for p.consume("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
 		}
-	case 1:
-		if !strings.Contains(dataSource, dataRule) {
-			return true
+		left = &andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseUnary() (ruleNode, error) {
+	p.skipSpace()
+	if p.pos >= len(p.rule) {
+		return nil, errors.New("规则不完整")
+	}
+	switch p.rule[p.pos] {
+	case '!':
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
 		}
-	case 2:
-		if dataSource == dataRule {
-			return true
+		return &notNode{node}, nil
+	case '(':
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
 		}
-	case 5:
-		rs, err := regexMatch(dataRule, dataSource)
-		if err == nil && rs {
-			return true
+		if !p.consume(")") {
+			return nil, fmt.Errorf("第 %d 个字符处缺少 )", p.pos+1)
 		}
+		return node, nil
 	}
-	return false
+	return p.parseCond()
 }
 
-func dataCheckInt(op int16, dataSource int, dataRule int) bool {
-	switch op {
-	// 数字相等
-	case 0:
-		if dataSource == dataRule {
-			return true
+func (p *ruleParser) parseCond() (ruleNode, error) {
+	start := p.pos
+	for p.pos < len(p.rule) && isRuleKeyChar(p.rule[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos {
+		return nil, fmt.Errorf("第 %d 个字符处缺少规则关键字", p.pos+1)
+	}
+	// JS 全局变量区分大小写，其余关键字统一小写
+	key := p.rule[start:p.pos]
+	if kind, name, ok := strings.Cut(key, "."); ok && strings.EqualFold(kind, "js") {
+		key = "js." + name
+	} else {
+		key = strings.ToLower(key)
+	}
+
+	op := int16(-1)
+	for _, item := range ruleOperators {
+		if p.consume(item.token) {
+			op = item.op
+			break
 		}
-	// 数字不相等
-	case 1:
-		if dataSource != dataRule {
-			return true
+	}
+	if op < 0 {
+		return nil, fmt.Errorf("第 %d 个字符处缺少运算符", p.pos+1)
+	}
+	if !p.consume("\"") {
+		return nil, fmt.Errorf("第 %d 个字符处缺少引号", p.pos+1)
+	}
+	// 值中的 \" 表示引号本身，其余转义原样保留供正则使用
+	var value strings.Builder
+	for {
+		if p.pos >= len(p.rule) {
+			return nil, fmt.Errorf("%s 的值缺少结束引号", key)
 		}
-	// 大于等于
-	case 3:
-		if dataSource >= dataRule {
-			return true
+		ch := p.rule[p.pos]
+		if ch == '\\' && p.pos+1 < len(p.rule) && p.rule[p.pos+1] == '"' {
+			value.WriteByte('"')
+			p.pos += 2
+			continue
 		}
-	case 4:
-		if dataSource <= dataRule {
-			return true
+		p.pos++
+		if ch == '"' {
+			break
 		}
+		value.WriteByte(ch)
 	}
-	return false
+	return p.compiler.cond(key, op, value.String())
+}
+
+// 除字母数字外，. 用于分隔带名称的关键字，- : $ 出现在响应头、meta 名称与 JS 变量名中
+func isRuleKeyChar(ch byte) bool {
+	return ch == '_' || ch == '.' || ch == '-' || ch == ':' || ch == '$' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
 }
 
 var WorkFlowDB map[string][]string
 
 func (config *Config) InitAll(ctx context.Context) bool {
 	FingerprintDB = nil
+	ActiveFingerprintDB = nil
 	if err := config.InitFingprintDB(ctx, config.FingerprintRuleFile); err != nil {
 		gologger.Error(ctx, err)
 		return false
 	}
-	if err := config.InitActiveScanPath(config.ActiveRuleFile); err != nil {
+	if err := config.InitActiveScanPath(ctx, config.ActiveRuleFile); err != nil {
 		gologger.Error(ctx, err)
 		return false
 	}
@@ -301,6 +745,14 @@ func (config *Config) InitAll(ctx context.Context) bool {
 		gologger.Error(ctx, err)
 		return false
 	}
+	// 默认口令库加载失败不影响指纹识别
+	if err := config.InitWebCredentialDB(config.WebCredentialFile); err != nil {
+		gologger.Warning(ctx, fmt.Sprintf("[default-login] load %s failed: %v", config.WebCredentialFile, err))
+	}
+	// CVE 数据集加载失败时仅不输出 CPE 与 CVE
+	if err := config.InitCVEDB(config.CVEFile); err != nil {
+		gologger.Warning(ctx, fmt.Sprintf("[cve] load %s failed: %v", config.CVEFile, err))
+	}
 	return true
 }
 
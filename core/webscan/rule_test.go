@@ -0,0 +1,334 @@
+package webscan
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeywordMatcher(t *testing.T) {
+	m := newKeywordMatcher()
+	for _, keyword := range []string{"he", "she", "his", "hers", "usher"} {
+		m.add(keyword)
+	}
+	m.build()
+	if hits := m.match("ushers"); !reflect.DeepEqual(hits, []bool{true, true, false, true, true}) {
+		t.Fatalf("match() = %v", hits)
+	}
+}
+
+func TestCompileRule(t *testing.T) {
+	compiler := newRuleCompiler()
+	rules := map[string]bool{
+		`body="tomcat" && title="apache"`:                   true,
+		`body="nginx" || (title="apache" && !header="iis")`: true,
+		`body="say \"hi\""`:                                 true,
+		`body~="version (\d+)\.\d+" && status="200"`:        true,
+		`status>="500" || port="8080"`:                      false,
+		`server=="nginx"`:                                   false,
+		`body!="tomcat"`:                                    false,
+		`protocol="ssh"`:                                    false,
+		`header="x-powered-by: php" || banner="ssh"`:        false,
+	}
+	nodes := make(map[string]ruleNode)
+	for rule := range rules {
+		node, err := compiler.compile(rule)
+		if err != nil {
+			t.Fatalf("compile(%s) returned an error: %v", rule, err)
+		}
+		nodes[rule] = node
+	}
+	compiler.build()
+
+	web := &WebInfo{
+		Title:       "apache tomcat",
+		BodyString:  `<h1>apache tomcat</h1> version 9.0 say "hi"`,
+		HeadeString: "server: nginx/1.20\r\n",
+		Server:      "nginx/1.20",
+		StatusCode:  200,
+		Port:        443,
+	}
+	state := newMatchState(web)
+	for rule, want := range rules {
+		if got := nodes[rule].eval(state); got != want {
+			t.Errorf("eval(%s) = %v, want %v", rule, got, want)
+		}
+	}
+
+	for rule, want := range map[string]string{
+		`body="a" &&`:        "规则不完整",
+		`(body="a"`:          "缺少 )",
+		`body="a`:            "缺少结束引号",
+		`bodyx="a"`:          "未知的规则关键字",
+		`status="ok"`:        "不是整数",
+		`title>="a"`:         "不支持",
+		`body~="(unclosed"`:  "编译失败",
+		`body="a" title="b"`: "多余内容",
+		`protocol~="ssh"`:    "仅支持",
+	} {
+		if _, err := compiler.compile(rule); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("compile(%s) error = %v, want %s", rule, err, want)
+		}
+	}
+}
+
+func TestAnalyzeRule(t *testing.T) {
+	compiler := newRuleCompiler()
+	rules := map[string]string{
+		`body="a" && title="b"`:                       "",
+		`status="200" && status="404"`:                "永远无法命中",
+		`body="a" && !body="a"`:                       "永远无法命中",
+		`status="200" && (status="404" || title="x")`: `status="404" 不影响匹配结果`,
+		`body="a" || body="a" && title="b"`:           `body="a" && title="b" 不影响匹配结果`,
+		`port>="8000" && port<="8100"`:                "",
+	}
+	nodes := make(map[string]ruleNode)
+	for rule := range rules {
+		node, err := compiler.compile(rule)
+		if err != nil {
+			t.Fatalf("compile(%s) returned an error: %v", rule, err)
+		}
+		nodes[rule] = node
+	}
+	compiler.build()
+	for rule, want := range rules {
+		issues := analyzeRule(nodes[rule])
+		if want == "" {
+			if len(issues) != 0 {
+				t.Errorf("analyzeRule(%s) = %v", rule, issues)
+			}
+			continue
+		}
+		if len(issues) != 1 || !strings.Contains(issues[0].Message, want) {
+			t.Errorf("analyzeRule(%s) = %v, want %s", rule, issues, want)
+		}
+	}
+}
+
+func TestIdentifyVersion(t *testing.T) {
+	config := &Config{}
+	if err := config.InitCVEDB(filepath.Join(t.TempDir(), "cve.yaml")); err != nil {
+		t.Fatalf("InitCVEDB() returned an error: %v", err)
+	}
+	defer config.InitCVEDB("")
+
+	compiler := newRuleCompiler()
+	var db []FingerPEntity
+	for _, item := range [][2]string{
+		{"Apache-HTTPD", `server="apache"`},
+		{"Apache-HTTPD", `server~="apache/(?P<version>[\d.]+)"`},
+		{"Grafana", `body~="grafana v(?P<version>[\d.]+)"`},
+		{"Nginx", `server="nginx"`},
+	} {
+		node, err := compiler.compile(item[1])
+		if err != nil {
+			t.Fatalf("compile(%s) returned an error: %v", item[1], err)
+		}
+		db = append(db, FingerPEntity{ProductName: item[0], AllString: item[1], Rule: node, versioned: ruleHasVersion(node)})
+	}
+	compiler.build()
+
+	fingerprints, versions := Identify(&WebInfo{Server: "apache/2.4.49 (unix)", BodyString: "grafana v8.3.1"}, db)
+	if !reflect.DeepEqual(fingerprints, []string{"Apache-HTTPD", "Grafana"}) {
+		t.Fatalf("Identify() fingerprints = %v", fingerprints)
+	}
+	if versions["Apache-HTTPD"] != "2.4.49" || versions["Grafana"] != "8.3.1" {
+		t.Fatalf("Identify() versions = %v", versions)
+	}
+
+	details := FingerprintDetails(fingerprints, versions)
+	if len(details) != 2 || details[0].CPE != "cpe:2.3:a:apache:http_server:2.4.49:*:*:*:*:*:*:*" || len(details[0].CVEs) != 2 || len(details[1].CVEs) != 0 {
+		t.Fatalf("FingerprintDetails() = %+v", details)
+	}
+	include, skip := CVETemplates(details)
+	if !reflect.DeepEqual(include, []string{"CVE-2021-41773", "CVE-2021-42013"}) || !reflect.DeepEqual(skip, []string{"CVE-2021-43798"}) {
+		t.Fatalf("CVETemplates() = %v, %v", include, skip)
+	}
+
+	// 指纹库没有版本提取规则时使用内置规则
+	fingerprints, versions = Identify(&WebInfo{Server: "nginx/1.20.1"}, db)
+	if !reflect.DeepEqual(fingerprints, []string{"Nginx"}) || versions["Nginx"] != "1.20.1" {
+		t.Fatalf("Identify() builtin version = %v, %v", fingerprints, versions)
+	}
+	// 版本不完整时不跳过模板
+	_, skip = CVETemplates(FingerprintDetails([]string{"Grafana"}, map[string]string{"Grafana": "9"}))
+	if len(skip) != 0 {
+		t.Fatalf("CVETemplates() skipped %v for an ambiguous version", skip)
+	}
+
+	for _, c := range []struct {
+		a, b string
+		want int
+	}{
+		{"2.4", "2.4.0", 0},
+		{"2.4.9", "2.4.10", -1},
+		{"v12.2.1.4.0", "12.2.1.3.0", 1},
+		{"1.0.0-rc1", "1.0.0-beta2", 1},
+	} {
+		if got := compareVersion(c.a, c.b); got != c.want {
+			t.Errorf("compareVersion(%s, %s) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestNamedRuleKeys(t *testing.T) {
+	body := `<html><head><meta name="generator" content="WordPress 6.1"><meta property="og:site_name" content="Blog">` +
+		`<script src="/wp-includes/js/jquery/jquery.min.js?ver=3.6.1"></script></head></html>`
+	web := &WebInfo{
+		HeadeString:   strings.ToLower("HTTP/1.1 302 Found\r\nX-Powered-By: PHP/8.1.2\r\nSet-Cookie: PHPSESSID=abc; path=/\r\nSet-Cookie: wordpress_test_cookie=WP%20Cookie\r\nLocation: /wp-login.php\r\n"),
+		BodyString:    strings.ToLower(body),
+		ContentLength: len(body),
+		JSGlobals:     map[string]string{"jQuery.fn.jquery": "3.6.1"},
+		DOM:           map[string]bool{"#wpadminbar": true},
+		JARM:          "27d40d40d29d40d1dc42d43d00041d4689ee210389f4f6b4b5b1b93f92252d",
+	}
+	web.parseResponse([]byte(body))
+
+	compiler := newRuleCompiler()
+	rules := map[string]bool{
+		`header.x-powered-by~="php/(?P<version>[\d.]+)"`:          true,
+		`header.X-Powered-By="asp.net"`:                           false,
+		`header.x-frame-options=""`:                               false,
+		`cookie="phpsessid" && cookie="wordpress_test_cookie"`:    true,
+		`location=="/wp-login.php"`:                               true,
+		`meta.generator="wordpress" && meta.og:site_name=="blog"`: true,
+		`script~="(?m)/wp-includes/.*jquery"`:                     true,
+		`length>="100" && length<="1000"`:                         true,
+		`body_mdhash=="` + web.BodyMd5 + `"`:                      true,
+		`body_hash="` + web.BodyHash + `"`:                        true,
+		`js.jQuery.fn.jquery~="^3\."`:                             true,
+		`js.jquery.fn.jquery=""`:                                  false,
+		`dom="#wpadminbar" && dom!="#app"`:                        true,
+		`jarm=="` + web.JARM + `"`:                                true,
+	}
+	nodes := make(map[string]ruleNode)
+	for rule := range rules {
+		node, err := compiler.compile(rule)
+		if err != nil {
+			t.Fatalf("compile(%s) returned an error: %v", rule, err)
+		}
+		nodes[rule] = node
+	}
+	compiler.build()
+	state := newMatchState(web)
+	for rule, want := range rules {
+		if got := nodes[rule].eval(state); got != want {
+			t.Errorf("eval(%s) = %v, want %v", rule, got, want)
+		}
+	}
+	if globals, selectors := compiler.browserProbes(); !reflect.DeepEqual(globals, []string{"jQuery.fn.jquery", "jquery.fn.jquery"}) || !reflect.DeepEqual(selectors, []string{"#app", "#wpadminbar"}) {
+		t.Errorf("browserProbes() = %v, %v", globals, selectors)
+	}
+
+	for rule, want := range map[string]string{
+		`cookies="a"`:   "未知的规则关键字",
+		`header.="a"`:   "未知的规则关键字",
+		`title.x="a"`:   "未知的规则关键字",
+		`dom~="#app"`:   "仅支持",
+		`dom=""`:        "不能为空",
+		`length~="100"`: "不支持",
+	} {
+		if _, err := compiler.compile(rule); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("compile(%s) error = %v, want %s", rule, err, want)
+		}
+	}
+}
+
+func TestInitActiveScanPath(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "dir.yaml")
+	os.WriteFile(file, []byte(`Nacos:
+  - /nacos/
+  - path: /nacos/v1/console/server/state
+    requires: [java]
+Spring-Actuator:
+  - path: /actuator
+    headers:
+      Accept: application/json
+    match: 'content_type="application/vnd.spring-boot.actuator"'
+Weblogic:
+  - method: raw
+    body: "t3 12.2.1\nAS:255\nHL:19\n\n"
+    match: 'banner~="^helo:(?P<version>\d+(\.\d+)+)"'
+Unknown:
+  - /unknown/
+`), 0644)
+	compiler := newRuleCompiler()
+	node, _ := compiler.compile(`title="nacos"`)
+	compiler.build()
+	FingerprintDB = []FingerPEntity{{ProductName: "Nacos", AllString: `title="nacos"`, Rule: node}}
+	ActiveFingerprintDB = nil
+	defer func() { FingerprintDB, ActiveFingerprintDB = nil, nil }()
+
+	config := &Config{}
+	if err := config.InitActiveScanPath(context.Background(), file); err != nil {
+		t.Fatalf("InitActiveScanPath() returned an error: %v", err)
+	}
+	probes := make(map[string][]ActiveProbe)
+	for _, item := range ActiveFingerprintDB {
+		probes[item.ProductName] = item.Probes
+	}
+	if len(probes) != 3 || len(probes["Nacos"]) != 2 || len(probes["Spring-Actuator"]) != 1 || len(probes["Weblogic"]) != 1 {
+		t.Fatalf("ActiveFingerprintDB = %+v", probes)
+	}
+	if p := probes["Nacos"][1]; p.Method != "GET" || !reflect.DeepEqual(p.Requires, []string{"java"}) || p.fpe != nil {
+		t.Errorf("Nacos probe = %+v", p)
+	}
+	if p := probes["Spring-Actuator"][0]; p.Headers["Accept"] != "application/json" || p.fpe == nil {
+		t.Errorf("Spring-Actuator probe = %+v", p)
+	}
+	if !hasAnyFingerprint([]string{"Nginx", "Java"}, probes["Nacos"][1].Requires) || hasAnyFingerprint([]string{"Nginx"}, probes["Nacos"][1].Requires) {
+		t.Error("hasAnyFingerprint() mismatch")
+	}
+
+	// T3 握手通过 RAW 探测发送到网站端口
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 64)
+		if n, _ := conn.Read(buf); strings.HasPrefix(string(buf[:n]), "t3 ") {
+			conn.Write([]byte("HELO:12.2.1.3.0.false\nAS:2048\nHL:19\n\n"))
+		}
+	}()
+	// RAW 探测同样经过扫描代理
+	proxy, tunnels := newConnectProxy(t)
+	weblogic := probes["Weblogic"][0]
+	banner, err := (&FingerScanner{proxyURL: proxy.URL}).rawProbe(&url.URL{Scheme: "http", Host: listener.Addr().String()}, weblogic.Body)
+	if err != nil {
+		t.Fatalf("rawProbe() returned an error: %v", err)
+	}
+	result, versions := Identify(&WebInfo{Banner: strings.ToLower(banner)}, weblogic.fpe)
+	if !reflect.DeepEqual(result, []string{"Weblogic"}) || versions["Weblogic"] != "12.2.1.3.0" {
+		t.Errorf("Identify() = %v, %v", result, versions)
+	}
+	if tunnels.Load() != 1 {
+		t.Errorf("rawProbe() tunnels = %d, want 1", tunnels.Load())
+	}
+
+	for _, c := range []struct {
+		probe ActiveProbe
+		ok    bool
+	}{
+		{ActiveProbe{}, true},
+		{ActiveProbe{Method: "options"}, true},
+		{ActiveProbe{Method: "DELETE"}, false},
+		{ActiveProbe{Method: "PUT", Unsafe: true}, true},
+		{ActiveProbe{Method: "TRACE"}, false},
+	} {
+		if err := checkActiveMethod(&c.probe); (err == nil) != c.ok {
+			t.Errorf("checkActiveMethod(%s) = %v", c.probe.Method, err)
+		}
+	}
+}
@@ -2,12 +2,15 @@ package webscan
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"net/http"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slack-wails/lib/assets"
+	"slack-wails/lib/browser"
 	"slack-wails/lib/utils"
-	"time"
+	"strings"
 
 	"github.com/chromedp/chromedp"
 )
@@ -15,20 +18,8 @@ import (
 var dir = filepath.Join(utils.HomeDir(), "slack", "screenshot")
 
 func init() {
-	// 创建截屏文件服务器
-	go func() {
-		fs := http.FileServer(http.Dir(dir))
-
-		// 创建独立的 ServeMux
-		mux := http.NewServeMux()
-		mux.Handle("/screenhost/", http.StripPrefix("/screenhost", fs))
-
-		// 启动 HTTP 服务器
-		err := http.ListenAndServe(":8732", mux)
-		if err != nil {
-			return
-		}
-	}()
+	// 截图通过本地资源服务的 /screenshot/ 路径访问
+	assets.Shared().Register("screenshot", dir)
 }
 
 // GetScreenshot 获取指定URL的屏幕截图，并保存到本地文件。
@@ -40,22 +31,9 @@ func GetScreenshot(url string) (string, error) {
 	if _, err := os.Stat(fp); err == nil {
 		return fp, nil
 	}
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-background-timer-throttling", false),
-	)
-	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	defer cancel()
-	// 创建一个浏览器实例
-	ctx, cancel := chromedp.NewContext(allocCtx)
-	defer cancel()
-	// 运行任务
+	// 使用共享浏览器池，等待网络空闲或指定选择器出现后截图
 	var buf []byte
-	if err := chromedp.Run(ctx,
-		chromedp.Navigate(url),
-		chromedp.Sleep(1*time.Second), // 等待页面加载完成
-		chromedp.FullScreenshot(&buf, 100),
-	); err != nil {
+	if err := browser.Shared().Run(context.Background(), url, chromedp.FullScreenshot(&buf, 100)); err != nil {
 		return "", errors.New("无法获取屏幕截图")
 	}
 
@@ -71,3 +49,42 @@ func GetScreenshot(url string) (string, error) {
 
 	return fp, nil
 }
+
+// 读取 JS 全局变量时对象与函数只返回类型，其余值转为字符串
+const evaluatePageJS = `(() => {
+	const globals = {}, dom = {};
+	for (const path of %s) {
+		try {
+			const value = path.split('.').reduce((obj, key) => obj == null ? undefined : obj[key], window);
+			if (value !== undefined && value !== null) {
+				globals[path] = (typeof value === 'object' || typeof value === 'function') ? typeof value : String(value);
+			}
+		} catch (e) {}
+	}
+	for (const selector of %s) {
+		try { dom[selector] = document.querySelector(selector) !== null; } catch (e) {}
+	}
+	return { globals, dom };
+})()`
+
+// EvaluatePage 在无头浏览器中打开页面，读取指定的 JS 全局变量并检查 CSS 选择器是否存在，变量值统一转为小写
+func EvaluatePage(url string, globals, selectors []string) (map[string]string, map[string]bool, error) {
+	globalsJSON, _ := json.Marshal(globals)
+	selectorsJSON, _ := json.Marshal(selectors)
+	var result struct {
+		Globals map[string]string `json:"globals"`
+		DOM     map[string]bool   `json:"dom"`
+	}
+	if err := browser.Shared().Run(context.Background(), url,
+		chromedp.Evaluate(fmt.Sprintf(evaluatePageJS, globalsJSON, selectorsJSON), &result),
+	); err != nil {
+		return nil, nil, fmt.Errorf("%s 页面计算失败: %v", url, err)
+	}
+	for path, value := range result.Globals {
+		result.Globals[path] = strings.ToLower(value)
+	}
+	if result.DOM == nil {
+		result.DOM = make(map[string]bool)
+	}
+	return result.Globals, result.DOM, nil
+}
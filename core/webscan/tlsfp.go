@@ -0,0 +1,330 @@
+package webscan
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hdm/jarm-go"
+	"golang.org/x/net/proxy"
+)
+
+// 单次连接与握手的超时时间
+const tlsDialTimeout = 3 * time.Second
+
+// tlsProber 对同一地址进行多次 TLS 握手，连接经由扫描代理建立，SNI 使用网站域名
+type tlsProber struct {
+	address    string
+	serverName string
+	dialer     proxy.ContextDialer
+}
+
+func newTLSProber(address string, options TLSProbeOptions) (*tlsProber, error) {
+	serverName := options.ServerName
+	if serverName == "" {
+		serverName, _, _ = net.SplitHostPort(address)
+	}
+	dialer, err := newProxyDialer(options.ProxyURL, tlsDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &tlsProber{address: address, serverName: serverName, dialer: dialer}, nil
+}
+
+// newProxyDialer 返回建立 TCP 连接的 Dialer，设置代理时经代理连接，支持 socks5 与 http CONNECT
+func newProxyDialer(proxyURL string, timeout time.Duration) (proxy.ContextDialer, error) {
+	if proxyURL == "" {
+		return &net.Dialer{Timeout: timeout}, nil
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		return &connectDialer{proxy: u, timeout: timeout}, nil
+	}
+	d, err := proxy.FromURL(u, &net.Dialer{Timeout: timeout})
+	if err != nil {
+		return nil, err
+	}
+	contextDialer, ok := d.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported proxy: %s", proxyURL)
+	}
+	return contextDialer, nil
+}
+
+// dial 建立 TCP 连接，读写截止时间取单次超时与总截止时间中较早的一个
+func (p *tlsProber) dial(ctx context.Context) (net.Conn, error) {
+	deadline := time.Now().Add(tlsDialTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	dialCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	conn, err := p.dialer.DialContext(dialCtx, "tcp", p.address)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(deadline)
+	return conn, nil
+}
+
+// connectDialer 通过 HTTP 代理的 CONNECT 方法建立隧道
+type connectDialer struct {
+	proxy   *url.URL
+	timeout time.Duration
+}
+
+func (d *connectDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := (&net.Dialer{Timeout: d.timeout}).DialContext(ctx, network, d.proxy.Host)
+	if err != nil {
+		return nil, err
+	}
+	if d.proxy.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: d.proxy.Hostname()})
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	req := &http.Request{Method: http.MethodConnect, URL: &url.URL{Opaque: address}, Host: address, Header: make(http.Header)}
+	if d.proxy.User != nil {
+		password, _ := d.proxy.User.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(d.proxy.User.Username()+":"+password)))
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// 客户端发送 ClientHello 之前目标不会发送数据，缓冲区中只有代理的响应
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy connect %s: %s", address, resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// JARM 10 次探测均无响应时的哈希，视为无法获取
+const emptyJARM = "00000000000000000000000000000000000000000000000000000000000000"
+
+// jarmFingerprint 并发发送 JARM 的 10 个 ClientHello 并根据 ServerHello 计算指纹
+func (p *tlsProber) jarmFingerprint(ctx context.Context) string {
+	_, portStr, err := net.SplitHostPort(p.address)
+	if err != nil {
+		return ""
+	}
+	port, _ := strconv.Atoi(portStr)
+	probes := jarm.GetProbes(p.serverName, port)
+	results := make([]string, len(probes))
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = p.jarmProbe(ctx, probe)
+		}()
+	}
+	wg.Wait()
+	hash := jarm.RawHashToFuzzyHash(strings.Join(results, ","))
+	if hash == emptyJARM {
+		return ""
+	}
+	return hash
+}
+
+func (p *tlsProber) jarmProbe(ctx context.Context, probe jarm.JarmProbeOptions) string {
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return ""
+	}
+	defer conn.Close()
+	if _, err := conn.Write(jarm.BuildProbe(probe)); err != nil {
+		return ""
+	}
+	// 与官方实现一致，最多读取 1484 字节，读取长度不同会导致哈希不同
+	buf := make([]byte, 1484)
+	n, _ := conn.Read(buf)
+	result, err := jarm.ParseServerHello(buf[:n], probe)
+	if err != nil {
+		return ""
+	}
+	return result
+}
+
+// recordConn 记录服务端发送的原始数据，用于在标准库握手后解析 ServerHello
+type recordConn struct {
+	net.Conn
+	mu   sync.Mutex
+	read []byte
+}
+
+func (c *recordConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.mu.Lock()
+	// ServerHello 位于最开始，只需保留前 16KB
+	if len(c.read) < 16*1024 {
+		c.read = append(c.read, b[:n]...)
+	}
+	c.mu.Unlock()
+	return n, err
+}
+
+// ja3s 根据 ServerHello 计算 JA3S: SSLVersion,Cipher,Extensions 的 MD5
+func ja3s(raw []byte) string {
+	version, cipher, extensions, ok := parseServerHello(raw)
+	if !ok {
+		return ""
+	}
+	exts := make([]string, len(extensions))
+	for i, ext := range extensions {
+		exts[i] = strconv.Itoa(int(ext))
+	}
+	sum := md5.Sum([]byte(fmt.Sprintf("%d,%d,%s", version, cipher, strings.Join(exts, "-"))))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseServerHello 从 TLS 记录中解析 ServerHello 的版本、加密套件与扩展类型
+func parseServerHello(raw []byte) (version, cipher uint16, extensions []uint16, ok bool) {
+	// 握手消息可能跨多个记录，先拼接握手类型的记录内容
+	var handshake []byte
+	for len(raw) >= 5 && raw[0] == 0x16 {
+		length := int(binary.BigEndian.Uint16(raw[3:5]))
+		if len(raw) < 5+length {
+			handshake = append(handshake, raw[5:]...)
+			break
+		}
+		handshake = append(handshake, raw[5:5+length]...)
+		raw = raw[5+length:]
+		if len(handshake) >= 4 {
+			msgLen := int(handshake[1])<<16 | int(handshake[2])<<8 | int(handshake[3])
+			if len(handshake) >= 4+msgLen {
+				break
+			}
+		}
+	}
+	if len(handshake) < 4 || handshake[0] != 0x02 {
+		return
+	}
+	body := handshake[4:]
+	// version(2) + random(32) + session id
+	if len(body) < 35 {
+		return
+	}
+	version = binary.BigEndian.Uint16(body[0:2])
+	sidLen := int(body[34])
+	p := 35 + sidLen
+	if len(body) < p+3 {
+		return
+	}
+	cipher = binary.BigEndian.Uint16(body[p : p+2])
+	p += 3 // cipher suite + compression method
+	if len(body) >= p+2 {
+		extLen := int(binary.BigEndian.Uint16(body[p : p+2]))
+		p += 2
+		end := min(p+extLen, len(body))
+		for p+4 <= end {
+			extensions = append(extensions, binary.BigEndian.Uint16(body[p:p+2]))
+			p += 4 + int(binary.BigEndian.Uint16(body[p+2:p+4]))
+		}
+	}
+	return version, cipher, extensions, true
+}
+
+// 已经废弃的协议版本，Go 不支持 SSLv3 因此无法探测
+var weakProtocols = []struct {
+	name    string
+	version uint16
+}{
+	{"TLS 1.0", tls.VersionTLS10},
+	{"TLS 1.1", tls.VersionTLS11},
+}
+
+// weakTLS 探测服务端是否接受 TLS 1.0/1.1 以及不安全的加密套件，协议探测与套件枚举并发进行
+func (p *tlsProber) weakTLS(ctx context.Context) (protocols, ciphers []string) {
+	accepted := make([]bool, len(weakProtocols))
+	var wg sync.WaitGroup
+	for i, weak := range weakProtocols {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, accepted[i] = p.handshake(ctx, &tls.Config{
+				InsecureSkipVerify: true,
+				ServerName:         p.serverName,
+				MinVersion:         weak.version,
+				MaxVersion:         weak.version,
+			})
+		}()
+	}
+	ciphers = p.weakCiphers(ctx)
+	wg.Wait()
+	for i, weak := range weakProtocols {
+		if accepted[i] {
+			protocols = append(protocols, weak.name)
+		}
+	}
+	return protocols, ciphers
+}
+
+// weakCiphers 每次握手只会协商出一个套件，去掉已协商的套件后继续尝试
+func (p *tlsProber) weakCiphers(ctx context.Context) (ciphers []string) {
+	var suites []uint16
+	for _, suite := range tls.InsecureCipherSuites() {
+		suites = append(suites, suite.ID)
+	}
+	for len(suites) > 0 && ctx.Err() == nil {
+		state, ok := p.handshake(ctx, &tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         p.serverName,
+			MinVersion:         tls.VersionTLS10,
+			MaxVersion:         tls.VersionTLS12,
+			CipherSuites:       suites,
+		})
+		if !ok {
+			break
+		}
+		ciphers = append(ciphers, tls.CipherSuiteName(state.CipherSuite))
+		remaining := suites[:0]
+		for _, id := range suites {
+			if id != state.CipherSuite {
+				remaining = append(remaining, id)
+			}
+		}
+		if len(remaining) == len(suites) {
+			break
+		}
+		suites = remaining
+	}
+	return ciphers
+}
+
+func (p *tlsProber) handshake(ctx context.Context, config *tls.Config) (tls.ConnectionState, bool) {
+	rawConn, err := p.dial(ctx)
+	if err != nil {
+		return tls.ConnectionState{}, false
+	}
+	conn := tls.Client(rawConn, config)
+	defer conn.Close()
+	if err := conn.Handshake(); err != nil {
+		return tls.ConnectionState{}, false
+	}
+	return conn.ConnectionState(), true
+}
@@ -0,0 +1,113 @@
+package webscan
+
+import (
+	"crypto/tls"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseServerHello(t *testing.T) {
+	body := []byte{0x03, 0x03}
+	body = append(body, make([]byte, 32)...)          // random
+	body = append(body, 0x00)                         // session id
+	body = append(body, 0xc0, 0x2f, 0x00)             // cipher + compression
+	body = append(body, 0x00, 0x09)                   // extensions length
+	body = append(body, 0xff, 0x01, 0x00, 0x01, 0x00) // renegotiation_info
+	body = append(body, 0x00, 0x0b, 0x00, 0x00)       // ec_point_formats
+	handshake := append([]byte{0x02, 0x00, 0x00, byte(len(body))}, body...)
+	// ServerHello 拆分到两个记录中
+	raw := append([]byte{0x16, 0x03, 0x03, 0x00, 0x10}, handshake[:16]...)
+	raw = append(raw, 0x16, 0x03, 0x03, 0x00, byte(len(handshake)-16))
+	raw = append(raw, handshake[16:]...)
+
+	version, cipher, extensions, ok := parseServerHello(raw)
+	if !ok || version != 0x0303 || cipher != 0xc02f || !slices.Equal(extensions, []uint16{0xff01, 0x0b}) {
+		t.Fatalf("parseServerHello() = %x, %x, %v, %v", version, cipher, extensions, ok)
+	}
+	// md5("771,49199,65281-11")
+	if got := ja3s(raw); got != "303951d4c50efb2e991652225a6f02b1" {
+		t.Errorf("ja3s() = %s", got)
+	}
+}
+
+func TestGetTLSInfo(t *testing.T) {
+	var serverNames sync.Map
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// JARM 与弱协议探测会产生大量握手失败日志
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.TLS = &tls.Config{GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		serverNames.Store(hello.ServerName, true)
+		return nil, nil
+	}}
+	server.StartTLS()
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	// 默认只进行一次握手
+	cert, info := GetTLSInfo(u.Scheme, u.Host, TLSProbeOptions{})
+	if info == nil {
+		t.Fatal("GetTLSInfo() returned nil")
+	}
+	if cert == "" || info.JA3S == "" || info.Version == "" || info.JARM != "" {
+		t.Errorf("GetTLSInfo() = %q, %+v", cert, info)
+	}
+	if !slices.Contains(info.SANs, "example.com") || !slices.Contains(SANDomains(info), "example.com") || slices.Contains(SANDomains(info), "127.0.0.1") {
+		t.Errorf("SANs = %v, SANDomains = %v", info.SANs, SANDomains(info))
+	}
+	if _, info := GetTLSInfo("http", u.Host, TLSProbeOptions{}); info != nil {
+		t.Errorf("GetTLSInfo(http) = %+v", info)
+	}
+
+	// 经过 HTTP 代理，SNI 使用虚拟主机域名
+	proxy, tunnels := newConnectProxy(t)
+	start := time.Now()
+	_, info = GetTLSInfo(u.Scheme, u.Host, TLSProbeOptions{ServerName: "www.example.com", ProxyURL: proxy.URL, Deep: true})
+	if info == nil || info.JARM == "" {
+		t.Fatalf("GetTLSInfo(deep) = %+v", info)
+	}
+	if elapsed := time.Since(start); elapsed > tlsProbeTimeout {
+		t.Errorf("GetTLSInfo(deep) took %s", elapsed)
+	}
+	if tunnels.Load() < 11 {
+		t.Errorf("expected all handshakes through the proxy, got %d", tunnels.Load())
+	}
+	if _, ok := serverNames.Load("www.example.com"); !ok {
+		t.Error("vhost server name was not sent as SNI")
+	}
+}
+
+// newConnectProxy 只支持 CONNECT 的 HTTP 代理，返回代理与已建立的隧道数量
+func newConnectProxy(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	tunnels := &atomic.Int32{}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "connect only", http.StatusMethodNotAllowed)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		tunnels.Add(1)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go func() {
+			io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy, tunnels
+}
@@ -0,0 +1,146 @@
+package webscan
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slack-wails/core/vhost"
+	"slack-wails/lib/gologger"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// vhostTransport 将以虚拟主机域名访问的请求发往实际的 IP，Host 头与 TLS SNI 保持为域名
+type vhostTransport struct {
+	base  http.RoundTripper
+	hosts map[string]vhostRoute
+}
+
+type vhostRoute struct {
+	address   string
+	transport http.RoundTripper // ServerName 为虚拟主机域名的 Transport，经过代理时同样生效
+}
+
+func newVHostTransport(base http.RoundTripper, hosts map[string]string) *vhostTransport {
+	t := &vhostTransport{base: base, hosts: make(map[string]vhostRoute, len(hosts))}
+	for host, address := range hosts {
+		route := vhostRoute{address: address, transport: base}
+		if transport, ok := base.(*http.Transport); ok {
+			transport = transport.Clone()
+			if transport.TLSClientConfig == nil {
+				transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+			}
+			transport.TLSClientConfig.ServerName, _, _ = net.SplitHostPort(host)
+			if transport.TLSClientConfig.ServerName == "" {
+				transport.TLSClientConfig.ServerName = host
+			}
+			route.transport = transport
+		}
+		t.hosts[host] = route
+	}
+	return t
+}
+
+func (t *vhostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	route, ok := t.hosts[req.URL.Host]
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	if req.Host == "" {
+		req.Host = req.URL.Host
+	}
+	req.URL.Host = route.address
+	return route.transport.RoundTrip(req)
+}
+
+// VHostScan 对存活的 IP 网站目标爆破 Host 头，发现的虚拟主机作为新目标进行指纹识别
+func (s *FingerScanner) VHostScan(ctrlCtx context.Context) {
+	var targets []*url.URL
+	for _, u := range s.aliveURLs {
+		if net.ParseIP(u.Hostname()) != nil {
+			targets = append(targets, u)
+		}
+	}
+	if len(targets) == 0 {
+		gologger.Warning(s.ctx, "No IP web target found, virtual host discovery has been skipped")
+		return
+	}
+	candidates := s.vhostCandidates()
+	gologger.Info(s.ctx, fmt.Sprintf("Virtual host discovery in progress, targets: %d, candidates: %d", len(targets), len(candidates)))
+
+	hosts := make(map[string]string)
+	var found []*url.URL
+	for _, target := range targets {
+		if ctrlCtx.Err() != nil {
+			return
+		}
+		for _, result := range vhost.Discover(ctrlCtx, target.String(), candidates, s.headers, s.notFollowClient, s.thread) {
+			u, err := url.Parse(result.URL)
+			if err != nil || hosts[u.Host] != "" {
+				continue
+			}
+			hosts[u.Host] = target.Host
+			found = append(found, u)
+			result.TaskId = s.taskId
+			runtime.EventsEmit(s.ctx, "webscanVHost", result)
+			gologger.Info(s.ctx, fmt.Sprintf("[vhost] %s -> %s [%d] %s", result.URL, result.Address, result.StatusCode, result.Title))
+		}
+	}
+	if len(found) == 0 || ctrlCtx.Err() != nil {
+		return
+	}
+	s.vhosts = hosts
+	for _, client := range []*resty.Client{s.client, s.notFollowClient} {
+		client.SetTransport(newVHostTransport(client.GetClient().Transport, hosts))
+	}
+	s.urls = append(s.urls, found...)
+	s.fingerScan(ctrlCtx, found)
+}
+
+// vhostCandidates 候选只来自本次扫描的证书 SAN 与任务参数中传入的当前项目子域名、ICP 备案域名及自定义输入，避免沿用其他项目的域名
+func (s *FingerScanner) vhostCandidates() map[string]string {
+	known := make(map[string]string)
+	s.mutex.RLock()
+	for domain := range s.candidateDomains {
+		known[domain] = vhost.SourceCert
+	}
+	s.mutex.RUnlock()
+	// 同一域名出现在多个来源时优先记录为子域名与备案来源
+	for _, domain := range s.vhostICPDomains {
+		known[domain] = vhost.SourceICP
+	}
+	for _, domain := range s.vhostSubdomains {
+		known[domain] = vhost.SourceSubdomain
+	}
+	return vhost.Expand(known, s.vhostDomains)
+}
+
+func (s *FingerScanner) isVHost(u *url.URL) bool {
+	_, ok := s.vhosts[u.Host]
+	return ok
+}
+
+// address 实际建立连接的地址，虚拟主机返回对应的 IP:端口
+func (s *FingerScanner) address(u *url.URL) string {
+	if address, ok := s.vhosts[u.Host]; ok {
+		return address
+	}
+	return u.Host
+}
+
+// VHostAddress 虚拟主机目标返回以 IP 访问的链接及 Host 头，用于漏洞扫描等无法替换连接地址的场景
+func (s *FingerScanner) VHostAddress(target string) (string, string, bool) {
+	u, err := url.Parse(target)
+	if err != nil || !s.isVHost(u) {
+		return "", "", false
+	}
+	host := u.Hostname()
+	ipURL := *u
+	ipURL.Host = s.vhosts[u.Host]
+	return ipURL.String(), host, true
+}
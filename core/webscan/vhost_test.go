@@ -0,0 +1,60 @@
+package webscan
+
+import (
+	"crypto/tls"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slack-wails/core/vhost"
+	"testing"
+)
+
+func TestVHostTransport(t *testing.T) {
+	var serverName, host string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverName, host = r.TLS.ServerName, r.Host
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	base := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	client := &http.Client{Transport: newVHostTransport(base, map[string]string{"app.example.com:8443": u.Host})}
+	resp, err := client.Get("https://app.example.com:8443/")
+	if err != nil {
+		t.Fatalf("Get() returned an error: %v", err)
+	}
+	resp.Body.Close()
+	if serverName != "app.example.com" || host != "app.example.com:8443" {
+		t.Errorf("server name = %q, host = %q", serverName, host)
+	}
+	if base.TLSClientConfig.ServerName != "" {
+		t.Error("base transport should not be modified")
+	}
+}
+
+func TestVHostCandidates(t *testing.T) {
+	s := &FingerScanner{
+		candidateDomains: map[string]bool{"gitlab.example.com": true, "www.example.com": true},
+		vhostSubdomains:  []string{"www.example.com", "vpn.example.com"},
+		vhostICPDomains:  []string{"example.org"},
+		vhostDomains:     []string{"hrms.example.net"},
+	}
+	candidates := s.vhostCandidates()
+	want := map[string]string{
+		"gitlab.example.com": vhost.SourceCert,
+		"www.example.com":    vhost.SourceSubdomain,
+		"vpn.example.com":    vhost.SourceSubdomain,
+		"example.org":        vhost.SourceICP,
+		"hrms.example.net":   vhost.SourceCustom,
+		"oa.example.org":     vhost.SourceWordlist,
+	}
+	for domain, source := range want {
+		if candidates[domain] != source {
+			t.Errorf("candidates[%q] = %q, want %q", domain, candidates[domain], source)
+		}
+	}
+}
@@ -0,0 +1,423 @@
+package webscan
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slack-wails/lib/gologger"
+	"slack-wails/lib/resultstore"
+	"slack-wails/lib/structs"
+	"slack-wails/lib/utils/httputil"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/panjf2000/ants/v2"
+	"github.com/qiwentaidi/clients"
+	"gopkg.in/yaml.v2"
+)
+
+// WebCredential 描述一个 Web 管理后台的默认口令检测方式
+type WebCredential struct {
+	Name         string            `yaml:"name"`
+	Fingerprints []string          `yaml:"fingerprints"` // 与 webfinger.yaml 中的指纹名称对应，不区分大小写
+	Path         string            `yaml:"path"`
+	Method       string            `yaml:"method"`
+	Auth         string            `yaml:"auth"` // basic 使用 Authorization 头，form/json 替换 body 中的 {{username}} {{password}}
+	Body         string            `yaml:"body"`
+	Headers      map[string]string `yaml:"headers"`
+	Success      WebCredMatcher    `yaml:"success"`
+	Lockout      []string          `yaml:"lockout"`      // 响应中出现任意关键字即认为账户被锁定，停止该目标的尝试
+	MaxAttempts  int               `yaml:"max_attempts"` // 单个目标最多尝试次数，防止触发锁定策略
+	Credentials  []string          `yaml:"credentials"`  // username:password
+}
+
+// WebCredMatcher 所有条件同时满足才判定登录成功
+type WebCredMatcher struct {
+	Status    []int    `yaml:"status"`
+	Body      []string `yaml:"body"`
+	Header    []string `yaml:"header"`
+	NotBody   []string `yaml:"not_body"`
+	NotHeader []string `yaml:"not_header"`
+}
+
+var WebCredentialDB []WebCredential
+
+const defaultWebCredMaxAttempts = 5
+
+// 配置文件不存在时写入的内置默认口令库
+const defaultWebCredentialYAML = `# Web 管理后台默认口令库，fingerprints 与 webfinger.yaml 中的指纹名称对应（不区分大小写）
+# auth: basic 使用 Authorization 头; form/json 会替换 body 中的 {{username}} 与 {{password}}
+# success 中的条件需同时满足，关键字均不区分大小写; 请求不跟随跳转，可通过 Location 头判断
+- name: Tomcat Manager
+  fingerprints: [Apache-Tomcat, Tomcat]
+  path: /manager/html
+  method: GET
+  auth: basic
+  success:
+    status: [200]
+    body: [tomcat web application manager]
+  max_attempts: 8
+  credentials: [tomcat:tomcat, admin:admin, tomcat:s3cret, admin:tomcat, both:tomcat, role1:role1, admin:, tomcat:123456]
+- name: Weblogic Console
+  fingerprints: [Weblogic, Oracle-Weblogic, WebLogic-Server]
+  path: /console/j_security_check
+  method: POST
+  auth: form
+  body: j_username={{username}}&j_password={{password}}&j_character_encoding=UTF-8
+  headers:
+    Content-Type: application/x-www-form-urlencoded
+  success:
+    status: [302]
+    header: [/console]
+    not_header: [loginform.jsp]
+  max_attempts: 4
+  credentials: [weblogic:weblogic, weblogic:weblogic1, weblogic:weblogic123, weblogic:Oracle@123]
+- name: Nacos
+  fingerprints: [Nacos, Alibaba-Nacos]
+  path: /nacos/v1/auth/users/login
+  method: POST
+  auth: form
+  body: username={{username}}&password={{password}}
+  headers:
+    Content-Type: application/x-www-form-urlencoded
+  success:
+    status: [200]
+    body: [accesstoken]
+  max_attempts: 3
+  credentials: [nacos:nacos, admin:admin, nacos:123456]
+- name: Jenkins
+  fingerprints: [Jenkins]
+  path: /j_spring_security_check
+  method: POST
+  auth: form
+  body: j_username={{username}}&j_password={{password}}&from=%2F&Submit=Sign+in
+  headers:
+    Content-Type: application/x-www-form-urlencoded
+  success:
+    status: [302]
+    not_header: [loginerror]
+  max_attempts: 4
+  credentials: [admin:admin, jenkins:jenkins, admin:password, admin:123456]
+- name: Grafana
+  fingerprints: [Grafana]
+  path: /login
+  method: POST
+  auth: json
+  body: '{"user":"{{username}}","password":"{{password}}"}'
+  headers:
+    Content-Type: application/json
+  success:
+    status: [200]
+    body: [logged in]
+  lockout: [too many consecutive incorrect login attempts]
+  max_attempts: 3
+  credentials: [admin:admin, admin:grafana, admin:123456]
+- name: Zabbix
+  fingerprints: [Zabbix]
+  path: /index.php
+  method: POST
+  auth: form
+  body: name={{username}}&password={{password}}&autologin=1&enter=Sign+in
+  headers:
+    Content-Type: application/x-www-form-urlencoded
+  success:
+    status: [302]
+    header: ['zabbix.php?action=dashboard']
+  lockout: [account is blocked, 账号被锁定]
+  max_attempts: 3
+  credentials: [Admin:zabbix, admin:zabbix, Guest:]
+- name: Hikvision
+  fingerprints: [Hikvision, HIKVISION-视频监控, 海康威视]
+  path: /ISAPI/Security/userCheck
+  method: GET
+  auth: basic
+  success:
+    status: [200]
+    body: [<statusvalue>200</statusvalue>]
+  lockout: [<lockstatus>lock</lockstatus>, userlocked]
+  max_attempts: 3
+  credentials: [admin:12345, admin:admin12345, admin:hik12345]
+- name: RabbitMQ Management
+  fingerprints: [RabbitMQ]
+  path: /api/whoami
+  method: GET
+  auth: basic
+  success:
+    status: [200]
+    body: ['"name"']
+  max_attempts: 3
+  credentials: [guest:guest, admin:admin, rabbitmq:rabbitmq]
+- name: ActiveMQ Console
+  fingerprints: [ActiveMQ, Apache-ActiveMQ]
+  path: /admin/
+  method: GET
+  auth: basic
+  success:
+    status: [200]
+    body: [activemq]
+  max_attempts: 3
+  credentials: [admin:admin, user:user, system:manager]
+- name: Druid Monitor
+  fingerprints: [Druid, Alibaba-Druid]
+  path: /druid/submitLogin
+  method: POST
+  auth: form
+  body: loginUsername={{username}}&loginPassword={{password}}
+  headers:
+    Content-Type: application/x-www-form-urlencoded
+  success:
+    status: [200]
+    body: [success]
+  max_attempts: 4
+  credentials: [admin:admin, druid:druid, admin:123456, ruoyi:123456]
+- name: Router Admin
+  fingerprints: [TP-LINK, D-Link, Netgear, Tenda, H3C-Router, Ruijie-Router]
+  path: /
+  method: GET
+  auth: basic
+  success:
+    status: [200]
+  max_attempts: 4
+  credentials: [admin:admin, admin:password, admin:, root:admin]
+`
+
+// InitWebCredentialDB 加载默认口令库，文件不存在时写入内置默认配置
+func (config *Config) InitWebCredentialDB(webCredentialFile string) error {
+	WebCredentialDB = nil
+	if _, err := os.Stat(webCredentialFile); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(webCredentialFile), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(webCredentialFile, []byte(defaultWebCredentialYAML), 0644); err != nil {
+			return err
+		}
+	}
+	data, err := os.ReadFile(webCredentialFile)
+	if err != nil {
+		return err
+	}
+	var entries []WebCredential
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		if len(entry.Fingerprints) == 0 || entry.Path == "" || len(entry.Credentials) == 0 {
+			return fmt.Errorf("web credential [%d] %s: fingerprints, path and credentials are required", i, entry.Name)
+		}
+		switch strings.ToLower(entry.Auth) {
+		case "basic", "form", "json":
+		default:
+			return fmt.Errorf("web credential [%d] %s: unsupported auth %q", i, entry.Name, entry.Auth)
+		}
+		if entry.Method == "" {
+			entry.Method = "GET"
+		}
+		if entry.MaxAttempts <= 0 {
+			entry.MaxAttempts = defaultWebCredMaxAttempts
+		}
+		entry.Method = strings.ToUpper(entry.Method)
+		entry.Auth = strings.ToLower(entry.Auth)
+		WebCredentialDB = append(WebCredentialDB, entry)
+	}
+	return nil
+}
+
+// matchWebCredentials 返回与指纹相匹配的默认口令条目
+func matchWebCredentials(fingerprints []string) []WebCredential {
+	var result []WebCredential
+	for _, entry := range WebCredentialDB {
+	next:
+		for _, name := range entry.Fingerprints {
+			for _, fp := range fingerprints {
+				if strings.EqualFold(name, fp) {
+					result = append(result, entry)
+					break next
+				}
+			}
+		}
+	}
+	return result
+}
+
+type webCredTask struct {
+	BaseURL string
+	Entry   WebCredential
+}
+
+// DefaultCredentialScan 根据识别到的指纹对 Web 管理后台尝试默认口令
+func (s *FingerScanner) DefaultCredentialScan(ctrlCtx context.Context) {
+	var tasks []webCredTask
+	visited := make(map[string]bool)
+	s.mutex.RLock()
+	targets := make([]string, 0, len(s.basicURLWithFingerprint))
+	for target := range s.basicURLWithFingerprint {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		if !strings.HasPrefix(target, "http") {
+			continue
+		}
+		baseURL := httputil.GetBasicURL(target)
+		for _, entry := range matchWebCredentials(s.basicURLWithFingerprint[target]) {
+			key := baseURL + "|" + entry.Name
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+			tasks = append(tasks, webCredTask{BaseURL: baseURL, Entry: entry})
+		}
+	}
+	s.mutex.RUnlock()
+	if len(tasks) == 0 {
+		return
+	}
+	gologger.Info(s.ctx, fmt.Sprintf("Default credential check is running, targets number: %d", len(tasks)))
+
+	var wg sync.WaitGroup
+	threadPool, _ := ants.NewPoolWithFunc(s.thread, func(t interface{}) {
+		defer wg.Done()
+		s.tryWebCredential(ctrlCtx, t.(webCredTask))
+	})
+	defer threadPool.Release()
+	for _, task := range tasks {
+		if ctrlCtx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		threadPool.Invoke(task)
+	}
+	wg.Wait()
+	gologger.Info(s.ctx, "Default credential check finished")
+}
+
+func (s *FingerScanner) tryWebCredential(ctrlCtx context.Context, task webCredTask) {
+	entry := task.Entry
+	target := strings.TrimRight(task.BaseURL, "/") + entry.Path
+	// Basic 认证的页面未携带凭据时必须返回 401，否则说明页面无需认证，避免误报
+	if entry.Auth == "basic" {
+		resp, err := clients.DoRequest(entry.Method, target, s.headers, nil, 10, s.notFollowClient)
+		if err != nil || resp.StatusCode() != 401 {
+			return
+		}
+	}
+	for i, cred := range entry.Credentials {
+		if ctrlCtx.Err() != nil {
+			return
+		}
+		if i >= entry.MaxAttempts {
+			gologger.Debug(s.ctx, fmt.Sprintf("[default-login] %s %s reached max attempts %d", target, entry.Name, entry.MaxAttempts))
+			return
+		}
+		username, password, _ := strings.Cut(cred, ":")
+		headers := make(map[string]string)
+		for k, v := range s.headers {
+			headers[k] = v
+		}
+		for k, v := range entry.Headers {
+			headers[k] = v
+		}
+		var body string
+		switch entry.Auth {
+		case "basic":
+			headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+		case "form":
+			body = strings.NewReplacer("{{username}}", url.QueryEscape(username), "{{password}}", url.QueryEscape(password)).Replace(entry.Body)
+		case "json":
+			body = strings.NewReplacer("{{username}}", jsonEscape(username), "{{password}}", jsonEscape(password)).Replace(entry.Body)
+		}
+		var reader io.Reader
+		if body != "" {
+			reader = strings.NewReader(body)
+		}
+		resp, err := clients.DoRequest(entry.Method, target, headers, reader, 10, s.notFollowClient)
+		if err != nil || resp == nil || resp.RawResponse == nil {
+			gologger.Debug(s.ctx, fmt.Sprintf("[default-login] %s %v", target, err))
+			return
+		}
+		rawHeaders := strings.ToLower(string(httputil.DumpResponseHeadersOnly(resp.RawResponse)))
+		respBody := strings.ToLower(string(resp.Body()))
+		if containsAny(respBody, entry.Lockout) || containsAny(rawHeaders, entry.Lockout) {
+			gologger.Warning(s.ctx, fmt.Sprintf("[default-login] %s %s account lockout detected, stop trying", target, entry.Name))
+			return
+		}
+		if !entry.Success.match(resp.StatusCode(), rawHeaders, respBody) {
+			continue
+		}
+		gologger.Success(s.ctx, fmt.Sprintf("[default-login] %s %s %s/%s", target, entry.Name, username, password))
+		resultstore.Vulnerability(s.ctx, structs.VulnerabilityInfo{
+			TaskId:      s.taskId,
+			ID:          "default-login",
+			Name:        entry.Name + " 默认口令",
+			Description: "Web 管理后台存在默认口令, 该结果由默认口令库 " + entry.Name + " 条目命中",
+			Type:        "HTTP",
+			Severity:    "HIGH",
+			URL:         target,
+			Request:     buildWebCredRequest(entry.Method, target, headers, body),
+			Response:    httputil.LimitResponse(rawHeaders+string(resp.Body()), maxInfoReponseSize, ""),
+			Extract:     username + "/" + password,
+		})
+		return
+	}
+}
+
+func (m WebCredMatcher) match(status int, headers, body string) bool {
+	if len(m.Status) > 0 {
+		matched := false
+		for _, code := range m.Status {
+			if code == status {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for _, word := range m.Body {
+		if !strings.Contains(body, strings.ToLower(word)) {
+			return false
+		}
+	}
+	for _, word := range m.Header {
+		if !strings.Contains(headers, strings.ToLower(word)) {
+			return false
+		}
+	}
+	return !containsAny(body, m.NotBody) && !containsAny(headers, m.NotHeader)
+}
+
+func containsAny(s string, words []string) bool {
+	for _, word := range words {
+		if word != "" && strings.Contains(s, strings.ToLower(word)) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+func buildWebCredRequest(method, target string, headers map[string]string, body string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s HTTP/1.1\n", method, u.RequestURI()))
+	sb.WriteString(fmt.Sprintf("Host: %s\n", u.Host))
+	for k, v := range headers {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", k, v))
+	}
+	if body != "" {
+		sb.WriteString("\n" + body)
+	}
+	return sb.String()
+}
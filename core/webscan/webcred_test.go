@@ -0,0 +1,43 @@
+package webscan
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestInitWebCredentialDB(t *testing.T) {
+	config := &Config{}
+	file := filepath.Join(t.TempDir(), "webcred.yaml")
+	if err := config.InitWebCredentialDB(file); err != nil {
+		t.Fatalf("InitWebCredentialDB() returned an error: %v", err)
+	}
+	if len(WebCredentialDB) == 0 {
+		t.Fatal("InitWebCredentialDB() loaded no entries")
+	}
+	entries := matchWebCredentials([]string{"jenkins"})
+	if len(entries) != 1 || entries[0].Method != "POST" || entries[0].Auth != "form" {
+		t.Errorf("matchWebCredentials() = %+v", entries)
+	}
+	if !entries[0].Success.match(302, "location: http://127.0.0.1/\r\n", "") {
+		t.Error("Jenkins success matcher should match redirect to root")
+	}
+	if entries[0].Success.match(302, "location: http://127.0.0.1/loginerror\r\n", "") {
+		t.Error("Jenkins success matcher should not match loginError redirect")
+	}
+
+	// 登录失败页面同样会设置 zbx_session，只有跳转到仪表盘才算登录成功
+	zabbix := matchWebCredentials([]string{"Zabbix"})
+	if len(zabbix) != 1 {
+		t.Fatalf("matchWebCredentials(Zabbix) = %+v", zabbix)
+	}
+	failed := "set-cookie: zbx_session=eyJzZXNzaW9uaWQiOiIifQ%3D%3D; path=/; httponly\r\n"
+	if zabbix[0].Success.match(200, failed, "<div class=\"red\">incorrect user name or password or account is temporarily blocked.</div>") {
+		t.Error("Zabbix success matcher should not match the failed-login page")
+	}
+	if zabbix[0].Success.match(302, failed+"location: index.php\r\n", "") {
+		t.Error("Zabbix success matcher should not match a redirect back to the login page")
+	}
+	if !zabbix[0].Success.match(302, failed+"location: zabbix.php?action=dashboard.view\r\n", "") {
+		t.Error("Zabbix success matcher should match the redirect to the dashboard")
+	}
+}
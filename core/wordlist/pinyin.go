@@ -0,0 +1,119 @@
+package wordlist
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 常见姓氏、地名与企业名称用字的拼音，多音字取企业与人名中最常见的读音
+const pinyinData = `
+a:阿 ai:爱艾 an:安 ao:奥澳
+ba:八巴 bai:百白柏 ban:班 bang:邦 bao:宝保包鲍 bei:北贝备 ben:本 bi:必毕碧 bian:边卞 biao:标 bin:滨斌彬 bing:兵冰
+bo:博波渤 bu:步部
+cai:财蔡才材 can:灿 cang:仓沧 cao:曹草 ce:策 chan:产 chang:长常昌畅厂场 chao:超朝潮 che:车 chen:陈晨辰
+cheng:成城程诚承橙 chi:驰 chong:重崇 chu:储出楚初 chuan:川传船 chuang:创 chun:春纯 ci:慈 cong:聪 cui:崔
+da:大达 dai:代戴 dan:丹单 dang:党 dao:道导岛 de:德 deng:邓登 di:地迪帝第 dian:电典点 ding:丁鼎定 dong:东董动冬 dou:豆
+du:杜都度 duan:段端 dun:盾敦 duo:多
+e:鄂 er:尔
+fa:发法 fan:范凡帆繁 fang:方房芳 fei:飞费肥 feng:丰风峰冯锋凤 fo:佛 fu:福富付傅府服复阜
+gan:甘 gang:港钢刚 gao:高 ge:格葛歌 gong:工公共宫供龚 gu:古谷顾股固 guan:管关冠观莞 guang:光广 gui:贵桂 guo:国郭果
+ha:哈 hai:海 han:韩汉涵翰 hang:航行杭 hao:浩豪好昊郝 he:何和合河贺荷 hei:黑 heng:恒衡 hong:红洪宏鸿弘 hou:侯后厚
+hu:胡湖互虎沪呼 hua:华化花 huai:淮 huan:环欢 huang:黄皇 hui:辉惠汇慧会徽 huo:火霍
+ji:吉集基机技纪济计季冀 jia:家佳嘉加贾 jian:建健剑坚简件 jiang:江姜蒋疆将 jiao:交教焦 jie:杰捷洁节 jin:金锦进晋津 jing:京精晶景经静敬井婧
+jiu:九久 ju:聚巨据局 juan:娟 jun:军君俊峻
+kai:开凯 kang:康 ke:科可克柯 kong:孔空控 kou:口 kuang:矿 kun:坤昆
+la:拉 lan:兰蓝 lang:朗 lao:劳 le:乐 lei:雷磊蕾 li:李利力立理丽黎礼莉里 lian:联连莲 liang:梁良亮粮 liao:廖辽疗料 lin:林临琳
+ling:凌灵领玲 liu:刘流六柳 long:龙隆 lu:陆路鲁卢录璐 luo:罗洛络 lv:吕旅律绿
+ma:马玛 mao:毛茂贸 mei:美梅媒 men:门 meng:孟蒙梦 mi:米 min:民敏闽 ming:明名铭 mo:莫墨 mu:木牧穆
+na:纳娜 nan:南 nei:内 neng:能 ni:尼倪 ning:宁 niu:牛 nong:农
+ou:欧
+pan:潘攀盘 pei:裴培 peng:彭鹏 pin:品 ping:平萍 pu:普浦蒲
+qi:齐奇企启琪其汽器气 qian:钱千乾前倩 qiang:强 qiao:乔桥 qin:秦琴勤覃 qing:青清庆晴 qiu:邱秋 qu:区曲 quan:全泉权券
+ran:燃 re:热 ren:人任仁 ri:日 rong:荣融容蓉 ruan:软 rui:瑞锐睿 run:润
+sa:萨 sai:赛 san:三 sen:森 sha:沙 shan:山善陕杉 shang:上商尚 shao:邵绍 she:社设 shen:深申沈神 sheng:生盛胜省圣
+shi:石史时世市施实师食诗 shou:寿首 shu:书舒数术 shuai:帅 shuang:双 shui:水 shun:顺 si:思司斯四 song:宋松 su:苏素肃
+sun:孙
+tai:泰太台 tan:谭坦 tang:唐汤 tao:陶涛 te:特 teng:腾滕 tian:天田 tie:铁 ting:婷厅 tong:通同童统 tou:投 tu:图
+tuan:团
+wan:万湾 wang:王网旺汪 wei:伟威卫维魏微韦委薇 wen:文温 wu:吴武五物无务乌
+xi:西喜熙夕息系锡 xia:夏霞厦 xian:先鲜县贤险 xiang:向湘香祥翔项 xiao:小晓萧肖 xie:谢协械 xin:新信鑫心欣 xing:兴星邢型 xiong:熊雄
+xiu:秀 xu:许徐旭 xuan:宣轩 xue:学薛雪 xun:迅讯询
+ya:亚雅 yan:严颜燕研言岩盐闫艳 yang:杨阳洋扬央 yao:姚耀药 ye:叶业 yi:一易医益亿义宜艺逸仪移怡毅 yin:银印尹音 ying:英应营影盈赢鹰颖
+yong:永勇 you:有友优游油尤邮 yu:于余宇玉渝鱼雨裕豫禹育 yuan:元园源远袁院圆苑 yue:越月岳悦 yun:云运韵
+zang:藏 zao:造 ze:泽 zeng:曾 zhan:展湛詹 zhang:张章彰 zhao:赵招昭兆 zhe:浙哲 zhen:振真珍镇圳 zheng:郑正政证征
+zhi:智志之至致芝治制置 zhong:中钟众忠仲 zhou:周州舟 zhu:朱祝珠竹筑 zhuang:庄装 zhuo:卓 zi:资紫子自咨梓 zong:宗总 zou:邹 zu:祖
+zuo:左
+`
+
+var pinyinTable = func() map[rune]string {
+	table := make(map[rune]string)
+	for _, group := range strings.Fields(pinyinData) {
+		syllable, chars, _ := strings.Cut(group, ":")
+		for _, r := range chars {
+			table[r] = syllable
+		}
+	}
+	return table
+}()
+
+// GB2312 一级汉字按拼音排序，各声母首个汉字的区位码
+var gb2312Initials = []struct {
+	code    int
+	initial byte
+}{
+	{0xB0A1, 'a'}, {0xB0C5, 'b'}, {0xB2C1, 'c'}, {0xB4EE, 'd'}, {0xB6EA, 'e'}, {0xB7A2, 'f'},
+	{0xB8C1, 'g'}, {0xB9FE, 'h'}, {0xBBF7, 'j'}, {0xBFA6, 'k'}, {0xC0AC, 'l'}, {0xC2E8, 'm'},
+	{0xC4C3, 'n'}, {0xC5B6, 'o'}, {0xC5BE, 'p'}, {0xC6DA, 'q'}, {0xC8BB, 'r'}, {0xC8F6, 's'},
+	{0xCBFA, 't'}, {0xCDDA, 'w'}, {0xCEF4, 'x'}, {0xD1B9, 'y'}, {0xD4D1, 'z'},
+}
+
+const gb2312Level1End = 0xD7F9
+
+// initialOf 返回汉字拼音首字母，优先使用拼音表，其次根据 GB2312 一级汉字的排序推算
+func initialOf(r rune) (byte, bool) {
+	if syllable, ok := pinyinTable[r]; ok {
+		return syllable[0], true
+	}
+	encoded, err := simplifiedchinese.GBK.NewEncoder().String(string(r))
+	if err != nil || len(encoded) != 2 {
+		return 0, false
+	}
+	code := int(encoded[0])<<8 | int(encoded[1])
+	if code < gb2312Initials[0].code || code > gb2312Level1End {
+		return 0, false
+	}
+	i := sort.Search(len(gb2312Initials), func(i int) bool { return gb2312Initials[i].code > code })
+	return gb2312Initials[i-1].initial, true
+}
+
+// Pinyin 将中文转换为全拼与首字母，字母数字原样保留，其余字符忽略
+// 存在拼音表之外的汉字时 full 为空，首字母仍尽量给出
+func Pinyin(s string) (full, initials string) {
+	var (
+		fb, ib   strings.Builder
+		complete = true
+	)
+	for _, r := range s {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			fb.WriteRune(unicode.ToLower(r))
+			ib.WriteRune(unicode.ToLower(r))
+		case unicode.Is(unicode.Han, r):
+			if syllable, ok := pinyinTable[r]; ok {
+				fb.WriteString(syllable)
+			} else {
+				complete = false
+			}
+			if initial, ok := initialOf(r); ok {
+				ib.WriteByte(initial)
+			}
+		}
+	}
+	if complete {
+		full = fb.String()
+	}
+	return full, ib.String()
+}
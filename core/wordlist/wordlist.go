@@ -0,0 +1,300 @@
+package wordlist
+
+import (
+	"fmt"
+	"slack-wails/lib/structs"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const defaultLimit = 10000
+
+var (
+	// 公司名称中需要剥离的组织形式后缀
+	companySuffixes = []string{
+		"股份有限公司", "有限责任公司", "集团有限公司", "有限公司", "分公司", "子公司", "集团", "公司",
+		"研究院", "研究所", "委员会", "大学", "学院", "医院", "银行", "中心",
+	}
+	// 公司名称开头常见的行政区划
+	regionPrefixes = []string{
+		"北京", "上海", "天津", "重庆", "河北", "山西", "辽宁", "吉林", "黑龙江", "江苏", "浙江", "安徽", "福建",
+		"江西", "山东", "河南", "湖北", "湖南", "广东", "海南", "四川", "贵州", "云南", "陕西", "甘肃", "青海",
+		"台湾", "内蒙古", "广西", "西藏", "宁夏", "新疆", "香港", "澳门", "深圳", "广州", "杭州", "南京", "武汉",
+		"成都", "西安", "苏州", "长沙", "郑州", "青岛", "厦门", "宁波", "合肥", "济南", "沈阳", "大连", "福州",
+		"中国",
+	}
+	// 注册后缀，提取域名主体时跳过
+	domainSuffixes = map[string]bool{
+		"com": true, "cn": true, "net": true, "org": true, "gov": true, "edu": true, "co": true, "io": true,
+		"info": true, "biz": true, "top": true, "xyz": true, "cc": true, "hk": true, "tw": true, "ac": true,
+	}
+	// 高频弱口令后缀，{year} 与 {yy} 会被替换为年份
+	passwordSuffixes = []string{
+		"@{year}", "{year}", "#{year}", "@123", "123", "@123456", "123456", "@1234", "1234", "12345",
+		"@{yy}", "{yy}", "!@#", "123!@#", "@123.com", "_{year}", "!{year}", "{year}!", "@{year}!",
+		"888", "666", "000", "520", "@",
+	}
+	keyboardWalks = []string{
+		"1qaz2wsx", "1qaz@WSX", "1qaz!QAZ", "!QAZ2wsx", "1QAZ2wsx", "qazwsx", "qazwsxedc", "zaq12wsx",
+		"qwe123", "qwe!@#", "qwer1234", "Qwer1234", "qwer!@#$", "qweasd", "qweasdzxc", "qweqwe",
+		"1q2w3e", "1q2w3e4r", "1q2w3e4r5t", "q1w2e3r4", "asd123", "asdf1234", "asdfgh", "zxcvbn",
+		"zxcvbnm", "qwertyuiop", "1234qwer", "123qwe", "123qweasd", "123qwe!@#", "Aa123456", "Qq123456",
+	}
+	// 结合用户名生成的口令，爆破模块会将 {user} 替换为当前用户名
+	userPatterns = []string{"{user}", "{user}123", "{user}@123", "{user}@{year}", "{user}{year}", "{user}123456"}
+)
+
+// Generate 根据目标相关的公司、域名、姓名等信息生成候选口令，按可能性由高到低排列
+func Generate(options structs.PasswordDictOptions) []string {
+	limit := options.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	years := yearRange(options.StartYear, options.EndYear)
+
+	var bases []string
+	for _, company := range options.Companies {
+		names, domains := CompanyKeywords(company)
+		bases = append(bases, companyBases(names)...)
+		bases = append(bases, domainBases(domains)...)
+	}
+	bases = append(bases, companyBases(options.Keywords)...)
+	bases = append(bases, domainBases(options.Domains)...)
+	for _, name := range options.Names {
+		bases = append(bases, personBases(name)...)
+	}
+	bases = unique(bases)
+
+	result := newOrderedSet(limit)
+	for _, pattern := range userPatterns {
+		for _, p := range expandYears(pattern, years) {
+			result.add(p)
+		}
+	}
+	if options.KeyboardWalk {
+		for _, walk := range keyboardWalks {
+			result.add(walk)
+		}
+	}
+	// 先按后缀优先级遍历全部关键词，保证截断时每个关键词的高频组合都能保留
+	for _, suffix := range passwordSuffixes {
+		for _, base := range bases {
+			for _, word := range caseVariants(base) {
+				for _, s := range expandYears(suffix, years) {
+					result.add(word + s)
+				}
+			}
+		}
+	}
+	for _, base := range bases {
+		for _, word := range caseVariants(base) {
+			result.add(word)
+		}
+	}
+	return result.items
+}
+
+// CompanyKeywords 递归提取公司名称、商标以及备案域名
+func CompanyKeywords(company structs.CompanyInfo) (names, domains []string) {
+	if company.CompanyName != "" {
+		names = append(names, company.CompanyName)
+	}
+	if company.Trademark != "" {
+		names = append(names, company.Trademark)
+	}
+	domains = append(domains, company.Domains...)
+	for _, sub := range company.Subsidiaries {
+		subNames, subDomains := CompanyKeywords(sub)
+		names = append(names, subNames...)
+		domains = append(domains, subDomains...)
+	}
+	return
+}
+
+// companyBases 生成公司全称、去除地区与组织形式后的简称的全拼与首字母
+func companyBases(names []string) []string {
+	var bases []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		short := trimCompanyName(name)
+		for _, n := range []string{short, name} {
+			full, initials := Pinyin(n)
+			if len(initials) >= 2 {
+				bases = append(bases, initials)
+			}
+			if full != "" && full != initials {
+				bases = append(bases, full)
+			}
+		}
+	}
+	return bases
+}
+
+func trimCompanyName(name string) string {
+	// 去掉括号内的地区说明，如 （中国）、(北京)
+	for _, pair := range [][2]string{{"（", "）"}, {"(", ")"}} {
+		if start := strings.Index(name, pair[0]); start != -1 {
+			if end := strings.Index(name[start:], pair[1]); end != -1 {
+				name = name[:start] + name[start+end+len(pair[1]):]
+			}
+		}
+	}
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, suffix := range companySuffixes {
+			if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+				name = strings.TrimSuffix(name, suffix)
+				trimmed = true
+			}
+		}
+	}
+	for _, prefix := range regionPrefixes {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			name = strings.TrimPrefix(name, prefix)
+			name = strings.TrimPrefix(name, "省")
+			name = strings.TrimPrefix(name, "市")
+			break
+		}
+	}
+	return name
+}
+
+// domainBases 提取域名主体，如 www.example.com.cn 取 example
+func domainBases(domains []string) []string {
+	var bases []string
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if i := strings.Index(domain, "://"); i != -1 {
+			domain = domain[i+3:]
+		}
+		domain = strings.Split(domain, "/")[0]
+		domain = strings.Split(domain, ":")[0]
+		labels := strings.Split(domain, ".")
+		for i := len(labels) - 1; i >= 0; i-- {
+			if labels[i] != "" && !domainSuffixes[labels[i]] {
+				if labels[i] != "www" {
+					bases = append(bases, labels[i])
+				}
+				break
+			}
+		}
+	}
+	return bases
+}
+
+// personBases 生成姓名的常见组合，如 张伟 -> zhangwei、zw、wzhang、zhangw
+func personBases(name string) []string {
+	name = strings.TrimSpace(name)
+	runes := []rune(name)
+	if len(runes) == 0 {
+		return nil
+	}
+	if !unicode.Is(unicode.Han, runes[0]) {
+		return []string{strings.ToLower(name)}
+	}
+	surname, given := string(runes[:1]), string(runes[1:])
+	// 复姓
+	if len(runes) >= 3 {
+		if _, ok := compoundSurnames[string(runes[:2])]; ok {
+			surname, given = string(runes[:2]), string(runes[2:])
+		}
+	}
+	sFull, sInitials := Pinyin(surname)
+	gFull, gInitials := Pinyin(given)
+	// 生僻字没有拼音时首字母可能为空
+	var bases []string
+	if initials := sInitials + gInitials; initials != "" {
+		bases = append(bases, initials)
+	}
+	if sFull != "" && gFull != "" {
+		bases = append(bases, sFull+gFull, gFull+sFull, sFull+gInitials, gInitials+sFull)
+	}
+	return bases
+}
+
+var compoundSurnames = map[string]struct{}{
+	"欧阳": {}, "司马": {}, "上官": {}, "诸葛": {}, "东方": {}, "皇甫": {}, "令狐": {}, "慕容": {}, "司徒": {}, "夏侯": {},
+}
+
+func caseVariants(word string) []string {
+	if word == "" {
+		return nil
+	}
+	variants := []string{word}
+	// 按字符而不是字节处理首字母，避免截断多字节字符
+	first, size := utf8.DecodeRuneInString(word)
+	if capitalized := string(unicode.ToUpper(first)) + word[size:]; capitalized != word {
+		variants = append(variants, capitalized)
+	}
+	// 首字母缩写通常较短，全大写也较为常见
+	if utf8.RuneCountInString(word) <= 6 {
+		if upper := strings.ToUpper(word); upper != word {
+			variants = append(variants, upper)
+		}
+	}
+	return variants
+}
+
+func yearRange(start, end int) []int {
+	now := time.Now().Year()
+	if end <= 0 {
+		end = now
+	}
+	if start <= 0 || start > end {
+		start = end - 3
+	}
+	var years []int
+	// 近年优先
+	for y := end; y >= start; y-- {
+		years = append(years, y)
+	}
+	return years
+}
+
+func expandYears(pattern string, years []int) []string {
+	if !strings.Contains(pattern, "{year}") && !strings.Contains(pattern, "{yy}") {
+		return []string{pattern}
+	}
+	var result []string
+	for _, y := range years {
+		p := strings.ReplaceAll(pattern, "{year}", fmt.Sprint(y))
+		p = strings.ReplaceAll(p, "{yy}", fmt.Sprintf("%02d", y%100))
+		result = append(result, p)
+	}
+	return result
+}
+
+func unique(items []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, item := range items {
+		if item != "" && !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+type orderedSet struct {
+	limit int
+	seen  map[string]bool
+	items []string
+}
+
+func newOrderedSet(limit int) *orderedSet {
+	return &orderedSet{limit: limit, seen: make(map[string]bool)}
+}
+
+func (s *orderedSet) add(item string) {
+	if len(s.items) >= s.limit || s.seen[item] {
+		return
+	}
+	s.seen[item] = true
+	s.items = append(s.items, item)
+}
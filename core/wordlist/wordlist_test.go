@@ -0,0 +1,64 @@
+package wordlist
+
+import (
+	"slack-wails/lib/structs"
+	"slices"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPinyin(t *testing.T) {
+	cases := []struct {
+		input, full, initials string
+	}{
+		{"阿里巴巴", "alibaba", "albb"},
+		{"蚂蚁", "", "my"},
+		{"中国银行", "zhongguoyinhang", "zgyh"},
+		{"ICBC工商", "icbcgongshang", "icbcgs"},
+	}
+	for _, c := range cases {
+		full, initials := Pinyin(c.input)
+		if full != c.full || initials != c.initials {
+			t.Errorf("Pinyin(%s) = %s, %s, want %s, %s", c.input, full, initials, c.full, c.initials)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	passwords := Generate(structs.PasswordDictOptions{
+		Companies: []structs.CompanyInfo{{CompanyName: "北京百度网讯科技有限公司", Domains: []string{"baidu.com"}}},
+		Names:     []string{"张伟"},
+		StartYear: 2023,
+		EndYear:   2024,
+	})
+	for _, want := range []string{"bdwxkj@2024", "Baidu@123", "baidu#2023", "zhangwei@123", "BDWXKJ", "{user}@2024"} {
+		if !slices.Contains(passwords, want) {
+			t.Errorf("Generate() missing %s", want)
+		}
+	}
+}
+
+func TestPersonBasesCase(t *testing.T) {
+	// 没有拼音的生僻字不能生成空的关键词
+	for _, base := range personBases("䶮") {
+		if base == "" {
+			t.Fatal("personBases() returned an empty base")
+		}
+	}
+	if got := caseVariants(""); len(got) != 0 {
+		t.Errorf("caseVariants(\"\") = %v", got)
+	}
+	// 非汉字姓名首字母按字符大写
+	if got := caseVariants("élodie"); !slices.Equal(got, []string{"élodie", "Élodie", "ÉLODIE"}) {
+		t.Errorf("caseVariants(élodie) = %q", got)
+	}
+	passwords := Generate(structs.PasswordDictOptions{Names: []string{"䶮", "Élodie"}, StartYear: 2024, EndYear: 2024})
+	if !slices.Contains(passwords, "Élodie@2024") {
+		t.Errorf("Generate() missing Élodie@2024")
+	}
+	for _, password := range passwords {
+		if !utf8.ValidString(password) {
+			t.Fatalf("Generate() returned an invalid string %q", password)
+		}
+	}
+}
@@ -1,9 +1,10 @@
 import { CheckFileStat, InitConfig, ReadFile, RemoveOldConfig, SaveDataToFile, ReadLocalStore } from 'wailsjs/go/services/File';
 import { ElLoading, ElNotification } from 'element-plus';
 import global from "./stores";
-import { check, compareVersion, sleep } from './util';
+import { check, compareVersion, sleep, ResetAssetServer } from './util';
 import router from "./router";
 import { CreateTable } from 'wailsjs/go/services/Database';
+import { ConfigureAssetServer, ConfigureBrowser } from 'wailsjs/go/services/App';
 
 function catchError(result: boolean, loading: any) {
     if (result) {
@@ -69,6 +70,8 @@ async function LoadConfig() {
             SaveConfig()
         }
     }
+    ConfigureBrowser(global.webscan.browser_tabs, global.webscan.browser_wait_selector)
+    ConfigureAssetServer(global.webscan.asset_port)
     // 检测更新
     check.client();
     check.poc();
@@ -80,6 +83,8 @@ export function SaveConfig() {
     // 去除不可见字符
     list = list.map(item => item.replace(/[\r\n\s]/g, ''));
     var data = { proxy: global.proxy, space: global.space, jsfinder: global.jsfinder, webscan: global.webscan, database: global.database, fileRetrieval: global.fileRetrieval, update: global.update };
+    ConfigureBrowser(global.webscan.browser_tabs, global.webscan.browser_wait_selector)
+    ConfigureAssetServer(global.webscan.asset_port).then(ResetAssetServer)
     SaveDataToFile(data).then(result => {
         if (result) {
             ElNotification.success({
@@ -37,6 +37,7 @@ export default {
         'poc_manage': 'Poc Management',
         'script': 'Script',
         'extract_database_info': 'Extract Database Info',
+        'ad_recon': 'AD Recon',
         'fscan': 'Fscan',
         'timestamp': 'Timestamp',
         'dumpall': 'Dumpall',
@@ -76,6 +77,10 @@ export default {
         'portscan_thread': 'Portscan Thread',
         'portscan_timeout': 'Portscan Timeout(s)',
         'survival': 'Survival verification',
+        'browser_tabs': 'Browser Tabs',
+        'browser_wait_selector': 'Wait Selector',
+        'browser_wait_selector_tips': 'Wait for this selector before screenshot, wait for network idle if empty',
+        'asset_port': 'Local Asset Server Port',
         'slogan': 'An integrated security and service tool platform',
         'source_code': 'Source Code',
         'update_log': 'Update Log',
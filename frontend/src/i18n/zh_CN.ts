@@ -37,6 +37,7 @@ export default {
         'poc_manage': 'POC和指纹管理',
         'script': '脚本管理',
         'extract_database_info': '数据库自动取样',
+        'ad_recon': '域信息收集',
         'fscan': 'Fscan专区',
         'timestamp': '时间戳转换',
         'dumpall': 'Dumpall',
@@ -81,6 +82,10 @@ export default {
         'portscan_thread': '端口扫描线程',
         'portscan_timeout': '端口指纹超时(s)',
         'survival': '存活验证模式',
+        'browser_tabs': '浏览器标签页上限',
+        'browser_wait_selector': '页面等待选择器',
+        'browser_wait_selector_tips': '截图前等待该选择器出现, 为空时等待网络空闲',
+        'asset_port': '本地资源服务端口',
         'slogan': '安服集成化工具平台，希望能让你少开几个应用测试',
         'source_code': '源码地址',
         'update_log': '更新日志',
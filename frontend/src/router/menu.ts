@@ -134,6 +134,11 @@ const MenuList = [
                 path: "/ExtractDbInfo",
                 icon: "/app/database.png"
             },
+            {
+                name: "aside.ad_recon",
+                path: "/ADRecon",
+                icon: "/app/domain.png"
+            },
             {
                 name: "aside.fileinfo",
                 path: "/FileContentRetrieval",
@@ -33,6 +33,9 @@ var webscan = reactive({
     ping_check_alive: false,
     default_alive_module: "None",
     default_network: "Auto",
+    browser_tabs: 4, // 截图与动态分析共用浏览器的标签页上限
+    browser_wait_selector: "", // 页面加载后等待的选择器，为空时等待网络空闲
+    asset_port: 8732, // 本地资源服务端口，仅监听 127.0.0.1
     highlight_fingerprints: [
         "泛微-协同办公OA",
         "致远互联-OA",
@@ -85,6 +88,9 @@ var temp = reactive({
     isMax: false,
     isGrid: true,
     goos: '',
+    companies: <structs.CompanyInfo[]>[], // 最近一次企业信息收集结果，用于生成口令字典与虚拟主机候选
+    subdomains: <string[]>[], // 最近一次子域名收集结果，用于虚拟主机候选
+    assetServer: <structs.AssetServer>{ URL: '', Token: '', Roots: {} }, // 本地资源服务地址与访问令牌
 })
 
 const Logger = reactive({
@@ -471,6 +471,8 @@ export var crackConfig = [
     { name: "ActiveMQ", dicPath: "/username/activemq.txt" },
     { name: "Rsync", dicPath: "/username/rsync.txt" },
     { name: "Kafka", dicPath: "/username/kafka.txt" },
+    { name: "HTTP", dicPath: "/username/http.txt" },
+    { name: "HTTPS", dicPath: "/username/http.txt" },
 ];
 
 export var crackDict = {
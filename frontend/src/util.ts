@@ -1,6 +1,6 @@
 import { ElMessage, ElNotification } from "element-plus";
 import global from "./stores";
-import { Callgologger, GoFetch, NetDial, Socks5Conn } from "wailsjs/go/services/App";
+import { AssetServer, Callgologger, GoFetch, NetDial, Socks5Conn } from "wailsjs/go/services/App";
 import { CheckFileStat, FileDialog, ReadFile, RemoveOldClient } from "wailsjs/go/services/File";
 import { Loading } from '@element-plus/icons-vue';
 import { BrowserOpenURL, ClipboardSetText } from "wailsjs/runtime/runtime";
@@ -9,6 +9,56 @@ import { reactive } from "vue";
 
 export var proxys: ""; // wails2.9之后替换原来的null
 
+// 本地资源服务在首次访问截图等文件时启动，启动失败只提示一次，修改端口后可重新启动
+let assetServerPending: Promise<void> | undefined
+
+export function LoadAssetServer(): Promise<void> {
+    if (!assetServerPending) {
+        assetServerPending = AssetServer().then(server => {
+            global.temp.assetServer = server
+        }).catch(err => {
+            ElNotification.error({
+                title: "本地资源服务启动失败",
+                message: String(err),
+                position: "bottom-right",
+            });
+        })
+    }
+    return assetServerPending
+}
+
+export function ResetAssetServer() {
+    assetServerPending = undefined
+    global.temp.assetServer = { URL: '', Token: '', Roots: {} }
+}
+
+// 本地资源服务中的文件链接，root 为 screenshot、sourcemap、report
+// 位于挂载目录下的文件按相对路径访问，其余只取文件名
+export function assetURL(root: string, filepath: string): string {
+    if (filepath == '') return ''
+    const { URL, Token } = global.temp.assetServer
+    if (URL == '') {
+        LoadAssetServer()
+        return ''
+    }
+    const segments = assetRelativePath(root, filepath) ?? [filepath.split(/[/\\]/).pop()!] // 适配 Windows 和 Linux 路径
+    return `${URL}/${root}/${segments.map(encodeURIComponent).join('/')}?token=${Token}`;
+}
+
+// 判断文件是否位于本地资源服务的挂载目录下
+export function isAssetPath(root: string, filepath: string): boolean {
+    return assetRelativePath(root, filepath) !== undefined
+}
+
+function assetRelativePath(root: string, filepath: string): string[] | undefined {
+    const dir = global.temp.assetServer.Roots?.[root]
+    if (!dir) return undefined
+    const base = dir.split(/[/\\]/).filter(Boolean)
+    const parts = filepath.split(/[/\\]/).filter(Boolean)
+    if (parts.length <= base.length || base.some((s, i) => s != parts[i])) return undefined
+    return parts.slice(base.length)
+}
+
 // 惰性函数，如果支持navigator.clipboard就用它，不支持就改成另一个
 let copyText: (content: string) => void;
 
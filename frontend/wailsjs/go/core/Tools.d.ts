@@ -2,6 +2,8 @@
 // This file is automatically generated. DO NOT EDIT
 import {structs} from '../models';
 
+export function ActionProfiles(arg1:string):Promise<Array<string>>;
+
 export function AntivirusIdentify(arg1:string):Promise<Array<structs.AntivirusResult>>;
 
 export function ConnectAndExecute(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string):Promise<string>;
@@ -27,3 +29,5 @@ export function IsRoot():Promise<boolean>;
 export function PatchIdentify(arg1:string):Promise<Array<structs.AuthPatch>>;
 
 export function PortParse(arg1:string):Promise<Array<number>>;
+
+export function RunActionProfile(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<Array<structs.ActionResult>>;
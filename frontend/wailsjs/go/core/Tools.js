@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ActionProfiles(arg1) {
+  return window['go']['core']['Tools']['ActionProfiles'](arg1);
+}
+
 export function AntivirusIdentify(arg1) {
   return window['go']['core']['Tools']['AntivirusIdentify'](arg1);
 }
@@ -53,3 +57,7 @@ export function PatchIdentify(arg1) {
 export function PortParse(arg1) {
   return window['go']['core']['Tools']['PortParse'](arg1);
 }
+
+export function RunActionProfile(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['core']['Tools']['RunActionProfile'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
@@ -14,6 +14,7 @@ export namespace dirsearch {
 	    CustomHeader: string;
 	    Recursion: number;
 	    Backupscan: boolean;
+	    Crawled: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new Options(source);
@@ -34,6 +35,7 @@ export namespace dirsearch {
 	        this.CustomHeader = source["CustomHeader"];
 	        this.Recursion = source["Recursion"];
 	        this.Backupscan = source["Backupscan"];
+	        this.Crawled = source["Crawled"];
 	    }
 	}
 
@@ -184,6 +186,338 @@ export namespace space {
 
 export namespace structs {
 	
+	export class ADComputer {
+	    Name: string;
+	    DNSHostName: string;
+	    DistinguishedName: string;
+	    SID: string;
+	    PrimaryGroupSID: string;
+	    OperatingSystem: string;
+	    OperatingSystemVersion: string;
+	    Enabled: boolean;
+	    DomainController: boolean;
+	    UnconstrainedDelegation: boolean;
+	    TrustedToAuth: boolean;
+	    AllowedToDelegate: string[];
+	    AllowedToAct: string[];
+	    SPNs: string[];
+	    LastLogon: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ADComputer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.DNSHostName = source["DNSHostName"];
+	        this.DistinguishedName = source["DistinguishedName"];
+	        this.SID = source["SID"];
+	        this.PrimaryGroupSID = source["PrimaryGroupSID"];
+	        this.OperatingSystem = source["OperatingSystem"];
+	        this.OperatingSystemVersion = source["OperatingSystemVersion"];
+	        this.Enabled = source["Enabled"];
+	        this.DomainController = source["DomainController"];
+	        this.UnconstrainedDelegation = source["UnconstrainedDelegation"];
+	        this.TrustedToAuth = source["TrustedToAuth"];
+	        this.AllowedToDelegate = source["AllowedToDelegate"];
+	        this.AllowedToAct = source["AllowedToAct"];
+	        this.SPNs = source["SPNs"];
+	        this.LastLogon = source["LastLogon"];
+	    }
+	}
+	export class ADDomain {
+	    Name: string;
+	    DistinguishedName: string;
+	    SID: string;
+	    DomainController: string;
+	    FunctionalLevel: string;
+	    MachineAccountQuota: number;
+	    PasswordPolicy: ADPasswordPolicy;
+	    GPOLinks: ADGPOLink[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ADDomain(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.DistinguishedName = source["DistinguishedName"];
+	        this.SID = source["SID"];
+	        this.DomainController = source["DomainController"];
+	        this.FunctionalLevel = source["FunctionalLevel"];
+	        this.MachineAccountQuota = source["MachineAccountQuota"];
+	        this.PasswordPolicy = this.convertValues(source["PasswordPolicy"], ADPasswordPolicy);
+	        this.GPOLinks = this.convertValues(source["GPOLinks"], ADGPOLink);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ADGPO {
+	    Name: string;
+	    GUID: string;
+	    ObjectGUID: string;
+	    DistinguishedName: string;
+	    Path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ADGPO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.GUID = source["GUID"];
+	        this.ObjectGUID = source["ObjectGUID"];
+	        this.DistinguishedName = source["DistinguishedName"];
+	        this.Path = source["Path"];
+	    }
+	}
+	export class ADGPOLink {
+	    Name: string;
+	    DistinguishedName: string;
+	    Enforced: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ADGPOLink(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.DistinguishedName = source["DistinguishedName"];
+	        this.Enforced = source["Enforced"];
+	    }
+	}
+	export class ADGroup {
+	    Name: string;
+	    DistinguishedName: string;
+	    SID: string;
+	    Description: string;
+	    AdminCount: boolean;
+	    Members: ADMember[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ADGroup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.DistinguishedName = source["DistinguishedName"];
+	        this.SID = source["SID"];
+	        this.Description = source["Description"];
+	        this.AdminCount = source["AdminCount"];
+	        this.Members = this.convertValues(source["Members"], ADMember);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ADMember {
+	    Name: string;
+	    SID: string;
+	    Type: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ADMember(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.SID = source["SID"];
+	        this.Type = source["Type"];
+	    }
+	}
+	export class ADPasswordPolicy {
+	    MinLength: number;
+	    HistoryLength: number;
+	    MaxAge: string;
+	    MinAge: string;
+	    LockoutThreshold: number;
+	    LockoutDuration: string;
+	    Complexity: boolean;
+	    ReversibleEncryption: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ADPasswordPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.MinLength = source["MinLength"];
+	        this.HistoryLength = source["HistoryLength"];
+	        this.MaxAge = source["MaxAge"];
+	        this.MinAge = source["MinAge"];
+	        this.LockoutThreshold = source["LockoutThreshold"];
+	        this.LockoutDuration = source["LockoutDuration"];
+	        this.Complexity = source["Complexity"];
+	        this.ReversibleEncryption = source["ReversibleEncryption"];
+	    }
+	}
+	export class ADReconResult {
+	    Domain: ADDomain;
+	    Users: ADUser[];
+	    Groups: ADGroup[];
+	    Computers: ADComputer[];
+	    GPOs: ADGPO[];
+	    Trusts: ADTrust[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ADReconResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Domain = this.convertValues(source["Domain"], ADDomain);
+	        this.Users = this.convertValues(source["Users"], ADUser);
+	        this.Groups = this.convertValues(source["Groups"], ADGroup);
+	        this.Computers = this.convertValues(source["Computers"], ADComputer);
+	        this.GPOs = this.convertValues(source["GPOs"], ADGPO);
+	        this.Trusts = this.convertValues(source["Trusts"], ADTrust);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ADTrust {
+	    Partner: string;
+	    SID: string;
+	    Direction: string;
+	    Type: string;
+	    Transitive: boolean;
+	    SIDFiltering: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ADTrust(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Partner = source["Partner"];
+	        this.SID = source["SID"];
+	        this.Direction = source["Direction"];
+	        this.Type = source["Type"];
+	        this.Transitive = source["Transitive"];
+	        this.SIDFiltering = source["SIDFiltering"];
+	    }
+	}
+	export class ADUser {
+	    Name: string;
+	    DisplayName: string;
+	    DistinguishedName: string;
+	    SID: string;
+	    PrimaryGroupSID: string;
+	    Description: string;
+	    Enabled: boolean;
+	    PasswordNeverExpires: boolean;
+	    PasswordNotRequired: boolean;
+	    DontRequirePreauth: boolean;
+	    AdminCount: boolean;
+	    Sensitive: boolean;
+	    UnconstrainedDelegation: boolean;
+	    TrustedToAuth: boolean;
+	    AllowedToDelegate: string[];
+	    SPNs: string[];
+	    SIDHistory: string[];
+	    MemberOf: string[];
+	    PasswordLastSet: string;
+	    LastLogon: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ADUser(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.DisplayName = source["DisplayName"];
+	        this.DistinguishedName = source["DistinguishedName"];
+	        this.SID = source["SID"];
+	        this.PrimaryGroupSID = source["PrimaryGroupSID"];
+	        this.Description = source["Description"];
+	        this.Enabled = source["Enabled"];
+	        this.PasswordNeverExpires = source["PasswordNeverExpires"];
+	        this.PasswordNotRequired = source["PasswordNotRequired"];
+	        this.DontRequirePreauth = source["DontRequirePreauth"];
+	        this.AdminCount = source["AdminCount"];
+	        this.Sensitive = source["Sensitive"];
+	        this.UnconstrainedDelegation = source["UnconstrainedDelegation"];
+	        this.TrustedToAuth = source["TrustedToAuth"];
+	        this.AllowedToDelegate = source["AllowedToDelegate"];
+	        this.SPNs = source["SPNs"];
+	        this.SIDHistory = source["SIDHistory"];
+	        this.MemberOf = source["MemberOf"];
+	        this.PasswordLastSet = source["PasswordLastSet"];
+	        this.LastLogon = source["LastLogon"];
+	    }
+	}
+	export class ActionResult {
+	    Name: string;
+	    Command: string;
+	    Output: string;
+	    Error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ActionResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.Command = source["Command"];
+	        this.Output = source["Output"];
+	        this.Error = source["Error"];
+	    }
+	}
 	export class AntivirusResult {
 	    Process: string;
 	    Pid: string;
@@ -296,6 +630,22 @@ export namespace structs {
 	        this.version = source["version"];
 	    }
 	}
+	export class AssetServer {
+	    URL: string;
+	    Token: string;
+	    Roots: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new AssetServer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.URL = source["URL"];
+	        this.Token = source["Token"];
+	        this.Roots = source["Roots"];
+	    }
+	}
 	export class AuthPatch {
 	    MS: string;
 	    Patch: string;
@@ -338,6 +688,222 @@ export namespace structs {
 	        this.Args = source["Args"];
 	    }
 	}
+	export class CrawlEndpoint {
+	    URL: string;
+	    Method: string;
+	    Params: string[];
+	    Body: string;
+	    ContentType: string;
+	    Source: string;
+	    Depth: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CrawlEndpoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.URL = source["URL"];
+	        this.Method = source["Method"];
+	        this.Params = source["Params"];
+	        this.Body = source["Body"];
+	        this.ContentType = source["ContentType"];
+	        this.Source = source["Source"];
+	        this.Depth = source["Depth"];
+	    }
+	}
+	export class CrawlResult {
+	    TaskId: string;
+	    Target: string;
+	    Endpoints: CrawlEndpoint[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CrawlResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.TaskId = source["TaskId"];
+	        this.Target = source["Target"];
+	        this.Endpoints = this.convertValues(source["Endpoints"], CrawlEndpoint);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Credential {
+	    Id: number;
+	    TaskId: string;
+	    Host: string;
+	    Port: number;
+	    Protocol: string;
+	    Username: string;
+	    Password: string;
+	    Source: string;
+	    Created: string;
+	    Actions: ActionResult[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Credential(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Id = source["Id"];
+	        this.TaskId = source["TaskId"];
+	        this.Host = source["Host"];
+	        this.Port = source["Port"];
+	        this.Protocol = source["Protocol"];
+	        this.Username = source["Username"];
+	        this.Password = source["Password"];
+	        this.Source = source["Source"];
+	        this.Created = source["Created"];
+	        this.Actions = this.convertValues(source["Actions"], ActionResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FingerprintCVE {
+	    ID: string;
+	    Severity: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FingerprintCVE(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.Severity = source["Severity"];
+	    }
+	}
+	export class FingerprintDetail {
+	    Name: string;
+	    Version: string;
+	    CPE: string;
+	    CVEs: FingerprintCVE[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FingerprintDetail(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.Version = source["Version"];
+	        this.CPE = source["CPE"];
+	        this.CVEs = this.convertValues(source["CVEs"], FingerprintCVE);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FingerprintImportResult {
+	    Converted: number;
+	    Skipped: number;
+	    Added: number;
+	    Duplicates: number;
+	    NewProducts: number;
+	    MergedProducts: number;
+	    ActivePaths: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FingerprintImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Converted = source["Converted"];
+	        this.Skipped = source["Skipped"];
+	        this.Added = source["Added"];
+	        this.Duplicates = source["Duplicates"];
+	        this.NewProducts = source["NewProducts"];
+	        this.MergedProducts = source["MergedProducts"];
+	        this.ActivePaths = source["ActivePaths"];
+	    }
+	}
+	export class HoneypotInfo {
+	    Score: number;
+	    Reasons: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new HoneypotInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Score = source["Score"];
+	        this.Reasons = source["Reasons"];
+	    }
+	}
+	export class ICSInfo {
+	    Protocol: string;
+	    Vendor: string;
+	    Model: string;
+	    Firmware: string;
+	    Serial: string;
+	    Extra: {[key: string]: string};
+	
+	    static createFrom(source: any = {}) {
+	        return new ICSInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Protocol = source["Protocol"];
+	        this.Vendor = source["Vendor"];
+	        this.Model = source["Model"];
+	        this.Firmware = source["Firmware"];
+	        this.Serial = source["Serial"];
+	        this.Extra = source["Extra"];
+	    }
+	}
 	export class OfficialAccount {
 	    Name: string;
 	    Numbers: string;
@@ -434,6 +1000,118 @@ export namespace structs {
 	        this.API = source["API"];
 	    }
 	}
+	export class PasswordDictOptions {
+	    Companies: CompanyInfo[];
+	    Keywords: string[];
+	    Domains: string[];
+	    Names: string[];
+	    StartYear: number;
+	    EndYear: number;
+	    KeyboardWalk: boolean;
+	    Limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PasswordDictOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Companies = this.convertValues(source["Companies"], CompanyInfo);
+	        this.Keywords = source["Keywords"];
+	        this.Domains = source["Domains"];
+	        this.Names = source["Names"];
+	        this.StartYear = source["StartYear"];
+	        this.EndYear = source["EndYear"];
+	        this.KeyboardWalk = source["KeyboardWalk"];
+	        this.Limit = source["Limit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScreenshotCluster {
+	    Hash: string;
+	    Screenshot: string;
+	    URL: string;
+	    Title: string;
+	    URLs: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ScreenshotCluster(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Hash = source["Hash"];
+	        this.Screenshot = source["Screenshot"];
+	        this.URL = source["URL"];
+	        this.Title = source["Title"];
+	        this.URLs = source["URLs"];
+	    }
+	}
+	export class TLSInfo {
+	    JARM: string;
+	    JA3S: string;
+	    Version: string;
+	    Cipher: string;
+	    SubjectCN: string;
+	    SubjectDN: string;
+	    IssuerCN: string;
+	    IssuerDN: string;
+	    IssuerOrg: string[];
+	    SANs: string[];
+	    NotBefore: string;
+	    NotAfter: string;
+	    KeyAlgorithm: string;
+	    KeySize: number;
+	    SignatureAlgorithm: string;
+	    SelfSigned: boolean;
+	    Expired: boolean;
+	    WeakProtocols: string[];
+	    WeakCiphers: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TLSInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.JARM = source["JARM"];
+	        this.JA3S = source["JA3S"];
+	        this.Version = source["Version"];
+	        this.Cipher = source["Cipher"];
+	        this.SubjectCN = source["SubjectCN"];
+	        this.SubjectDN = source["SubjectDN"];
+	        this.IssuerCN = source["IssuerCN"];
+	        this.IssuerDN = source["IssuerDN"];
+	        this.IssuerOrg = source["IssuerOrg"];
+	        this.SANs = source["SANs"];
+	        this.NotBefore = source["NotBefore"];
+	        this.NotAfter = source["NotAfter"];
+	        this.KeyAlgorithm = source["KeyAlgorithm"];
+	        this.KeySize = source["KeySize"];
+	        this.SignatureAlgorithm = source["SignatureAlgorithm"];
+	        this.SelfSigned = source["SelfSigned"];
+	        this.Expired = source["Expired"];
+	        this.WeakProtocols = source["WeakProtocols"];
+	        this.WeakCiphers = source["WeakCiphers"];
+	    }
+	}
 	export class Tianyancha {
 	    Enable: boolean;
 	    Token: string;
@@ -897,6 +1575,52 @@ export namespace structs {
 	        this.Source = source["Source"];
 	    }
 	}
+	export class NTLMInfo {
+	    Protocol: string;
+	    NetBIOSComputerName: string;
+	    NetBIOSDomainName: string;
+	    DNSComputerName: string;
+	    DNSDomainName: string;
+	    DNSTreeName: string;
+	    OSVersion: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NTLMInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Protocol = source["Protocol"];
+	        this.NetBIOSComputerName = source["NetBIOSComputerName"];
+	        this.NetBIOSDomainName = source["NetBIOSDomainName"];
+	        this.DNSComputerName = source["DNSComputerName"];
+	        this.DNSDomainName = source["DNSDomainName"];
+	        this.DNSTreeName = source["DNSTreeName"];
+	        this.OSVersion = source["OSVersion"];
+	    }
+	}
+	export class NetInfo {
+	    ComputerName: string;
+	    Workgroup: string;
+	    MAC: string;
+	    DomainController: boolean;
+	    Hostname: string;
+	    Addresses: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NetInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ComputerName = source["ComputerName"];
+	        this.Workgroup = source["Workgroup"];
+	        this.MAC = source["MAC"];
+	        this.DomainController = source["DomainController"];
+	        this.Hostname = source["Hostname"];
+	        this.Addresses = source["Addresses"];
+	    }
+	}
 	export class InfoResult {
 	    TaskId: string;
 	    URL: string;
@@ -911,6 +1635,13 @@ export namespace structs {
 	    WAF: string;
 	    Detect: string;
 	    Screenshot: string;
+	    NTLMInfo: NTLMInfo;
+	    NetInfo: NetInfo;
+	    ICSInfo: ICSInfo;
+	    FingerprintDetails: FingerprintDetail[];
+	    ScreenshotHash: string;
+	    Honeypot: HoneypotInfo;
+	    TLSInfo: TLSInfo;
 	
 	    static createFrom(source: any = {}) {
 	        return new InfoResult(source);
@@ -931,7 +1662,32 @@ export namespace structs {
 	        this.WAF = source["WAF"];
 	        this.Detect = source["Detect"];
 	        this.Screenshot = source["Screenshot"];
+	        this.NTLMInfo = this.convertValues(source["NTLMInfo"], NTLMInfo);
+	        this.NetInfo = this.convertValues(source["NetInfo"], NetInfo);
+	        this.ICSInfo = this.convertValues(source["ICSInfo"], ICSInfo);
+	        this.FingerprintDetails = this.convertValues(source["FingerprintDetails"], FingerprintDetail);
+	        this.ScreenshotHash = source["ScreenshotHash"];
+	        this.Honeypot = this.convertValues(source["Honeypot"], HoneypotInfo);
+	        this.TLSInfo = this.convertValues(source["TLSInfo"], TLSInfo);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	
 	export class JSFindResult {
@@ -1354,6 +2110,32 @@ export namespace structs {
 		    return a;
 		}
 	}
+	export class VHostResult {
+	    TaskId: string;
+	    URL: string;
+	    Address: string;
+	    Host: string;
+	    StatusCode: number;
+	    Length: number;
+	    Title: string;
+	    Source: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new VHostResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.TaskId = source["TaskId"];
+	        this.URL = source["URL"];
+	        this.Address = source["Address"];
+	        this.Host = source["Host"];
+	        this.StatusCode = source["StatusCode"];
+	        this.Length = source["Length"];
+	        this.Title = source["Title"];
+	        this.Source = source["Source"];
+	    }
+	}
 	export class VulnerabilityInfo {
 	    TaskId: string;
 	    ID: string;
@@ -1367,6 +2149,7 @@ export namespace structs {
 	    Response: string;
 	    ResponseTime: string;
 	    Extract: string;
+	    Source: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new VulnerabilityInfo(source);
@@ -1386,6 +2169,7 @@ export namespace structs {
 	        this.Response = source["Response"];
 	        this.ResponseTime = source["ResponseTime"];
 	        this.Extract = source["Extract"];
+	        this.Source = source["Source"];
 	    }
 	}
 	export class WebReport {
@@ -1437,6 +2221,17 @@ export namespace structs {
 	    AppendTemplateFolder: string;
 	    NetworkCard: string;
 	    CustomHeaders: string;
+	    DefaultCredential: boolean;
+	    WAFProbe: boolean;
+	    TLSProbe: boolean;
+	    VHostDiscovery: boolean;
+	    VHostDomains: string[];
+	    VHostSubdomains: string[];
+	    VHostICPDomains: string[];
+	    Crawl: boolean;
+	    CrawlDepth: number;
+	    CrawlHeadless: boolean;
+	    CrawlScope: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new WebscanOptions(source);
@@ -1458,6 +2253,17 @@ export namespace structs {
 	        this.AppendTemplateFolder = source["AppendTemplateFolder"];
 	        this.NetworkCard = source["NetworkCard"];
 	        this.CustomHeaders = source["CustomHeaders"];
+	        this.DefaultCredential = source["DefaultCredential"];
+	        this.WAFProbe = source["WAFProbe"];
+	        this.TLSProbe = source["TLSProbe"];
+	        this.VHostDiscovery = source["VHostDiscovery"];
+	        this.VHostDomains = source["VHostDomains"];
+	        this.VHostSubdomains = source["VHostSubdomains"];
+	        this.VHostICPDomains = source["VHostICPDomains"];
+	        this.Crawl = source["Crawl"];
+	        this.CrawlDepth = source["CrawlDepth"];
+	        this.CrawlHeadless = source["CrawlHeadless"];
+	        this.CrawlScope = source["CrawlScope"];
 	    }
 	}
 	export class WindowsSize {
@@ -5,10 +5,20 @@ import {dirsearch} from '../models';
 import {context} from '../models';
 import {space} from '../models';
 
+export function ADRecon(arg1:string,arg2:string,arg3:string):Promise<structs.ADReconResult>;
+
 export function AnalyzeAPI(arg1:string,arg2:string,arg3:Array<string>,arg4:{[key: string]: string},arg5:{[key: string]: string},arg6:Array<string>,arg7:Array<string>):Promise<void>;
 
+export function AssetServer():Promise<structs.AssetServer>;
+
 export function Callgologger(arg1:string,arg2:string):Promise<void>;
 
+export function ClusterScreenshots(arg1:Array<structs.InfoResult>,arg2:number):Promise<Array<structs.ScreenshotCluster>>;
+
+export function ConfigureAssetServer(arg1:number):Promise<void>;
+
+export function ConfigureBrowser(arg1:number,arg2:string):Promise<void>;
+
 export function CyberChefLocalServer():Promise<void>;
 
 export function DownloadCyberChef(arg1:string):Promise<void>;
@@ -17,6 +27,8 @@ export function EnrichCompanyWithMiit(arg1:structs.CompanyInfo,arg2:string):Prom
 
 export function ExitScanner(arg1:string):Promise<void>;
 
+export function ExportADRecon(arg1:structs.ADReconResult,arg2:string,arg3:string):Promise<boolean>;
+
 export function ExtractAllJSLink(arg1:string):Promise<Array<string>>;
 
 export function FaviconMd5(arg1:string):Promise<string>;
@@ -29,6 +41,8 @@ export function FofaSearch(arg1:string,arg2:string,arg3:string,arg4:string,arg5:
 
 export function FofaTips(arg1:string):Promise<structs.TipsResult>;
 
+export function GeneratePasswordDict(arg1:structs.PasswordDictOptions):Promise<Array<string>>;
+
 export function GetFingerPocMap():Promise<{[key: string]: Array<string>}>;
 
 export function GitDorks(arg1:string,arg2:string,arg3:string):Promise<structs.ISICollectionResult>;
@@ -45,6 +59,8 @@ export function HunterTips(arg1:string):Promise<structs.HunterTips>;
 
 export function IconHash(arg1:string):Promise<string>;
 
+export function ImportFingerprints(arg1:string,arg2:string):Promise<structs.FingerprintImportResult>;
+
 export function InitRule(arg1:string):Promise<boolean>;
 
 export function IpLocation(arg1:string):Promise<string>;
@@ -57,6 +73,8 @@ export function NetDial(arg1:string):Promise<boolean>;
 
 export function NewCrackScanenr(arg1:string,arg2:string,arg3:Array<string>,arg4:Array<string>):Promise<void>;
 
+export function NewCredentialReuse(arg1:string,arg2:Array<string>,arg3:Array<structs.Credential>):Promise<void>;
+
 export function NewDSStoreEngine(arg1:string):Promise<Array<string>>;
 
 export function NewDirsearchScanner(arg1:dirsearch.Options):Promise<void>;
@@ -73,6 +91,8 @@ export function ResumeAfterHumanCheck():Promise<void>;
 
 export function SendRequest(arg1:string,arg2:boolean,arg3:boolean,arg4:string):Promise<structs.RawResponse>;
 
+export function Shutdown(arg1:context.Context):Promise<void>;
+
 export function Socks5Conn(arg1:string,arg2:number,arg3:number,arg4:string,arg5:string,arg6:string):Promise<boolean>;
 
 export function SpaceGetPort(arg1:string):Promise<Array<number>>;
@@ -2,14 +2,34 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ADRecon(arg1, arg2, arg3) {
+  return window['go']['services']['App']['ADRecon'](arg1, arg2, arg3);
+}
+
 export function AnalyzeAPI(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
   return window['go']['services']['App']['AnalyzeAPI'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
 }
 
+export function AssetServer() {
+  return window['go']['services']['App']['AssetServer']();
+}
+
 export function Callgologger(arg1, arg2) {
   return window['go']['services']['App']['Callgologger'](arg1, arg2);
 }
 
+export function ClusterScreenshots(arg1, arg2) {
+  return window['go']['services']['App']['ClusterScreenshots'](arg1, arg2);
+}
+
+export function ConfigureAssetServer(arg1) {
+  return window['go']['services']['App']['ConfigureAssetServer'](arg1);
+}
+
+export function ConfigureBrowser(arg1, arg2) {
+  return window['go']['services']['App']['ConfigureBrowser'](arg1, arg2);
+}
+
 export function CyberChefLocalServer() {
   return window['go']['services']['App']['CyberChefLocalServer']();
 }
@@ -26,6 +46,10 @@ export function ExitScanner(arg1) {
   return window['go']['services']['App']['ExitScanner'](arg1);
 }
 
+export function ExportADRecon(arg1, arg2, arg3) {
+  return window['go']['services']['App']['ExportADRecon'](arg1, arg2, arg3);
+}
+
 export function ExtractAllJSLink(arg1) {
   return window['go']['services']['App']['ExtractAllJSLink'](arg1);
 }
@@ -50,6 +74,10 @@ export function FofaTips(arg1) {
   return window['go']['services']['App']['FofaTips'](arg1);
 }
 
+export function GeneratePasswordDict(arg1) {
+  return window['go']['services']['App']['GeneratePasswordDict'](arg1);
+}
+
 export function GetFingerPocMap() {
   return window['go']['services']['App']['GetFingerPocMap']();
 }
@@ -82,6 +110,10 @@ export function IconHash(arg1) {
   return window['go']['services']['App']['IconHash'](arg1);
 }
 
+export function ImportFingerprints(arg1, arg2) {
+  return window['go']['services']['App']['ImportFingerprints'](arg1, arg2);
+}
+
 export function InitRule(arg1) {
   return window['go']['services']['App']['InitRule'](arg1);
 }
@@ -106,6 +138,10 @@ export function NewCrackScanenr(arg1, arg2, arg3, arg4) {
   return window['go']['services']['App']['NewCrackScanenr'](arg1, arg2, arg3, arg4);
 }
 
+export function NewCredentialReuse(arg1, arg2, arg3) {
+  return window['go']['services']['App']['NewCredentialReuse'](arg1, arg2, arg3);
+}
+
 export function NewDSStoreEngine(arg1) {
   return window['go']['services']['App']['NewDSStoreEngine'](arg1);
 }
@@ -138,6 +174,10 @@ export function SendRequest(arg1, arg2, arg3, arg4) {
   return window['go']['services']['App']['SendRequest'](arg1, arg2, arg3, arg4);
 }
 
+export function Shutdown(arg1) {
+  return window['go']['services']['App']['Shutdown'](arg1);
+}
+
 export function Socks5Conn(arg1, arg2, arg3, arg4, arg5, arg6) {
   return window['go']['services']['App']['Socks5Conn'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
@@ -6,12 +6,16 @@ import {context} from '../models';
 
 export function AddConnection(arg1:structs.DatabaseConnection):Promise<boolean>;
 
+export function AddCredential(arg1:structs.Credential):Promise<boolean>;
+
 export function AddFingerscanResult(arg1:structs.InfoResult):Promise<boolean>;
 
 export function AddPocscanResult(arg1:structs.VulnerabilityInfo):Promise<boolean>;
 
 export function AddScanTask(arg1:string,arg2:string,arg3:string,arg4:number,arg5:number):Promise<boolean>;
 
+export function ClearCredentials():Promise<boolean>;
+
 export function ConnectDatabase(arg1:structs.DatabaseConnection):Promise<boolean>;
 
 export function ConnectMongodb(arg1:string,arg2:string,arg3:string):Promise<mongo.Client>;
@@ -68,6 +72,8 @@ export function ReadWebReportWithJson(arg1:string):Promise<structs.WebReport>;
 
 export function RemoveConnection(arg1:string):Promise<boolean>;
 
+export function RemoveCredential(arg1:number):Promise<boolean>;
+
 export function RemoveFavGrammarFiled(arg1:string,arg2:string,arg3:string):Promise<boolean>;
 
 export function RemoveFingerprintResult(arg1:string,arg2:Array<string>):Promise<boolean>;
@@ -80,6 +86,8 @@ export function RenameScanTask(arg1:string,arg2:string):Promise<boolean>;
 
 export function RetrieveAllScanTasks():Promise<Array<structs.TaskResult>>;
 
+export function RetrieveCredentials():Promise<Array<structs.Credential>>;
+
 export function RetrieveFingerscanResults(arg1:string):Promise<Array<structs.InfoResult>>;
 
 export function RetrievePocscanResults(arg1:string):Promise<Array<structs.VulnerabilityInfo>>;
@@ -96,6 +104,8 @@ export function Startup(arg1:context.Context):Promise<void>;
 
 export function UpdateConnection(arg1:structs.DatabaseConnection):Promise<boolean>;
 
+export function UpdateCredentialActions(arg1:number,arg2:Array<structs.ActionResult>):Promise<boolean>;
+
 export function UpdateOrInsertPath(arg1:string):Promise<boolean>;
 
 export function UpdateScanTaskWithResults(arg1:string,arg2:number,arg3:number):Promise<boolean>;
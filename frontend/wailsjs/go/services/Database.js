@@ -6,6 +6,10 @@ export function AddConnection(arg1) {
   return window['go']['services']['Database']['AddConnection'](arg1);
 }
 
+export function AddCredential(arg1) {
+  return window['go']['services']['Database']['AddCredential'](arg1);
+}
+
 export function AddFingerscanResult(arg1) {
   return window['go']['services']['Database']['AddFingerscanResult'](arg1);
 }
@@ -18,6 +22,10 @@ export function AddScanTask(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['services']['Database']['AddScanTask'](arg1, arg2, arg3, arg4, arg5);
 }
 
+export function ClearCredentials() {
+  return window['go']['services']['Database']['ClearCredentials']();
+}
+
 export function ConnectDatabase(arg1) {
   return window['go']['services']['Database']['ConnectDatabase'](arg1);
 }
@@ -130,6 +138,10 @@ export function RemoveConnection(arg1) {
   return window['go']['services']['Database']['RemoveConnection'](arg1);
 }
 
+export function RemoveCredential(arg1) {
+  return window['go']['services']['Database']['RemoveCredential'](arg1);
+}
+
 export function RemoveFavGrammarFiled(arg1, arg2, arg3) {
   return window['go']['services']['Database']['RemoveFavGrammarFiled'](arg1, arg2, arg3);
 }
@@ -154,6 +166,10 @@ export function RetrieveAllScanTasks() {
   return window['go']['services']['Database']['RetrieveAllScanTasks']();
 }
 
+export function RetrieveCredentials() {
+  return window['go']['services']['Database']['RetrieveCredentials']();
+}
+
 export function RetrieveFingerscanResults(arg1) {
   return window['go']['services']['Database']['RetrieveFingerscanResults'](arg1);
 }
@@ -186,6 +202,10 @@ export function UpdateConnection(arg1) {
   return window['go']['services']['Database']['UpdateConnection'](arg1);
 }
 
+export function UpdateCredentialActions(arg1, arg2) {
+  return window['go']['services']['Database']['UpdateCredentialActions'](arg1, arg2);
+}
+
 export function UpdateOrInsertPath(arg1) {
   return window['go']['services']['Database']['UpdateOrInsertPath'](arg1);
 }
@@ -3,6 +3,7 @@ module slack-wails
 go 1.23.0
 
 require (
+	github.com/Azure/go-ntlmssp v0.0.0-20221128193559-754e69321358
 	github.com/IBM/sarama v1.45.1
 	github.com/Knetic/govaluate v3.0.1-0.20171022003610-9aa49832a739+incompatible
 	github.com/chromedp/cdproto v0.0.0-20241022234722-4d5d5faf59fb
@@ -10,6 +11,7 @@ require (
 	github.com/go-ldap/ldap/v3 v3.4.5
 	github.com/go-resty/resty/v2 v2.16.5
 	github.com/go-sql-driver/mysql v1.7.1
+	github.com/hdm/jarm-go v0.0.7
 	github.com/jlaffaye/ftp v0.2.0
 	github.com/lib/pq v1.10.9
 	github.com/mat/besticon/v3 v3.21.0
@@ -22,6 +24,7 @@ require (
 	github.com/orcastor/fico v0.0.0-20241117150408-e3bea0a75fd1
 	github.com/panjf2000/ants/v2 v2.9.1
 	github.com/parsiya/golnk v0.0.0-20221103095132-740a4c27c4ff
+	github.com/projectdiscovery/go-smb2 v0.0.0-20240129202741-052cc450c6cb
 	github.com/projectdiscovery/nuclei/v3 v3.4.4
 	github.com/projectdiscovery/utils v0.4.19
 	github.com/qiwentaidi/clients v0.0.0-20250702115236-129e21f47c9d
@@ -46,7 +49,6 @@ require (
 	git.mills.io/prologic/smtpd v0.0.0-20210710122116-a525b76c287a // indirect
 	github.com/Azure/azure-sdk-for-go/sdk/azcore v1.17.0 // indirect
 	github.com/Azure/azure-sdk-for-go/sdk/azidentity v1.7.0 // indirect
-	github.com/Azure/go-ntlmssp v0.0.0-20221128193559-754e69321358 // indirect
 	github.com/Masterminds/semver/v3 v3.2.1 // indirect
 	github.com/Mzack9999/gcache v0.0.0-20230410081825-519e28eab057 // indirect
 	github.com/Mzack9999/go-http-digest-auth-client v0.6.1-0.20220414142836-eb8883508809 // indirect
@@ -162,7 +164,6 @@ require (
 	github.com/hashicorp/go-version v1.6.0 // indirect
 	github.com/hashicorp/golang-lru/v2 v2.0.7 // indirect
 	github.com/hbakhtiyor/strsim v0.0.0-20190107154042-4d2bbb273edf // indirect
-	github.com/hdm/jarm-go v0.0.7 // indirect
 	github.com/huin/asn1ber v0.0.0-20120622192748-af09f62e6358 // indirect
 	github.com/icodeface/tls v0.0.0-20190904083142-17aec93c60e5 // indirect
 	github.com/imdario/mergo v0.3.16 // indirect
@@ -221,7 +222,6 @@ require (
 	github.com/projectdiscovery/fastdialer v0.4.0 // indirect
 	github.com/projectdiscovery/fasttemplate v0.0.2 // indirect
 	github.com/projectdiscovery/freeport v0.0.7 // indirect
-	github.com/projectdiscovery/go-smb2 v0.0.0-20240129202741-052cc450c6cb // indirect
 	github.com/projectdiscovery/goflags v0.1.74 // indirect
 	github.com/projectdiscovery/gologger v1.1.54 // indirect
 	github.com/projectdiscovery/gostruct v0.0.2 // indirect
@@ -0,0 +1,193 @@
+// 本地资源服务，为前端提供截图、SourceMap 还原文件与报告附件，仅监听回环地址并校验访问令牌
+package assets
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultPort = 8732
+
+// 源码类文件按纯文本返回，避免在 webview 中被当作脚本或页面执行
+var textExtensions = map[string]bool{
+	".js": true, ".mjs": true, ".cjs": true, ".jsx": true, ".ts": true, ".tsx": true, ".vue": true, ".svelte": true,
+	".map": true, ".json": true, ".html": true, ".htm": true, ".xml": true, ".svg": true, ".css": true, ".scss": true,
+	".less": true, ".md": true, ".txt": true, ".log": true, ".yaml": true, ".yml": true,
+}
+
+type Server struct {
+	mu     sync.Mutex
+	port   int
+	token  string
+	roots  map[string]string
+	pages  map[string]bool
+	server *http.Server
+}
+
+var shared = NewServer(DefaultPort)
+
+// Shared 返回全局共享的资源服务
+func Shared() *Server {
+	return shared
+}
+
+func NewServer(port int) *Server {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return &Server{
+		port:  port,
+		token: hex.EncodeToString(buf),
+		roots: make(map[string]string),
+		pages: make(map[string]bool),
+	}
+}
+
+// Register 将目录挂载到 /name/ 路径下
+func (s *Server) Register(name, dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roots[name] = dir
+}
+
+// RegisterPages 挂载目录，其中的 HTML 文件按页面返回，sandbox 策略下页面脚本仍不会执行
+func (s *Server) RegisterPages(name, dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roots[name] = dir
+	s.pages[name] = true
+}
+
+// Roots 返回已挂载的目录，前端据此将本地文件路径转换为资源链接
+func (s *Server) Roots() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	roots := make(map[string]string, len(s.roots))
+	for name, dir := range s.roots {
+		roots[name] = dir
+	}
+	return roots
+}
+
+// Configure 修改监听端口，服务已启动时关闭，下次使用时在新端口启动
+func (s *Server) Configure(port int) {
+	if port <= 0 {
+		port = DefaultPort
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if port != s.port {
+		s.closeLocked()
+		s.port = port
+	}
+}
+
+// Start 尚未启动时监听 127.0.0.1，返回服务地址，端口被占用时返回错误
+func (s *Server) Start() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	addr := fmt.Sprintf("127.0.0.1:%d", s.port)
+	if s.server != nil {
+		return "http://" + addr, nil
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("资源服务端口 %d 无法监听，可能已被占用，请在设置中修改端口: %v", s.port, err)
+	}
+	server := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go server.Serve(listener)
+	s.server = server
+	return "http://" + addr, nil
+}
+
+// Token 访问令牌，每次启动应用随机生成
+func (s *Server) Token() string {
+	return s.token
+}
+
+// Close 关闭资源服务
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeLocked()
+}
+
+func (s *Server) closeLocked() {
+	if s.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	s.server.Shutdown(ctx)
+	s.server = nil
+}
+
+// ServeHTTP 请求格式为 /name/相对路径?token=令牌，也可以通过 X-Asset-Token 请求头传递令牌
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = r.Header.Get("X-Asset-Token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	fp, page, err := s.resolve(r.URL.Path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	info, err := os.Stat(fp)
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	ext := strings.ToLower(filepath.Ext(fp))
+	contentType := mime.TypeByExtension(ext)
+	if page && (ext == ".html" || ext == ".htm") {
+		contentType = "text/html; charset=utf-8"
+	} else if textExtensions[ext] || contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeFile(w, r, fp)
+}
+
+// resolve 将请求路径转换为挂载目录下的文件路径，拒绝跳出挂载目录，同时返回该目录是否按页面返回 HTML
+func (s *Server) resolve(urlPath string) (string, bool, error) {
+	name, rel, _ := strings.Cut(strings.TrimPrefix(path.Clean("/"+urlPath), "/"), "/")
+	s.mu.Lock()
+	root, ok := s.roots[name]
+	page := s.pages[name]
+	s.mu.Unlock()
+	if !ok || rel == "" {
+		return "", false, errors.New("not found")
+	}
+	fp := filepath.Join(root, filepath.FromSlash(rel))
+	if r, err := filepath.Rel(root, fp); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", false, errors.New("not found")
+	}
+	return fp, page, nil
+}
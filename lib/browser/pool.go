@@ -0,0 +1,268 @@
+// 共享的无头浏览器池，网站截图、指纹动态计算与 JS 动态提取共用同一个浏览器进程
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+type Options struct {
+	Tabs         int           // 同时打开的标签页上限
+	TabTimeout   time.Duration // 单个标签页从打开到关闭的超时时间
+	IdleTimeout  time.Duration // 等待网络空闲或选择器出现的最长时间，超时后继续后续操作
+	Proxy        string        // http、socks5 代理，Chrome 不支持代理认证，用户名密码会被忽略
+	WaitSelector string        // 页面加载后等待该选择器出现，为空时等待网络空闲
+}
+
+var DefaultOptions = Options{
+	Tabs:        4,
+	TabTimeout:  30 * time.Second,
+	IdleTimeout: 10 * time.Second,
+}
+
+type Pool struct {
+	mu      sync.Mutex
+	options Options
+	tabs    chan struct{}
+
+	browserCtx    context.Context
+	cancelBrowser context.CancelFunc
+}
+
+var shared = NewPool(DefaultOptions)
+
+// Shared 返回全局共享的浏览器池
+func Shared() *Pool {
+	return shared
+}
+
+func NewPool(options Options) *Pool {
+	p := &Pool{}
+	p.Configure(options)
+	return p
+}
+
+// Configure 更新浏览器池配置，未设置的字段使用默认值，代理变化时下次使用会重新启动浏览器
+func (p *Pool) Configure(options Options) {
+	if options.Tabs <= 0 {
+		options.Tabs = DefaultOptions.Tabs
+	}
+	if options.TabTimeout <= 0 {
+		options.TabTimeout = DefaultOptions.TabTimeout
+	}
+	if options.IdleTimeout <= 0 {
+		options.IdleTimeout = DefaultOptions.IdleTimeout
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tabs == nil || options.Tabs != p.options.Tabs {
+		p.tabs = make(chan struct{}, options.Tabs)
+	}
+	if options.Proxy != p.options.Proxy {
+		p.closeLocked()
+	}
+	p.options = options
+}
+
+// Options 返回当前配置，可修改部分字段后传给 Configure
+func (p *Pool) Options() Options {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.options
+}
+
+// Close 关闭浏览器进程，之后调用 Do 会重新启动
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closeLocked()
+}
+
+func (p *Pool) closeLocked() {
+	if p.cancelBrowser != nil {
+		p.cancelBrowser()
+	}
+	p.browserCtx, p.cancelBrowser = nil, nil
+}
+
+// browser 返回正在运行的浏览器，尚未启动或已被关闭时重新启动
+func (p *Pool) browser() (context.Context, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.browserCtx != nil {
+		return p.browserCtx, nil
+	}
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", true),
+		chromedp.Flag("disable-background-timer-throttling", false),
+		chromedp.IgnoreCertErrors,
+	)
+	if proxy := proxyServer(p.options.Proxy); proxy != "" {
+		opts = append(opts, chromedp.ProxyServer(proxy))
+	}
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), opts...)
+	browserCtx, cancel := chromedp.NewContext(allocCtx)
+	// 首次 Run 启动浏览器进程，不能使用带超时的派生 context，否则超时后浏览器会被关闭
+	if err := chromedp.Run(browserCtx); err != nil {
+		cancel()
+		cancelAlloc()
+		return nil, fmt.Errorf("启动浏览器失败: %v", err)
+	}
+	p.browserCtx = browserCtx
+	p.cancelBrowser = func() {
+		cancel()
+		cancelAlloc()
+	}
+	return browserCtx, nil
+}
+
+// reset 浏览器崩溃后关闭，browserCtx 已被其他标签页重启时不做处理
+func (p *Pool) reset(browserCtx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.browserCtx == browserCtx {
+		p.closeLocked()
+	}
+}
+
+// alive 检查浏览器进程是否仍可响应
+func alive(browserCtx context.Context) bool {
+	if browserCtx.Err() != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(browserCtx, 3*time.Second)
+	defer cancel()
+	var result int
+	return chromedp.Run(ctx, chromedp.Evaluate("1", &result)) == nil
+}
+
+// Do 占用一个标签页执行 fn，标签页数量达到上限时等待。浏览器崩溃导致失败时重启浏览器并重试一次
+func (p *Pool) Do(ctx context.Context, fn func(tab context.Context) error) error {
+	p.mu.Lock()
+	tabs, timeout := p.tabs, p.options.TabTimeout
+	p.mu.Unlock()
+	select {
+	case tabs <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-tabs }()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var browserCtx context.Context
+		if browserCtx, err = p.browser(); err != nil {
+			return err
+		}
+		err = p.runTab(ctx, browserCtx, timeout, fn)
+		if err == nil || ctx.Err() != nil || alive(browserCtx) {
+			return err
+		}
+		p.reset(browserCtx)
+	}
+	return err
+}
+
+func (p *Pool) runTab(ctx, browserCtx context.Context, timeout time.Duration, fn func(tab context.Context) error) error {
+	tabCtx, cancel := chromedp.NewContext(browserCtx)
+	defer cancel() // 关闭标签页
+	tabCtx, cancelTimeout := context.WithTimeout(tabCtx, timeout)
+	defer cancelTimeout()
+	// 调用方取消时同时关闭标签页
+	stop := context.AfterFunc(ctx, cancelTimeout)
+	defer stop()
+	return fn(tabCtx)
+}
+
+// Run 打开页面并在加载完成后依次执行 actions
+func (p *Pool) Run(ctx context.Context, url string, actions ...chromedp.Action) error {
+	return p.Do(ctx, func(tab context.Context) error {
+		return chromedp.Run(tab, append([]chromedp.Action{p.Navigate(url)}, actions...)...)
+	})
+}
+
+// Navigate 打开页面，等待配置的选择器出现，未配置时等待网络空闲，超过 IdleTimeout 后不再等待
+func (p *Pool) Navigate(url string) chromedp.Action {
+	p.mu.Lock()
+	waitSelector, idleTimeout := p.options.WaitSelector, p.options.IdleTimeout
+	p.mu.Unlock()
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if waitSelector != "" {
+			if err := chromedp.Navigate(url).Do(ctx); err != nil {
+				return err
+			}
+			waitCtx, cancel := context.WithTimeout(ctx, idleTimeout)
+			defer cancel()
+			// 选择器未出现时同样继续截图等操作
+			if err := chromedp.WaitReady(waitSelector, chromedp.ByQuery).Do(waitCtx); err != nil && ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return nil
+		}
+
+		c := chromedp.FromContext(ctx)
+		if c == nil || c.Target == nil {
+			return errors.New("invalid browser context")
+		}
+		mainFrame := cdp.FrameID(c.Target.TargetID)
+		idle := make(chan struct{})
+		var (
+			mu     sync.Mutex
+			loader cdp.LoaderID
+			once   sync.Once
+		)
+		lctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		// init 事件代表主框架开始新的导航，只接受同一次导航的 networkIdle
+		chromedp.ListenTarget(lctx, func(ev interface{}) {
+			e, ok := ev.(*page.EventLifecycleEvent)
+			if !ok || e.FrameID != mainFrame {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch e.Name {
+			case "init":
+				loader = e.LoaderID
+			case "networkIdle":
+				if loader != "" && e.LoaderID == loader {
+					once.Do(func() { close(idle) })
+				}
+			}
+		})
+		if err := page.SetLifecycleEventsEnabled(true).Do(ctx); err != nil {
+			return err
+		}
+		if err := chromedp.Navigate(url).Do(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-idle:
+		case <-time.After(idleTimeout):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	})
+}
+
+// proxyServer 去掉代理地址中的认证信息
+func proxyServer(proxy string) string {
+	if proxy == "" {
+		return ""
+	}
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return proxy
+	}
+	u.User = nil
+	return u.String()
+}
@@ -3,11 +3,14 @@ package nuclei
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/projectdiscovery/goflags"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/ratelimit"
+	proxyutils "github.com/projectdiscovery/utils/proxy"
 
 	"github.com/projectdiscovery/nuclei/v3/pkg/authprovider"
 	"github.com/projectdiscovery/nuclei/v3/pkg/catalog"
@@ -322,10 +325,39 @@ func WithProxy(proxy []string, proxyInternalRequests bool) NucleiSDKOptions {
 		if e.mode == threadSafe {
 			return ErrOptionsNotSupported.Msgf("WithProxy")
 		}
-		e.opts.Proxy = proxy
-		e.opts.ProxyInternal = proxyInternalRequests
+		return applyProxy(e, proxy, proxyInternalRequests)
+	}
+}
+
+// WithGlobalProxy allows setting proxy options of thread safe engine
+// http clients are shared by all executions, so it must be passed when creating the engine
+// Note: it has no effect when passed to ExecuteNucleiWithOpts
+func WithGlobalProxy(proxy []string, proxyInternalRequests bool) NucleiSDKOptions {
+	return func(e *NucleiEngine) error {
+		return applyProxy(e, proxy, proxyInternalRequests)
+	}
+}
+
+// applyProxy sets the first proxy as the proxy used by http clients
+func applyProxy(e *NucleiEngine, proxy []string, proxyInternalRequests bool) error {
+	e.opts.Proxy = proxy
+	e.opts.ProxyInternal = proxyInternalRequests
+	if len(proxy) == 0 {
 		return nil
 	}
+	proxyURL, err := url.Parse(proxy[0])
+	if err != nil {
+		return err
+	}
+	switch proxyURL.Scheme {
+	case proxyutils.HTTP, proxyutils.HTTPS:
+		e.opts.AliveHttpProxy = proxyURL.String()
+	case proxyutils.SOCKS5:
+		e.opts.AliveSocksProxy = proxyURL.String()
+	default:
+		return fmt.Errorf("unsupported proxy scheme: %s", proxyURL.Scheme)
+	}
+	return nil
 }
 
 // WithScanStrategy allows setting scan strategy options
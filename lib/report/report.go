@@ -2,10 +2,44 @@ package report
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slack-wails/lib/assets"
 	"slack-wails/lib/structs"
+	"slack-wails/lib/utils"
 	"strings"
 )
 
+// 报告附件目录：~/slack/report，通过本地资源服务的 /report/ 路径访问
+var AttachmentDir = filepath.Join(utils.HomeDir(), "slack", "report")
+
+func init() {
+	assets.Shared().RegisterPages("report", AttachmentDir)
+}
+
+// SaveAttachment 将导出的报告复制到附件目录，同名附件会被覆盖，返回附件路径
+func SaveAttachment(reportpath string) (string, error) {
+	if err := os.MkdirAll(AttachmentDir, 0755); err != nil {
+		return "", err
+	}
+	src, err := os.Open(reportpath)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	attachment := filepath.Join(AttachmentDir, filepath.Base(reportpath))
+	dst, err := os.Create(attachment)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+	if _, err = io.Copy(dst, src); err != nil {
+		return "", err
+	}
+	return attachment, nil
+}
+
 func GenerateReport(Fingerprints []structs.InfoResult, POCs []structs.VulnerabilityInfo) string {
 	return defaultHeader() + reportBody(Fingerprints, POCs) + defaultFooter()
 }
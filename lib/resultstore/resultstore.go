@@ -0,0 +1,170 @@
+// 扫描结果在产生时由后端直接写入数据库并推送给前端，不依赖前端接收事件后回调保存
+package resultstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slack-wails/lib/gologger"
+	"slack-wails/lib/structs"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	batchSize     = 200                    // 缓存的结果达到该数量时立即写入
+	flushInterval = 500 * time.Millisecond // 结果较少时最长的写入间隔
+)
+
+// Store 批量写入结果，每次调用在同一个事务中完成
+type Store interface {
+	SaveFingerprints(results []structs.InfoResult) error
+	SaveVulnerabilities(results []structs.VulnerabilityInfo) error
+}
+
+var (
+	ctx   context.Context
+	store Store
+
+	mutex           sync.Mutex
+	fingerprints    []structs.InfoResult
+	vulnerabilities []structs.VulnerabilityInfo
+	timer           *time.Timer
+
+	flushMutex sync.Mutex // 保证批次按产生顺序写入
+
+	emit     = runtime.EventsEmit // 推送结果到前端
+	logError = gologger.Error     // 记录写入失败的结果
+)
+
+// Register 设置结果的存储位置，未设置时结果只推送给前端
+func Register(appCtx context.Context, s Store) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	ctx, store = appCtx, s
+}
+
+// Fingerprint 保存指纹结果并推送到前端，402/422 为云防护地址，只推送不保存
+func Fingerprint(appCtx context.Context, result structs.InfoResult) {
+	if result.StatusCode != 402 && result.StatusCode != 422 {
+		add(func() int {
+			fingerprints = append(fingerprints, result)
+			return len(fingerprints)
+		})
+	}
+	emit(appCtx, "webFingerScan", result)
+}
+
+type sourceKey struct{}
+
+// WithSource 标记该上下文中产生的漏洞结果来源，如凭据复用时为 reuse
+func WithSource(appCtx context.Context, source string) context.Context {
+	return context.WithValue(appCtx, sourceKey{}, source)
+}
+
+// Vulnerability 保存漏洞结果并推送到前端
+func Vulnerability(appCtx context.Context, result structs.VulnerabilityInfo) {
+	if source, ok := appCtx.Value(sourceKey{}).(string); ok && result.Source == "" {
+		result.Source = source
+	}
+	add(func() int {
+		vulnerabilities = append(vulnerabilities, result)
+		return len(vulnerabilities)
+	})
+	emit(appCtx, "nucleiResult", result)
+}
+
+func add(appendResult func() int) {
+	mutex.Lock()
+	if store == nil {
+		mutex.Unlock()
+		return
+	}
+	full := appendResult() >= batchSize
+	if !full && timer == nil {
+		timer = time.AfterFunc(flushInterval, Flush)
+	}
+	mutex.Unlock()
+	if full {
+		Flush()
+	}
+}
+
+// Flush 将缓存的结果写入数据库，扫描结束时调用以保证前端查询时结果已经落库
+func Flush() {
+	flushMutex.Lock()
+	defer flushMutex.Unlock()
+
+	mutex.Lock()
+	if timer != nil {
+		timer.Stop()
+		timer = nil
+	}
+	s, logCtx := store, ctx
+	fps, vulns := fingerprints, vulnerabilities
+	fingerprints, vulnerabilities = nil, nil
+	mutex.Unlock()
+
+	if s == nil {
+		return
+	}
+	save(logCtx, "fingerprint", fps, s.SaveFingerprints)
+	save(logCtx, "vulnerability", vulns, s.SaveVulnerabilities)
+}
+
+// save 批量写入失败时整个事务已回滚，逐条重新写入，只丢弃自身无法写入的结果
+func save[T any](logCtx context.Context, kind string, results []T, saveBatch func([]T) error) {
+	if len(results) == 0 {
+		return
+	}
+	if err := saveBatch(results); err == nil {
+		return
+	}
+	failed := 0
+	var lastErr error
+	for i := range results {
+		if err := saveBatch(results[i : i+1]); err != nil {
+			failed, lastErr = failed+1, err
+		}
+	}
+	if failed > 0 {
+		logError(logCtx, fmt.Sprintf("[sqlite] save %d of %d %s results failed: %v", failed, len(results), kind, lastErr))
+	}
+}
+
+// Statement 批量写入中的一条 SQL 语句
+type Statement struct {
+	Query string
+	Args  []interface{}
+}
+
+// ExecBatch 在同一个事务中执行全部语句，相同的语句只预编译一次，任意一条失败时回滚整个批次
+func ExecBatch(db *sql.DB, statements []Statement) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	prepared := make(map[string]*sql.Stmt)
+	defer func() {
+		for _, stmt := range prepared {
+			stmt.Close()
+		}
+	}()
+	for _, statement := range statements {
+		stmt, ok := prepared[statement.Query]
+		if !ok {
+			if stmt, err = tx.Prepare(statement.Query); err != nil {
+				tx.Rollback()
+				return err
+			}
+			prepared[statement.Query] = stmt
+		}
+		if _, err := stmt.Exec(statement.Args...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
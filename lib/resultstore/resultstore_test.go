@@ -0,0 +1,188 @@
+package resultstore
+
+import (
+	"context"
+	"database/sql"
+	"slack-wails/lib/structs"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type fakeStore struct {
+	mu      sync.Mutex
+	batches []int
+	vulns   int
+}
+
+func (s *fakeStore) SaveFingerprints(results []structs.InfoResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, len(results))
+	return nil
+}
+
+func (s *fakeStore) SaveVulnerabilities(results []structs.VulnerabilityInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vulns += len(results)
+	return nil
+}
+
+func (s *fakeStore) saved() ([]int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int{}, s.batches...), s.vulns
+}
+
+// useFakeStore 注册测试用的存储并屏蔽前端推送，结束时清空缓存与注册信息
+func useFakeStore(t *testing.T) *fakeStore {
+	store := &fakeStore{}
+	Register(context.Background(), store)
+	emitted := emit
+	emit = func(context.Context, string, ...interface{}) {}
+	t.Cleanup(func() {
+		Register(context.Background(), nil)
+		Flush()
+		emit = emitted
+	})
+	return store
+}
+
+func TestFlushOnBatchSize(t *testing.T) {
+	store := useFakeStore(t)
+	for i := 0; i < batchSize+1; i++ {
+		Fingerprint(context.Background(), structs.InfoResult{StatusCode: 200})
+	}
+	// 达到批次大小时同步写入，剩余的一条等待定时写入
+	if batches, _ := store.saved(); len(batches) != 1 || batches[0] != batchSize {
+		t.Fatalf("batches = %v, want [%d]", batches, batchSize)
+	}
+	// 云防护地址只推送不保存
+	Fingerprint(context.Background(), structs.InfoResult{StatusCode: 422})
+	Flush()
+	if batches, _ := store.saved(); len(batches) != 2 || batches[1] != 1 {
+		t.Fatalf("batches = %v, want [%d 1]", batches, batchSize)
+	}
+}
+
+func TestFlushOnTimer(t *testing.T) {
+	store := useFakeStore(t)
+	Vulnerability(context.Background(), structs.VulnerabilityInfo{ID: "CVE-2021-41773"})
+	if _, vulns := store.saved(); vulns != 0 {
+		t.Fatalf("vulnerability saved before the flush interval")
+	}
+	deadline := time.Now().Add(10 * flushInterval)
+	for time.Now().Before(deadline) {
+		if _, vulns := store.saved(); vulns == 1 {
+			return
+		}
+		time.Sleep(flushInterval / 10)
+	}
+	t.Fatal("vulnerability was not flushed by the timer")
+}
+
+func TestFlushOnShutdown(t *testing.T) {
+	store := useFakeStore(t)
+	Fingerprint(context.Background(), structs.InfoResult{StatusCode: 200})
+	Vulnerability(context.Background(), structs.VulnerabilityInfo{ID: "CVE-2021-41773"})
+	// 退出时调用 Flush 立即写入，不等待定时器
+	Flush()
+	if batches, vulns := store.saved(); len(batches) != 1 || vulns != 1 {
+		t.Fatalf("saved = %v, %d", batches, vulns)
+	}
+	mutex.Lock()
+	pending := timer != nil
+	mutex.Unlock()
+	if pending {
+		t.Error("timer should be stopped after Flush()")
+	}
+}
+
+// sqliteStore 通过 ExecBatch 写入指纹结果，URL 为空的结果违反约束导致整个批次回滚
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func (s *sqliteStore) SaveFingerprints(results []structs.InfoResult) error {
+	var statements []Statement
+	for _, result := range results {
+		statements = append(statements, Statement{Query: "INSERT INTO fingerprint (url) VALUES (?)", Args: []interface{}{result.URL}})
+	}
+	return ExecBatch(s.db, statements)
+}
+
+func (s *sqliteStore) SaveVulnerabilities(results []structs.VulnerabilityInfo) error {
+	return nil
+}
+
+func TestFlushWriteFailure(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("CREATE TABLE fingerprint (url TEXT NOT NULL CHECK (url != ''))"); err != nil {
+		t.Fatal(err)
+	}
+	Register(context.Background(), &sqliteStore{db: db})
+	emitted, logged := emit, logError
+	var logs []string
+	emit = func(context.Context, string, ...interface{}) {}
+	logError = func(_ context.Context, i interface{}) { logs = append(logs, i.(string)) }
+	t.Cleanup(func() {
+		Register(context.Background(), nil)
+		Flush()
+		emit, logError = emitted, logged
+	})
+
+	for _, u := range []string{"http://10.0.0.1", "", "http://10.0.0.2"} {
+		Fingerprint(context.Background(), structs.InfoResult{URL: u, StatusCode: 200})
+	}
+	Flush()
+	// 批次写入失败后逐条写入，只有违反约束的一条被丢弃
+	var count int
+	db.QueryRow("SELECT COUNT(*) FROM fingerprint").Scan(&count)
+	if count != 2 {
+		t.Fatalf("rows after a failed batch = %d, want 2", count)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("logged errors = %v, want 1", logs)
+	}
+}
+
+func TestExecBatchRollback(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("CREATE TABLE result (id TEXT PRIMARY KEY)"); err != nil {
+		t.Fatal(err)
+	}
+	insert := "INSERT INTO result (id) VALUES (?)"
+	err = ExecBatch(db, []Statement{
+		{Query: insert, Args: []interface{}{"a"}},
+		{Query: insert, Args: []interface{}{"b"}},
+		{Query: insert, Args: []interface{}{"a"}},
+	})
+	if err == nil {
+		t.Fatal("ExecBatch() should fail on a duplicate key")
+	}
+	var count int
+	db.QueryRow("SELECT COUNT(*) FROM result").Scan(&count)
+	if count != 0 {
+		t.Fatalf("rows after rollback = %d, want 0", count)
+	}
+	if err := ExecBatch(db, []Statement{{Query: insert, Args: []interface{}{"a"}}, {Query: insert, Args: []interface{}{"b"}}}); err != nil {
+		t.Fatalf("ExecBatch() returned an error: %v", err)
+	}
+	db.QueryRow("SELECT COUNT(*) FROM result").Scan(&count)
+	if count != 2 {
+		t.Fatalf("rows after commit = %d, want 2", count)
+	}
+}
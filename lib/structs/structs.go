@@ -154,6 +154,28 @@ type DatabaseConnection struct {
 	Notes      string
 }
 
+// 各模块获取到的有效凭据，用于凭据复用
+type Credential struct {
+	Id       int64
+	TaskId   string
+	Host     string
+	Port     int
+	Protocol string // 与爆破模块名称一致，如 ssh、mysql、http
+	Username string
+	Password string
+	Source   string // crack、reuse、jsfind、database、exploit
+	Created  string
+	Actions  []ActionResult // 登录后动作配置的执行结果
+}
+
+// 登录后执行的单条只读命令结果
+type ActionResult struct {
+	Name    string
+	Command string
+	Output  string
+	Error   string
+}
+
 type RowData struct {
 	Columns   []string
 	Rows      [][]interface{}
@@ -171,10 +193,56 @@ type WebscanOptions struct {
 	Tags                  []string
 	TemplateFiles         []string
 	SkipNucleiWithoutTags bool
-	GenerateLog4j2        bool   // 开启后会将所有目标添加 Generate-Log4j2 的指纹
-	AppendTemplateFolder  string // 追加模板文件夹
-	NetworkCard           string // 指定扫描网卡
-	CustomHeaders         string // 自定义请求头
+	GenerateLog4j2        bool     // 开启后会将所有目标添加 Generate-Log4j2 的指纹
+	AppendTemplateFolder  string   // 追加模板文件夹
+	NetworkCard           string   // 指定扫描网卡
+	CustomHeaders         string   // 自定义请求头
+	DefaultCredential     bool     // 根据指纹检测 Web 管理后台默认口令
+	WAFProbe              bool     // CNAME 未识别到 WAF 时发送攻击特征请求主动识别
+	TLSProbe              bool     // 计算 JARM 并探测弱协议与弱加密套件，每个 HTTPS 网站需要数十次握手
+	VHostDiscovery        bool     // 对 IP 形式的网站目标爆破 Host 头发现虚拟主机
+	VHostDomains          []string // 额外的虚拟主机候选域名或字典单词
+	VHostSubdomains       []string // 当前项目子域名收集结果，作为虚拟主机候选
+	VHostICPDomains       []string // 当前项目企业信息收集得到的 ICP 备案域名，作为虚拟主机候选
+	Crawl                 bool     // 爬取存活网站的链接、表单与参数
+	CrawlDepth            int      // 从首页开始跟随链接的层数
+	CrawlHeadless         bool     // 使用无头浏览器渲染页面并记录 XHR/fetch 请求
+	CrawlScope            string   // 爬取范围 host/domain
+}
+
+// 爬虫发现的请求，Params 为查询参数、表单字段与请求体中的参数名称
+type CrawlEndpoint struct {
+	URL         string
+	Method      string
+	Params      []string
+	Body        string
+	ContentType string
+	Source      string // 发现方式 link/form/xhr
+	Depth       int
+}
+
+type CrawlResult struct {
+	TaskId    string
+	Target    string
+	Endpoints []CrawlEndpoint
+}
+
+// 参数模糊测试的请求，URL 为实际请求的地址，Raw 为原始请求报文
+type FuzzRequest struct {
+	URL string
+	Raw string
+}
+
+// 虚拟主机发现结果，URL 为以域名访问的地址，Address 为实际请求的 IP 地址
+type VHostResult struct {
+	TaskId     string
+	URL        string
+	Address    string
+	Host       string
+	StatusCode int
+	Length     int
+	Title      string
+	Source     string // 候选来源 subdomain/cert/icp/wordlist/custom
 }
 
 type AntivirusResult struct {
@@ -495,6 +563,7 @@ type VulnerabilityInfo struct {
 	Response     string
 	ResponseTime string
 	Extract      string
+	Source       string // 结果来源，凭据复用命中时为 reuse，只用于区分写入凭据表的来源
 }
 
 type NucleiOption struct {
@@ -506,6 +575,10 @@ type NucleiOption struct {
 	TemplateFolders       []string
 	CustomHeaders         string
 	Proxy                 string
+	CVETemplates          []string      // 按指纹版本命中的 CVE 模板，追加扫描
+	SkipTemplates         []string      // 指纹版本不受影响的 CVE 模板，不再扫描
+	RateLimit             int           // 每秒请求数上限，0 为不限制，存在 WAF 的目标会降低
+	FuzzRequests          []FuzzRequest // 爬虫发现的带参数请求，使用 DAST 模板进行参数模糊测试
 }
 
 type InfoResult struct {
@@ -521,7 +594,104 @@ type InfoResult struct {
 	IsWAF        bool
 	WAF          string
 	Detect       string
-	Screenshot   string // 截图图片路径
+	Screenshot   string    // 截图图片路径
+	NTLMInfo     *NTLMInfo // NTLMSSP 质询中泄露的主机信息
+	NetInfo      *NetInfo  // NetBIOS 与 OXID 探测得到的主机名及网卡信息
+	ICSInfo      *ICSInfo  // 工控协议探测得到的设备信息
+
+	FingerprintDetails []FingerprintDetail // 提取到版本或对应 CPE 的指纹
+	ScreenshotHash     string              // 截图感知哈希，用于视觉聚类
+	Honeypot           *HoneypotInfo       // 蜜罐评分，没有任何可疑信号时为空
+	TLSInfo            *TLSInfo            // TLS 指纹与证书信息，非 TLS 服务时为空
+}
+
+// TLS 指纹、证书详情及弱协议/弱套件探测结果
+type TLSInfo struct {
+	JARM               string
+	JA3S               string
+	Version            string // 协商的协议版本
+	Cipher             string // 协商的加密套件
+	SubjectCN          string
+	SubjectDN          string
+	IssuerCN           string
+	IssuerDN           string
+	IssuerOrg          []string
+	SANs               []string // DNS 名称与 IP
+	NotBefore          string
+	NotAfter           string
+	KeyAlgorithm       string
+	KeySize            int
+	SignatureAlgorithm string
+	SelfSigned         bool
+	Expired            bool
+	WeakProtocols      []string
+	WeakCiphers        []string
+}
+
+// 蜜罐置信度评分及依据
+type HoneypotInfo struct {
+	Score   int // 0-100
+	Reasons []string
+}
+
+// 本地资源服务地址，访问 URL/screenshot/文件名?token=Token
+type AssetServer struct {
+	URL   string
+	Token string
+	Roots map[string]string // 挂载名称与本地目录
+}
+
+// 视觉相似的截图聚类，代表图取首个成员
+type ScreenshotCluster struct {
+	Hash       string   // 代表图的感知哈希
+	Screenshot string   // 代表图路径
+	URL        string   // 代表图对应的链接
+	Title      string   // 代表图对应的标题
+	URLs       []string // 全部成员链接，包含代表图
+}
+
+// NTLMSSP CHALLENGE 消息中 TargetInfo 与 Version 字段解析出的主机信息
+type NTLMInfo struct {
+	Protocol            string // 获取信息的协议 smb/rdp/http/mssql/smtp/imap
+	NetBIOSComputerName string
+	NetBIOSDomainName   string
+	DNSComputerName     string
+	DNSDomainName       string
+	DNSTreeName         string // 林名称
+	OSVersion           string // 例如 Windows 10.0 Build 17763
+}
+
+// NetBIOS 名称表与 OXID ServerAlive2 字符串绑定中解析出的主机信息
+type NetInfo struct {
+	ComputerName     string
+	Workgroup        string
+	MAC              string
+	DomainController bool     // 名称表中存在 <1C> 组名
+	Hostname         string   // OXID 返回的主机名
+	Addresses        []string // OXID 返回的全部网卡地址，多于一个即为多网卡主机
+}
+
+// 工控协议只读探测得到的设备信息
+type ICSInfo struct {
+	Protocol string // modbus、s7、bacnet、enip、dnp3、iec-104、fins、fox
+	Vendor   string
+	Model    string
+	Firmware string
+	Serial   string
+	Extra    map[string]string // 协议特有字段，如 S7 的模块类型、DNP3 的链路地址
+}
+
+// 指纹的版本、CPE 及离线 CVE 数据集中受影响的漏洞
+type FingerprintDetail struct {
+	Name    string
+	Version string
+	CPE     string // 未提取到版本时版本位为 *
+	CVEs    []FingerprintCVE
+}
+
+type FingerprintCVE struct {
+	ID       string
+	Severity string
 }
 
 type WebReport struct {
@@ -543,6 +713,18 @@ type CompanyInfo struct {
 	OfficialAccounts []OfficialAccount // 公众号
 }
 
+// 口令字典生成选项
+type PasswordDictOptions struct {
+	Companies    []CompanyInfo // 企业信息收集结果，提取公司名称、商标与备案域名
+	Keywords     []string      // 公司简称、项目名等关键词
+	Domains      []string
+	Names        []string // 人员姓名
+	StartYear    int
+	EndYear      int
+	KeyboardWalk bool // 是否包含键盘序口令
+	Limit        int
+}
+
 type App struct {
 	CityID           int    `json:"cityId"`
 	CountyID         int    `json:"countyId"`
@@ -698,3 +880,125 @@ type RawResponse struct {
 	Response     string
 	ResponseTime int64
 }
+
+// 域信息收集结果
+type ADReconResult struct {
+	Domain    ADDomain
+	Users     []ADUser
+	Groups    []ADGroup
+	Computers []ADComputer
+	GPOs      []ADGPO
+	Trusts    []ADTrust
+}
+
+type ADDomain struct {
+	Name                string // DNS 域名，如 corp.local
+	DistinguishedName   string
+	SID                 string
+	DomainController    string
+	FunctionalLevel     string
+	MachineAccountQuota int
+	PasswordPolicy      ADPasswordPolicy
+	GPOLinks            []ADGPOLink // 链接到域根的 GPO
+}
+
+type ADGPOLink struct {
+	Name              string
+	DistinguishedName string
+	Enforced          bool
+}
+
+type ADPasswordPolicy struct {
+	MinLength            int
+	HistoryLength        int
+	MaxAge               string
+	MinAge               string
+	LockoutThreshold     int
+	LockoutDuration      string
+	Complexity           bool
+	ReversibleEncryption bool
+}
+
+type ADUser struct {
+	Name                    string
+	DisplayName             string
+	DistinguishedName       string
+	SID                     string
+	PrimaryGroupSID         string
+	Description             string
+	Enabled                 bool
+	PasswordNeverExpires    bool
+	PasswordNotRequired     bool
+	DontRequirePreauth      bool // 可进行 AS-REP Roasting
+	AdminCount              bool
+	Sensitive               bool // 敏感账号，不可被委派
+	UnconstrainedDelegation bool
+	TrustedToAuth           bool     // 约束委派且允许协议转换
+	AllowedToDelegate       []string // 约束委派的目标 SPN
+	SPNs                    []string // 存在 SPN 时可进行 Kerberoasting
+	SIDHistory              []string
+	MemberOf                []string // 所属组名称，包含嵌套组
+	PasswordLastSet         string
+	LastLogon               string
+}
+
+type ADGroup struct {
+	Name              string
+	DistinguishedName string
+	SID               string
+	Description       string
+	AdminCount        bool
+	Members           []ADMember // 直接成员
+}
+
+type ADMember struct {
+	Name string
+	SID  string
+	Type string // User、Group、Computer，无法解析时为 Base
+}
+
+type ADComputer struct {
+	Name                    string
+	DNSHostName             string
+	DistinguishedName       string
+	SID                     string
+	PrimaryGroupSID         string
+	OperatingSystem         string
+	OperatingSystemVersion  string
+	Enabled                 bool
+	DomainController        bool
+	UnconstrainedDelegation bool
+	TrustedToAuth           bool
+	AllowedToDelegate       []string
+	AllowedToAct            []string // 基于资源的约束委派，可委派到本机的主体 SID
+	SPNs                    []string
+	LastLogon               string
+}
+
+type ADGPO struct {
+	Name              string
+	GUID              string // 策略名称 {GUID}
+	ObjectGUID        string
+	DistinguishedName string
+	Path              string // SYSVOL 路径
+}
+
+type ADTrust struct {
+	Partner      string
+	SID          string
+	Direction    string // Disabled、Inbound、Outbound、Bidirectional
+	Type         string // ParentChild、CrossLink、Forest、External、Unknown
+	Transitive   bool
+	SIDFiltering bool
+}
+
+// 导入第三方指纹库的统计结果
+type FingerprintImportResult struct {
+	Converted      int // 转换并校验通过的规则数
+	Skipped        int // 无法转换或校验失败的规则数
+	Added          int // 新增到指纹库的规则数
+	Duplicates     int // 与已有规则重复的规则数
+	NewProducts    int
+	MergedProducts int // 已存在并合并了新规则的产品数
+	ActivePaths    int // 新增的主动探测路径数
+}
@@ -0,0 +1,73 @@
+// 扫描结果与凭据之间的转换，供扫描模块与结果存储共用
+package credutil
+
+import (
+	"slack-wails/lib/structs"
+	"slack-wails/lib/utils/netutil"
+	"strings"
+)
+
+// 凭据来源
+const (
+	SourceCrack = "crack" // 爆破与默认口令检测
+	SourceReuse = "reuse" // 凭据复用命中
+)
+
+// 仅校验密码的协议，复用时忽略用户名
+var passwordOnly = map[string]bool{
+	"vnc":   true,
+	"redis": true,
+}
+
+// 漏洞名称前缀与爆破模块名称不一致的情况
+var credentialProtocolAlias = map[string]string{
+	"postgres": "postgresql",
+}
+
+// FromVulnerability 从爆破模块或默认口令检测的结果中提取凭据，来源默认为 crack，其他结果返回 false
+func FromVulnerability(result structs.VulnerabilityInfo) (structs.Credential, bool) {
+	var protocol string
+	switch {
+	case strings.HasSuffix(result.ID, " weak password"):
+		protocol = strings.ToLower(strings.TrimSuffix(result.ID, " weak password"))
+	case result.ID == "default-login":
+		protocol, _, _ = netutil.SplitTarget(result.URL)
+	default:
+		return structs.Credential{}, false
+	}
+	if alias, ok := credentialProtocolAlias[protocol]; ok {
+		protocol = alias
+	}
+	scheme, host, port := netutil.SplitTarget(result.URL)
+	if scheme == "http" || scheme == "https" {
+		protocol = scheme
+	}
+	cred := structs.Credential{
+		TaskId:   result.TaskId,
+		Host:     host,
+		Port:     port,
+		Protocol: protocol,
+		Source:   SourceCrack,
+	}
+	if result.Source != "" {
+		cred.Source = result.Source
+	}
+	if passwordOnly[protocol] {
+		cred.Password = result.Extract
+	} else {
+		user, pass, ok := strings.Cut(result.Extract, "/")
+		if !ok {
+			return structs.Credential{}, false
+		}
+		cred.Username, cred.Password = user, pass
+	}
+	if cred.Password == "" && cred.Username == "" {
+		return structs.Credential{}, false
+	}
+	return cred, true
+}
+
+// PasswordOnly 协议是否仅校验密码
+func PasswordOnly(protocol string) bool {
+	return passwordOnly[protocol]
+}
@@ -0,0 +1,46 @@
+package credutil
+
+import (
+	"reflect"
+	"slack-wails/lib/structs"
+	"testing"
+)
+
+func TestFromVulnerability(t *testing.T) {
+	cases := []struct {
+		result structs.VulnerabilityInfo
+		want   structs.Credential
+		ok     bool
+	}{
+		{
+			result: structs.VulnerabilityInfo{ID: "postgres weak password", URL: "10.0.0.1:5432", Extract: "postgres/p@ss/word"},
+			want:   structs.Credential{Host: "10.0.0.1", Port: 5432, Protocol: "postgresql", Username: "postgres", Password: "p@ss/word", Source: "crack"},
+			ok:     true,
+		},
+		{
+			result: structs.VulnerabilityInfo{ID: "redis weak password", URL: "10.0.0.2:6379", Extract: "123456"},
+			want:   structs.Credential{Host: "10.0.0.2", Port: 6379, Protocol: "redis", Password: "123456", Source: "crack"},
+			ok:     true,
+		},
+		{
+			result: structs.VulnerabilityInfo{ID: "default-login", URL: "https://10.0.0.3/login", Extract: "admin/admin"},
+			want:   structs.Credential{Host: "10.0.0.3", Port: 443, Protocol: "https", Username: "admin", Password: "admin", Source: "crack"},
+			ok:     true,
+		},
+		{
+			result: structs.VulnerabilityInfo{ID: "ssh weak password", URL: "10.0.0.4:22", Extract: "root/toor", Source: SourceReuse},
+			want:   structs.Credential{Host: "10.0.0.4", Port: 22, Protocol: "ssh", Username: "root", Password: "toor", Source: "reuse"},
+			ok:     true,
+		},
+		{
+			result: structs.VulnerabilityInfo{ID: "redis unauthorized", URL: "10.0.0.2:6379"},
+			ok:     false,
+		},
+	}
+	for _, c := range cases {
+		got, ok := FromVulnerability(c.result)
+		if ok != c.ok || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("FromVulnerability(%s) = %+v, %v", c.result.ID, got, ok)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 func PrettyURL(url string) string {
@@ -203,3 +204,45 @@ func GetPort(u *url.URL) int {
 	port, _ := strconv.Atoi(u.Port())
 	return port
 }
+
+// 将文本分割成 shingle（n-gram 片段），用于计算相似度
+func tokenize(text string, n int) map[string]struct{} {
+	// 预处理文本，去除空格和标点
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return r
+		}
+		return ' '
+	}, text)
+	words := strings.Fields(cleaned)
+
+	// 生成 n-gram 片段
+	tokens := make(map[string]struct{})
+	for i := 0; i < len(words)-n+1; i++ {
+		token := strings.Join(words[i:i+n], " ")
+		tokens[token] = struct{}{}
+	}
+	return tokens
+}
+
+// JaccardSimilarity 按 3-gram 计算两段文本的 Jaccard 相似度
+func JaccardSimilarity(text1, text2 string) float64 {
+	set1 := tokenize(text1, 3) // 3-gram
+	set2 := tokenize(text2, 3)
+
+	// 计算交集大小
+	intersection := 0
+	for token := range set1 {
+		if _, exists := set2[token]; exists {
+			intersection++
+		}
+	}
+
+	// 计算并集大小
+	union := len(set1) + len(set2) - intersection
+
+	if union == 0 {
+		return 0.0
+	}
+	return float64(intersection) / float64(union)
+}
@@ -0,0 +1,35 @@
+package netutil
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var defaultSchemePorts = map[string]int{
+	"http":  80,
+	"https": 443,
+}
+
+// SplitTarget 将 scheme://host:port/path 或 host:port 形式的目标拆分为协议、主机与端口
+func SplitTarget(target string) (scheme, host string, port int) {
+	if strings.Contains(target, "://") {
+		u, err := url.Parse(target)
+		if err != nil {
+			return "", target, 0
+		}
+		scheme = strings.ToLower(u.Scheme)
+		host = u.Hostname()
+		if port, err = strconv.Atoi(u.Port()); err != nil {
+			port = defaultSchemePorts[scheme]
+		}
+		return
+	}
+	h, p, err := net.SplitHostPort(target)
+	if err != nil {
+		return "", target, 0
+	}
+	port, _ = strconv.Atoi(p)
+	return "", h, port
+}
@@ -41,6 +41,7 @@ func main() {
 			exp.Startup(ctx)
 		},
 		OnBeforeClose: app.BeforeClose,
+		OnShutdown:    app.Shutdown,
 		DragAndDrop:   DragAndDropOptions(),
 		OnDomReady: func(ctx context.Context) {
 			runtime.OnFileDrop(ctx, func(x, y int, paths []string) {
@@ -14,6 +14,8 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slack-wails/core/adrecon"
+	"slack-wails/core/crawler"
 	"slack-wails/core/dirsearch"
 	"slack-wails/core/dumpall"
 	"slack-wails/core/info/icp"
@@ -24,10 +26,15 @@ import (
 	"slack-wails/core/repeater"
 	"slack-wails/core/space"
 	"slack-wails/core/subdomain"
+	"slack-wails/core/waf"
 	"slack-wails/core/webscan"
+	"slack-wails/core/wordlist"
+	"slack-wails/lib/assets"
+	"slack-wails/lib/browser"
 	"slack-wails/lib/control"
 	"slack-wails/lib/gologger"
 	"slack-wails/lib/gomessage"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"slack-wails/lib/utils"
 	"slack-wails/lib/utils/arrayutil"
@@ -49,6 +56,8 @@ type App struct {
 	ctx              context.Context
 	webfingerFile    string
 	activefingerFile string
+	webcredFile      string
+	cveFile          string
 	cdnFile          string
 	qqwryFile        string
 	templateDir      string
@@ -62,6 +71,8 @@ func NewApp() *App {
 	return &App{
 		webfingerFile:    home + "/slack/config/webfinger.yaml",
 		activefingerFile: home + "/slack/config/dir.yaml",
+		webcredFile:      home + "/slack/config/webcred.yaml",
+		cveFile:          home + "/slack/config/cve.yaml",
 		cdnFile:          home + "/slack/config/cdn.yaml",
 		qqwryFile:        home + "/slack/config/qqwry.dat",
 		templateDir:      home + "/slack/config/pocs",
@@ -76,6 +87,35 @@ func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
+// 退出时关闭共享的无头浏览器与本地资源服务
+func (a *App) Shutdown(ctx context.Context) {
+	resultstore.Flush()
+	browser.Shared().Close()
+	assets.Shared().Close()
+}
+
+// 设置本地资源服务端口，端口变化时在下次访问时重新监听
+func (a *App) ConfigureAssetServer(port int) {
+	assets.Shared().Configure(port)
+}
+
+// 启动本地资源服务并返回地址与访问令牌，端口被占用时返回错误
+func (a *App) AssetServer() (structs.AssetServer, error) {
+	url, err := assets.Shared().Start()
+	if err != nil {
+		gologger.Error(a.ctx, err)
+		return structs.AssetServer{}, err
+	}
+	return structs.AssetServer{URL: url, Token: assets.Shared().Token(), Roots: assets.Shared().Roots()}, nil
+}
+
+// 设置共享无头浏览器的标签页上限与页面加载后等待的选择器，选择器为空时等待网络空闲
+func (a *App) ConfigureBrowser(tabs int, waitSelector string) {
+	options := browser.Shared().Options()
+	options.Tabs, options.WaitSelector = tabs, waitSelector
+	browser.Shared().Configure(options)
+}
+
 // 返回 true 将导致应用程序继续，false 将继续正常关闭
 func (a *App) BeforeClose(ctx context.Context) (prevent bool) {
 	if !webscan.IsRunning {
@@ -469,6 +509,7 @@ func (a *App) SpaceGetPort(ip string) []float64 {
 
 func (a *App) NewTcpScanner(taskId string, specialTargets []string, ips []string, ports []int, thread, timeout int, proxyURL string) {
 	ctrlCtx, _ := control.GetScanContext(control.Portscan) // 标识任务
+	defer resultstore.Flush()
 	addresses := make(chan portscan.Address)
 
 	go func() {
@@ -495,9 +536,44 @@ func (a *App) NewTcpScanner(taskId string, specialTargets []string, ips []string
 // 端口暴破
 func (a *App) NewCrackScanenr(taskId, host string, usernames, passwords []string) {
 	ctrlCtx, _ := control.GetScanContext(control.Crack) // 标识任务
+	defer resultstore.Flush()
 	portscan.Runner(a.ctx, ctrlCtx, taskId, host, usernames, passwords)
 }
 
+// 凭据复用，将已获取的凭据重放到其他已发现的服务
+func (a *App) NewCredentialReuse(taskId string, targets []string, creds []structs.Credential) {
+	ctrlCtx, _ := control.GetScanContext(control.Crack) // 标识任务
+	defer resultstore.Flush()
+	portscan.CredentialReuse(a.ctx, ctrlCtx, taskId, targets, creds)
+}
+
+// 使用域账号通过 LDAP 收集域信息，host 为 ip:port
+func (a *App) ADRecon(host, username, password string) *structs.ADReconResult {
+	result, err := adrecon.Collect(a.ctx, host, username, password)
+	if err != nil {
+		gologger.Error(a.ctx, fmt.Sprintf("[adrecon] %s %v", host, err))
+		return nil
+	}
+	return result
+}
+
+// 导出域信息，format 为 json 或 bloodhound
+func (a *App) ExportADRecon(result structs.ADReconResult, filename, format string) bool {
+	if format == "bloodhound" {
+		if err := adrecon.ExportBloodHound(&result, filename); err != nil {
+			gologger.Error(a.ctx, err)
+			return false
+		}
+		return true
+	}
+	return fileutil.SaveJsonWithFormat(a.ctx, filename, result)
+}
+
+// 根据公司、域名、姓名等目标信息生成口令字典
+func (a *App) GeneratePasswordDict(options structs.PasswordDictOptions) []string {
+	return wordlist.Generate(options)
+}
+
 // fofa
 
 func (a *App) FofaTips(query string) *structs.TipsResult {
@@ -540,12 +616,24 @@ func (a *App) InitRule(appendTemplateFolder string) bool {
 		TemplateFolders:     templateFolders,
 		ActiveRuleFile:      a.activefingerFile,
 		FingerprintRuleFile: a.webfingerFile,
+		WebCredentialFile:   a.webcredFile,
+		CVEFile:             a.cveFile,
 	}
 	return config.InitAll(a.ctx)
 }
 
 // webscan
 
+// 导入第三方指纹库并合并到 webfinger.yaml 与 dir.yaml，format 为 ehole、fingerprinthub、wappalyzer、nuclei 或 tidefinger
+func (a *App) ImportFingerprints(format, source string) *structs.FingerprintImportResult {
+	result, err := webscan.ImportFingerprints(format, source, a.webfingerFile, a.activefingerFile)
+	if err != nil {
+		gologger.Error(a.ctx, fmt.Sprintf("[fingerprint] import %s %v", source, err))
+		return nil
+	}
+	return result
+}
+
 func (a *App) FingerprintList() []string {
 	var fingers []string
 	for _, item := range webscan.FingerprintDB {
@@ -554,14 +642,24 @@ func (a *App) FingerprintList() []string {
 	return fingers
 }
 
-// 多线程 Nuclei 扫描，由于Nucli的设计问题，多线程无法调用代理，否则会导致扫描失败
+// 多线程 Nuclei 扫描时代理只能在创建引擎时全局设置，单个目标的扫描不能再指定代理
 func (a *App) NewWebScanner(taskId string, options structs.WebscanOptions, proxyURL string, threadSafe bool) {
 	ctrlCtx, cancel := control.GetScanContext(control.Webscan) // 标识任务
 	defer cancel()
+	// 结果在扫描过程中批量写入，结束时写入剩余的结果，保证前端查询时已经落库
+	defer resultstore.Flush()
 	webscan.IsRunning = true
+	// WAF 识别与爬虫结果只在本次扫描及之后的目录扫描、JS 接口分析中使用，避免沿用上一个目标的结果
+	waf.Reset()
+	crawler.Reset()
 	gologger.Info(a.ctx, fmt.Sprintf("Load web scanner, targets number: %d", len(options.Target)))
 	gologger.Info(a.ctx, "Fingerscan is running ...")
 
+	// 截图与指纹动态计算跟随扫描代理
+	browserOptions := browser.Shared().Options()
+	browserOptions.Proxy = proxyURL
+	browser.Shared().Configure(browserOptions)
+
 	engine := webscan.NewWebscanEngine(a.ctx, taskId, proxyURL, options)
 	if engine == nil {
 		gologger.Error(a.ctx, "Init fingerscan engine failed")
@@ -575,6 +673,22 @@ func (a *App) NewWebScanner(taskId string, options structs.WebscanOptions, proxy
 		engine.ActiveFingerScan(ctrlCtx)
 	}
 
+	// 爬取存活网站，发现的带参数请求交由 nuclei 进行参数模糊测试
+	if options.Crawl && ctrlCtx.Err() == nil {
+		engine.CrawlScan(ctrlCtx)
+	}
+
+	// 证书 SAN 中发现的新域名，交由前端添加为后续扫描目标
+	if domains := engine.CandidateDomains(); len(domains) > 0 {
+		gologger.Info(a.ctx, fmt.Sprintf("Found %d candidate domains from certificate SANs", len(domains)))
+		runtime.EventsEmit(a.ctx, "webscanCandidateDomains", domains)
+	}
+
+	// 根据指纹尝试 Web 管理后台默认口令
+	if options.DefaultCredential && ctrlCtx.Err() == nil {
+		engine.DefaultCredentialScan(ctrlCtx)
+	}
+
 	if options.CallNuclei && ctrlCtx.Err() == nil {
 		gologger.Info(a.ctx, "Init nuclei engine, vulnerability scan is running ...")
 
@@ -586,17 +700,35 @@ func (a *App) NewWebScanner(taskId string, options structs.WebscanOptions, proxy
 
 		// 提取所有目标和标签
 		fpm := engine.URLWithFingerprintMap()
+		details := engine.URLWithFingerprintDetails()
 		allOptions := []structs.NucleiOption{}
 		for target, tags := range fpm {
+			cveTemplates, skipTemplates := webscan.CVETemplates(details[target])
+			// 存在 WAF 的目标降低请求速率
+			var rateLimit int
+			if name, ok := waf.Detected(target); ok {
+				rateLimit = waf.ThrottledRateLimit
+				gologger.Info(a.ctx, fmt.Sprintf("[nuclei] %s is protected by %s, rate limit %d/s", target, name, rateLimit))
+			}
+			// 虚拟主机无法通过 DNS 解析，改为请求 IP 并指定 Host 头
+			nucleiURL, customHeaders := target, options.CustomHeaders
+			if ipURL, host, ok := engine.VHostAddress(target); ok {
+				nucleiURL = ipURL
+				customHeaders = strings.TrimSpace("Host: " + host + "\n" + customHeaders)
+			}
 			allOptions = append(allOptions, structs.NucleiOption{
-				URL:                   target,
+				URL:                   nucleiURL,
 				Tags:                  arrayutil.RemoveDuplicates(tags),
 				TemplateFile:          options.TemplateFiles,
 				SkipNucleiWithoutTags: options.SkipNucleiWithoutTags,
 				TemplateFolders:       allTemplateFolders,
 				CustomTags:            options.Tags,
-				CustomHeaders:         options.CustomHeaders,
+				CustomHeaders:         customHeaders,
 				Proxy:                 proxyURL,
+				CVETemplates:          cveTemplates,
+				SkipTemplates:         skipTemplates,
+				RateLimit:             rateLimit,
+				FuzzRequests:          engine.FuzzRequests(target),
 			})
 		}
 		counts := len(allOptions)
@@ -622,6 +754,11 @@ func (a *App) GetFingerPocMap() map[string][]string {
 	return webscan.WorkFlowDB
 }
 
+// 按截图感知哈希聚类，threshold 为汉明距离阈值，小于 0 时使用默认值
+func (a *App) ClusterScreenshots(results []structs.InfoResult, threshold int) []structs.ScreenshotCluster {
+	return webscan.ClusterScreenshots(results, threshold)
+}
+
 // hunter
 
 func (a *App) HunterTips(query string) *structs.HunterTips {
@@ -663,10 +800,19 @@ func (a *App) ExtractAllJSLink(url string) []string {
 }
 
 func (a *App) JSFind(target, prefixJsURL string, jsLinks, blackDomainList []string) structs.FindSomething {
-	return jsfind.Scan(a.ctx, target, prefixJsURL, jsLinks, blackDomainList)
+	fs := jsfind.Scan(a.ctx, target, prefixJsURL, jsLinks, blackDomainList)
+	for _, cred := range jsfind.ExtractCredentials(target, fs.Sensitive) {
+		runtime.EventsEmit(a.ctx, "credential", cred)
+	}
+	return fs
 }
 
 func (a *App) AnalyzeAPI(homeURL, baseURL string, apiList []string, headers, lowPrivilegeHeaders map[string]string, authentication []string, highRiskRouter []string) {
+	// 合并网站扫描时爬虫发现的接口
+	if crawled := crawler.APIs(baseURL, crawler.Endpoints(homeURL)); len(crawled) > 0 {
+		runtime.EventsEmit(a.ctx, "jsfindlog", fmt.Sprintf("[+] 已合并爬虫发现的接口 %d 个", len(crawled)))
+		apiList = arrayutil.RemoveDuplicates(append(apiList, crawled...))
+	}
 	options := structs.JSFindOptions{
 		HomeURL:             homeURL,
 		BaseURL:             baseURL,
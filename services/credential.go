@@ -0,0 +1,135 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slack-wails/lib/gologger"
+	"slack-wails/lib/structs"
+	"strings"
+	"time"
+)
+
+var (
+	credentialUserColumn = regexp.MustCompile(`(?i)^(user_?name|user|login_?name|account|login|uname)$`)
+	credentialPassColumn = regexp.MustCompile(`(?i)^(pass_?word|passwd|pwd|pass)$`)
+	// md5、sha1、sha256 以及 $2a$、$6$ 等形式的哈希不具备复用价值
+	credentialHashValue = regexp.MustCompile(`^([0-9a-fA-F]{32}|[0-9a-fA-F]{40}|[0-9a-fA-F]{64}|\$.+\$.+)$`)
+)
+
+// 添加凭据，同一服务下相同的账号密码只保留一条
+func (d *Database) AddCredential(cred structs.Credential) bool {
+	return d.ExecSqlStatement(credentialInsertStmt, credentialArgs(cred)...)
+}
+
+const credentialInsertStmt = "INSERT OR IGNORE INTO Credentials (task_id, host, port, protocol, username, password, source, created) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+
+func credentialArgs(cred structs.Credential) []interface{} {
+	if cred.Created == "" {
+		cred.Created = time.Now().Format("2006-01-02 15:04:05")
+	}
+	return []interface{}{cred.TaskId, cred.Host, cred.Port, cred.Protocol, cred.Username, cred.Password, cred.Source, cred.Created}
+}
+
+// 检索全部凭据，凭据复用需要跨任务使用
+func (d *Database) RetrieveCredentials() []structs.Credential {
+	rows, err := d.DB.Query("SELECT id, task_id, host, port, protocol, username, password, source, created, actions FROM Credentials ORDER BY id DESC")
+	if err != nil {
+		return []structs.Credential{}
+	}
+	defer rows.Close()
+	var creds []structs.Credential
+	for rows.Next() {
+		var (
+			cred    structs.Credential
+			actions *string
+		)
+		err = rows.Scan(&cred.Id, &cred.TaskId, &cred.Host, &cred.Port, &cred.Protocol, &cred.Username, &cred.Password, &cred.Source, &cred.Created, &actions)
+		if err != nil {
+			gologger.Debug(d.ctx, err)
+			continue
+		}
+		if actions != nil && *actions != "" {
+			json.Unmarshal([]byte(*actions), &cred.Actions)
+		}
+		creds = append(creds, cred)
+	}
+	return creds
+}
+
+// 保存凭据登录后动作配置的执行结果，重复执行时覆盖
+func (d *Database) UpdateCredentialActions(id int64, results []structs.ActionResult) bool {
+	b, err := json.Marshal(results)
+	if err != nil {
+		return false
+	}
+	return d.ExecSqlStatement("UPDATE Credentials SET actions = ? WHERE id = ?", string(b), id)
+}
+
+func (d *Database) RemoveCredential(id int64) bool {
+	return d.ExecSqlStatement("DELETE FROM Credentials WHERE id = ?", id)
+}
+
+func (d *Database) ClearCredentials() bool {
+	return d.ExecSqlStatement("DELETE FROM Credentials")
+}
+
+// 数据库管理中连接成功的账号，协议名称与爆破模块保持一致
+func databaseCredential(info structs.DatabaseConnection, username, password string) structs.Credential {
+	protocol := info.Scheme
+	if protocol == "postgres" {
+		protocol = "postgresql"
+	}
+	return structs.Credential{
+		Host:     info.Host,
+		Port:     info.Port,
+		Protocol: protocol,
+		Username: username,
+		Password: password,
+		Source:   "database",
+	}
+}
+
+// 从采样的表数据中提取账号密码列，明文密码记录为凭据
+func (d *Database) harvestRowCredentials(columns []string, rows [][]interface{}) {
+	if d.ConnectionInfo == nil {
+		return
+	}
+	userIndex, passIndex := -1, -1
+	for i, column := range columns {
+		switch {
+		case userIndex == -1 && credentialUserColumn.MatchString(column):
+			userIndex = i
+		case passIndex == -1 && credentialPassColumn.MatchString(column):
+			passIndex = i
+		}
+	}
+	if userIndex == -1 || passIndex == -1 {
+		return
+	}
+	for _, row := range rows {
+		if userIndex >= len(row) || passIndex >= len(row) {
+			continue
+		}
+		username := strings.TrimSpace(columnString(row[userIndex]))
+		password := strings.TrimSpace(columnString(row[passIndex]))
+		if username == "" || password == "" || credentialHashValue.MatchString(password) {
+			continue
+		}
+		cred := databaseCredential(*d.ConnectionInfo, username, password)
+		// 表内账号通常属于业务系统，协议未知，复用时尝试所有服务
+		cred.Protocol = ""
+		d.AddCredential(cred)
+	}
+}
+
+func columnString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -7,12 +7,15 @@ import (
 	"github.com/xuri/excelize/v2"
 
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"slack-wails/lib/gologger"
 	"slack-wails/lib/report"
+	"slack-wails/lib/resultstore"
 	"slack-wails/lib/structs"
 	"slack-wails/lib/utils"
+	"slack-wails/lib/utils/credutil"
 	"slack-wails/lib/utils/fileutil"
 	"strings"
 	"sync"
@@ -22,16 +25,18 @@ import (
 )
 
 type Database struct {
-	ctx           context.Context
-	DB            *sql.DB // 系统数据库
-	lock          sync.RWMutex
-	OtherDatabase *sql.DB                     // 数据库信息采集时的连接池
-	MongoClient   *mongo.Client               // mongodb连接池
-	PostgresInfo  *structs.DatabaseConnection // 用于临时存储postgres数据库连接信息，方便其他方法调用
+	ctx            context.Context
+	DB             *sql.DB // 系统数据库
+	lock           sync.RWMutex
+	OtherDatabase  *sql.DB                     // 数据库信息采集时的连接池
+	MongoClient    *mongo.Client               // mongodb连接池
+	PostgresInfo   *structs.DatabaseConnection // 用于临时存储postgres数据库连接信息，方便其他方法调用
+	ConnectionInfo *structs.DatabaseConnection // 当前采集的数据库连接，用于关联采集到的凭据
 }
 
 func (d *Database) Startup(ctx context.Context) {
 	d.ctx = ctx
+	resultstore.Register(ctx, &resultWriter{d: d})
 }
 
 func NewDatabase() *Database {
@@ -92,6 +97,7 @@ func (d *Database) CreateTable() bool {
         CREATE TABLE IF NOT EXISTS scanTask ( task_id TEXT PRIMARY KEY, task_name TEXT, targets TEXT, failed INTEGER, vulnerability INTEGER );
         CREATE TABLE IF NOT EXISTS FingerprintInfo ( task_id TEXT, url TEXT, status INTEGER, length INTEGER, title TEXT, detect TEXT, is_waf INTEGER, waf TEXT, fingerprints TEXT, screenshot TEXT, host TEXT, scheme TEXT, port INTEGER );
         CREATE TABLE IF NOT EXISTS VulnerabilityInfo ( task_id TEXT, template_id TEXT, vuln_name TEXT, protocol TEXT, severity TEXT, vuln_url TEXT, extract TEXT, request TEXT, response TEXT, description TEXT, reference TEXT, response_time TEXT );
+        CREATE TABLE IF NOT EXISTS Credentials ( id INTEGER PRIMARY KEY AUTOINCREMENT, task_id TEXT, host TEXT, port INTEGER, protocol TEXT, username TEXT, password TEXT, source TEXT, created TEXT, actions TEXT, UNIQUE(host, port, protocol, username, password) );
     `)
 	if err != nil {
 		gologger.Debug(d.ctx, fmt.Sprintf("[sqlite] create table: %s", err))
@@ -122,12 +128,60 @@ func (d *Database) CreateTable() bool {
 			return false
 		}
 	}
+	if !columnExists(d.DB, "FingerprintInfo", "ntlm_info") {
+		_, err := d.DB.Exec(`ALTER TABLE FingerprintInfo ADD COLUMN ntlm_info TEXT`)
+		if err != nil {
+			return false
+		}
+	}
+	if !columnExists(d.DB, "FingerprintInfo", "net_info") {
+		_, err := d.DB.Exec(`ALTER TABLE FingerprintInfo ADD COLUMN net_info TEXT`)
+		if err != nil {
+			return false
+		}
+	}
+	if !columnExists(d.DB, "FingerprintInfo", "ics_info") {
+		_, err := d.DB.Exec(`ALTER TABLE FingerprintInfo ADD COLUMN ics_info TEXT`)
+		if err != nil {
+			return false
+		}
+	}
+	if !columnExists(d.DB, "FingerprintInfo", "fingerprint_details") {
+		_, err := d.DB.Exec(`ALTER TABLE FingerprintInfo ADD COLUMN fingerprint_details TEXT`)
+		if err != nil {
+			return false
+		}
+	}
+	if !columnExists(d.DB, "FingerprintInfo", "screenshot_hash") {
+		_, err := d.DB.Exec(`ALTER TABLE FingerprintInfo ADD COLUMN screenshot_hash TEXT`)
+		if err != nil {
+			return false
+		}
+	}
+	if !columnExists(d.DB, "FingerprintInfo", "honeypot") {
+		_, err := d.DB.Exec(`ALTER TABLE FingerprintInfo ADD COLUMN honeypot TEXT`)
+		if err != nil {
+			return false
+		}
+	}
+	if !columnExists(d.DB, "FingerprintInfo", "tls_info") {
+		_, err := d.DB.Exec(`ALTER TABLE FingerprintInfo ADD COLUMN tls_info TEXT`)
+		if err != nil {
+			return false
+		}
+	}
 	if !columnExists(d.DB, "dbManager", "serverName") {
 		_, err := d.DB.Exec(`ALTER TABLE dbManager ADD COLUMN serverName TEXT`)
 		if err != nil {
 			return false
 		}
 	}
+	if !columnExists(d.DB, "Credentials", "actions") {
+		_, err := d.DB.Exec(`ALTER TABLE Credentials ADD COLUMN actions TEXT`)
+		if err != nil {
+			return false
+		}
+	}
 	return err == nil
 }
 
@@ -353,6 +407,8 @@ func (d *Database) AddScanTask(taskid, taskname, targets string, failed, vulnera
 
 // 修改扫描结果 - 失败数量，漏洞数量
 func (d *Database) UpdateScanTaskWithResults(taskid string, failed, vulnerability int) bool {
+	// 先写入缓存中的结果，保证任务记录的数量与已保存的结果一致
+	resultstore.Flush()
 	updateStmt := "UPDATE scanTask SET failed = ?, vulnerability = ? WHERE task_id = ?"
 	return d.ExecSqlStatement(updateStmt, failed, vulnerability, taskid)
 }
@@ -390,7 +446,14 @@ func (d *Database) RetrieveFingerscanResults(taskid string) []structs.InfoResult
 		var host *string // 使用指针来处理可能的 NULL 值
 		var scheme *string
 		var port *int
-		err = rows.Scan(&task_id, &result.URL, &result.StatusCode, &result.Length, &result.Title, &result.Detect, &result.IsWAF, &result.WAF, &fingerprintsStr, &result.Screenshot, &host, &scheme, &port)
+		var ntlmInfo *string
+		var netInfo *string
+		var icsInfo *string
+		var fingerprintDetails *string
+		var screenshotHash *string
+		var honeypot *string
+		var tlsInfo *string
+		err = rows.Scan(&task_id, &result.URL, &result.StatusCode, &result.Length, &result.Title, &result.Detect, &result.IsWAF, &result.WAF, &fingerprintsStr, &result.Screenshot, &host, &scheme, &port, &ntlmInfo, &netInfo, &icsInfo, &fingerprintDetails, &screenshotHash, &honeypot, &tlsInfo)
 		if err != nil {
 			gologger.Debug(d.ctx, err)
 			continue
@@ -413,6 +476,27 @@ func (d *Database) RetrieveFingerscanResults(taskid string) []structs.InfoResult
 		if scheme != nil {
 			result.Scheme = *scheme
 		}
+		if ntlmInfo != nil && *ntlmInfo != "" {
+			json.Unmarshal([]byte(*ntlmInfo), &result.NTLMInfo)
+		}
+		if netInfo != nil && *netInfo != "" {
+			json.Unmarshal([]byte(*netInfo), &result.NetInfo)
+		}
+		if icsInfo != nil && *icsInfo != "" {
+			json.Unmarshal([]byte(*icsInfo), &result.ICSInfo)
+		}
+		if fingerprintDetails != nil && *fingerprintDetails != "" {
+			json.Unmarshal([]byte(*fingerprintDetails), &result.FingerprintDetails)
+		}
+		if screenshotHash != nil {
+			result.ScreenshotHash = *screenshotHash
+		}
+		if honeypot != nil && *honeypot != "" {
+			json.Unmarshal([]byte(*honeypot), &result.Honeypot)
+		}
+		if tlsInfo != nil && *tlsInfo != "" {
+			json.Unmarshal([]byte(*tlsInfo), &result.TLSInfo)
+		}
 		results = append(results, result)
 	}
 	return results
@@ -443,16 +527,95 @@ func (d *Database) RetrievePocscanResults(taskid string) []structs.Vulnerability
 	return results
 }
 
+const fingerprintInsertStmt = "INSERT INTO FingerprintInfo (task_id, url, status, length, title, detect, is_waf, waf, fingerprints, screenshot, host, scheme, port, ntlm_info, net_info, ics_info, fingerprint_details, screenshot_hash, honeypot, tls_info) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+const vulnerabilityInsertStmt = "INSERT INTO VulnerabilityInfo (task_id, template_id, vuln_name, protocol, severity, vuln_url, extract, request, response, description, reference, response_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
 // 添加指纹扫描结果
 func (d *Database) AddFingerscanResult(result structs.InfoResult) bool {
-	insertStmt := "INSERT INTO FingerprintInfo (task_id, url, status, length, title, detect, is_waf, waf, fingerprints, screenshot, host, scheme, port) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
-	return d.ExecSqlStatement(insertStmt, result.TaskId, result.URL, result.StatusCode, result.Length, result.Title, result.Detect, result.IsWAF, result.WAF, strings.Join(result.Fingerprints, ","), result.Screenshot, result.Host, result.Scheme, result.Port)
+	return d.ExecSqlStatement(fingerprintInsertStmt, fingerprintArgs(result)...)
+}
+
+func fingerprintArgs(result structs.InfoResult) []interface{} {
+	var ntlmInfo string
+	if result.NTLMInfo != nil {
+		b, _ := json.Marshal(result.NTLMInfo)
+		ntlmInfo = string(b)
+	}
+	var netInfo string
+	if result.NetInfo != nil {
+		b, _ := json.Marshal(result.NetInfo)
+		netInfo = string(b)
+	}
+	var icsInfo string
+	if result.ICSInfo != nil {
+		b, _ := json.Marshal(result.ICSInfo)
+		icsInfo = string(b)
+	}
+	var fingerprintDetails string
+	if len(result.FingerprintDetails) > 0 {
+		b, _ := json.Marshal(result.FingerprintDetails)
+		fingerprintDetails = string(b)
+	}
+	var honeypot string
+	if result.Honeypot != nil {
+		b, _ := json.Marshal(result.Honeypot)
+		honeypot = string(b)
+	}
+	var tlsInfo string
+	if result.TLSInfo != nil {
+		b, _ := json.Marshal(result.TLSInfo)
+		tlsInfo = string(b)
+	}
+	return []interface{}{result.TaskId, result.URL, result.StatusCode, result.Length, result.Title, result.Detect, result.IsWAF, result.WAF, strings.Join(result.Fingerprints, ","), result.Screenshot, result.Host, result.Scheme, result.Port, ntlmInfo, netInfo, icsInfo, fingerprintDetails, result.ScreenshotHash, honeypot, tlsInfo}
 }
 
 // 添加漏洞扫描结果
 func (d *Database) AddPocscanResult(result structs.VulnerabilityInfo) bool {
-	insertStmt := "INSERT INTO VulnerabilityInfo (task_id, template_id, vuln_name, protocol, severity, vuln_url, extract, request, response, description, reference, response_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
-	return d.ExecSqlStatement(insertStmt, result.TaskId, result.ID, result.Name, result.Type, result.Severity, result.URL, result.Extract, result.Request, result.Response, result.Description, result.Reference, result.ResponseTime)
+	// 爆破与默认口令结果同时写入凭据表
+	if cred, ok := credutil.FromVulnerability(result); ok {
+		d.AddCredential(cred)
+	}
+	return d.ExecSqlStatement(vulnerabilityInsertStmt, vulnerabilityArgs(result)...)
+}
+
+func vulnerabilityArgs(result structs.VulnerabilityInfo) []interface{} {
+	return []interface{}{result.TaskId, result.ID, result.Name, result.Type, result.Severity, result.URL, result.Extract, result.Request, result.Response, result.Description, result.Reference, result.ResponseTime}
+}
+
+// execBatch 在同一个事务中执行多条语句，任意一条失败时全部回滚
+func (d *Database) execBatch(statements []resultstore.Statement) error {
+	if d.DB == nil {
+		return errors.New("database is not open")
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return resultstore.ExecBatch(d.DB, statements)
+}
+
+// resultWriter 扫描结果由后端批量写入，单独定义以免作为绑定方法暴露给前端
+type resultWriter struct {
+	d *Database
+}
+
+func (w *resultWriter) SaveFingerprints(results []structs.InfoResult) error {
+	statements := make([]resultstore.Statement, 0, len(results))
+	for _, result := range results {
+		statements = append(statements, resultstore.Statement{Query: fingerprintInsertStmt, Args: fingerprintArgs(result)})
+	}
+	return w.d.execBatch(statements)
+}
+
+func (w *resultWriter) SaveVulnerabilities(results []structs.VulnerabilityInfo) error {
+	statements := make([]resultstore.Statement, 0, len(results))
+	for _, result := range results {
+		// 爆破与默认口令结果同时写入凭据表
+		if cred, ok := credutil.FromVulnerability(result); ok {
+			statements = append(statements, resultstore.Statement{Query: credentialInsertStmt, Args: credentialArgs(cred)})
+		}
+		statements = append(statements, resultstore.Statement{Query: vulnerabilityInsertStmt, Args: vulnerabilityArgs(result)})
+	}
+	return w.d.execBatch(statements)
 }
 
 // 移除某个漏洞
@@ -505,7 +668,11 @@ func (d *Database) ExportWebReportWithJson(reportpath string, tasks []structs.Ta
 		Fingerprints: fingerprintsResults,
 		POCs:         pocsResults,
 	}
-	return fileutil.SaveJsonWithFormat(d.ctx, reportpath, result)
+	if !fileutil.SaveJsonWithFormat(d.ctx, reportpath, result) {
+		return false
+	}
+	d.saveReportAttachment(reportpath)
+	return true
 }
 
 // 加载JSON报告
@@ -528,7 +695,11 @@ func (d *Database) ExportWebReportWithHtml(reportpath string, taskids []string)
 		fingerprintsResults = append(fingerprintsResults, fingerprintsResult...)
 		pocsResults = append(pocsResults, pocsResult...)
 	}
-	return os.WriteFile(reportpath, []byte(report.GenerateReport(fingerprintsResults, pocsResults)), 0644) == nil
+	if err := os.WriteFile(reportpath, []byte(report.GenerateReport(fingerprintsResults, pocsResults)), 0644); err != nil {
+		return false
+	}
+	d.saveReportAttachment(reportpath)
+	return true
 }
 
 // 导出EXCEL报告
@@ -608,10 +779,17 @@ func (d *Database) ExportWebReportWithExcel(reportpath string, tasks []structs.T
 		gologger.Error(d.ctx, "Failed to save Excel file")
 		return false
 	}
-
+	d.saveReportAttachment(reportpath)
 	return true
 }
 
+// 导出的报告同时保存到附件目录，前端通过本地资源服务打开，保存失败不影响导出结果
+func (d *Database) saveReportAttachment(reportpath string) {
+	if _, err := report.SaveAttachment(reportpath); err != nil {
+		gologger.Error(d.ctx, fmt.Sprintf("保存报告附件失败: %v", err))
+	}
+}
+
 func (d *Database) ExportJSReportWithExcel(reportpath string, results []structs.JSFindResult) bool {
 	// 创建Excel文件
 	f := excelize.NewFile()
@@ -6,9 +6,12 @@ import (
 	"slack-wails/core/exp/finereport"
 	"slack-wails/core/exp/hikvision"
 	"slack-wails/core/exp/nacos"
+	"slack-wails/lib/structs"
+	"slack-wails/lib/utils/netutil"
 	"strings"
 
 	"github.com/qiwentaidi/clients"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type Exp struct {
@@ -27,11 +30,25 @@ func trimRightSubString(url string) string {
 	return strings.TrimRight(url, "/")
 }
 
+// 利用成功后获得的账号交由前端写入凭据表
+func (e *Exp) emitCredential(url, username, password string) {
+	protocol, host, port := netutil.SplitTarget(url)
+	runtime.EventsEmit(e.ctx, "credential", structs.Credential{
+		Host:     host,
+		Port:     port,
+		Protocol: protocol,
+		Username: username,
+		Password: password,
+		Source:   "exploit",
+	})
+}
+
 // nacos
 
 func (e *Exp) CVE_2021_29441_AddUser(url string, headers map[string]string, username, password string, proxyURL string) string {
 	url = trimRightSubString(url)
 	if nacos.CVE_2021_29441_Step1(url, headers, username, password, clients.NewRestyClientWithProxy(nil, true, proxyURL)) {
+		e.emitCredential(url, username, password)
 		return fmt.Sprintf("[+] 添加用户成功: \nusername: %s\npassword: %s", username, password)
 	}
 	return "[-] 添加用户失败"
@@ -60,7 +77,11 @@ func (e *Exp) CVE_2021_36260(url, cmd string, proxyURL string) string {
 }
 
 func (e *Exp) CameraCrackPassword(url, username string, passwordList []string) string {
-	return hikvision.CameraHandlessLogin(e.ctx, url, username, passwordList)
+	result := hikvision.CameraHandlessLogin(e.ctx, url, username, passwordList)
+	if result.Success {
+		e.emitCredential(result.Target, result.Username, result.Password)
+	}
+	return result.Message
 }
 
 func (e *Exp) FineReportChannelDeserialize(url, cmd string, proxyURL string) string {
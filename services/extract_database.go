@@ -91,6 +91,8 @@ func (d *Database) ConnectDatabase(info structs.DatabaseConnection) bool {
 				d.showErrorMessage(err.Error())
 				return false
 			}
+			d.ConnectionInfo = &info
+			d.AddCredential(databaseCredential(info, info.Username, info.Password))
 			return true
 		}
 	default:
@@ -102,6 +104,8 @@ func (d *Database) ConnectDatabase(info structs.DatabaseConnection) bool {
 		d.showErrorMessage(err.Error())
 		return false
 	}
+	d.ConnectionInfo = &info
+	d.AddCredential(databaseCredential(info, info.Username, info.Password))
 
 	// Connect to other databases
 	d.OtherDatabase, err = sql.Open(info.Scheme, dataSourceName)
@@ -283,6 +287,7 @@ func (d *Database) FetchTableInfoFromMysql(dbName, tableName string) structs.Row
 		gologger.Debug(d.ctx, fmt.Sprintf("[mysql] 获取总行数失败: %v", err))
 	}
 
+	d.harvestRowCredentials(columns, data)
 	return structs.RowData{
 		Columns:   columns,
 		Rows:      data,
@@ -393,6 +398,7 @@ func (d *Database) FetchTableInfoFromSqlServer(dbName, tableName string) structs
 		gologger.Debug(d.ctx, fmt.Sprintf("[mysql] 获取总行数失败: %v", err))
 	}
 
+	d.harvestRowCredentials(columns, data)
 	return structs.RowData{
 		Columns:   columns,
 		Rows:      data,
@@ -501,6 +507,7 @@ func (d *Database) FetchTableInfoFromOracle(schemaName, tableName string) struct
 		gologger.Debug(d.ctx, fmt.Sprintf("[oracle] 获取总行数失败: %v", err))
 	}
 
+	d.harvestRowCredentials(columns, data)
 	return structs.RowData{
 		Columns:   columns,
 		Rows:      data,
@@ -637,6 +644,7 @@ func (d *Database) FetchTableInfoFromPostgres(schemaName, tableName string) stru
 		gologger.Debug(d.ctx, fmt.Sprintf("[postgres] 获取总行数失败: %v", err))
 	}
 
+	d.harvestRowCredentials(columns, data)
 	return structs.RowData{
 		Columns:   columns,
 		Rows:      data,